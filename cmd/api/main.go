@@ -1,125 +1,316 @@
-package main
-
-import (
-	"context"
-	"encoding/json"
-	"fmt"
-	"log"
-	"net/http"
-	"os/signal"
-	"syscall"
-	"time"
-
-	"github.com/anggakrnwn/kasir-api/config"
-	"github.com/anggakrnwn/kasir-api/database"
-	"github.com/anggakrnwn/kasir-api/handlers"
-	"github.com/anggakrnwn/kasir-api/middlewares"
-	"github.com/anggakrnwn/kasir-api/repositories"
-	"github.com/anggakrnwn/kasir-api/services"
-)
-
-func main() {
-
-	cfg, err := config.Init()
-	if err != nil {
-		log.Fatal("failed to load config:", err)
-	}
-
-	// Setup database
-	db, err := database.InitDB(cfg.Database.ConnectionString)
-	if err != nil {
-		log.Fatal("Failed to initialize database:", err)
-	}
-	defer db.Close()
-
-	apiKeyMiddleware := middlewares.APIKey(cfg.Auth.APIKey)
-
-	productRepo := repositories.NewProductRepository(db)
-	productService := services.NewProductService(productRepo)
-	productHandler := handlers.NewProductHandler(productService)
-	transactionRepo := repositories.NewTransactionRepository(db)
-	transactionService := services.NewTransactionService(transactionRepo)
-	transactionHandler := handlers.NewTransactionHandler(transactionService)
-
-	// setup routes
-	mux := http.NewServeMux()
-
-	mux.HandleFunc("/health", healthHandler(cfg))
-	mux.HandleFunc("/", homeHandler(cfg))
-
-	mux.HandleFunc("/api/product", apiKeyMiddleware(productHandler.HandleProduct))
-	mux.HandleFunc("/api/product/", apiKeyMiddleware(productHandler.HandleProductByID))
-	mux.HandleFunc("/api/checkout", apiKeyMiddleware(transactionHandler.HandleCheckout))
-	mux.HandleFunc("/api/report/hari-ini", apiKeyMiddleware(transactionHandler.GetReport))
-	mux.HandleFunc("/api/report", apiKeyMiddleware(transactionHandler.HandleReport))
-
-	addr := "0.0.0.0:" + cfg.Server.Port
-	server := &http.Server{
-		Addr:         addr,
-		Handler:      mux,
-		ReadTimeout:  cfg.Server.ReadTimeout,
-		WriteTimeout: cfg.Server.WriteTimeout,
-		IdleTimeout:  cfg.Server.IdleTimeout,
-	}
-
-	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
-	defer stop()
-
-	go func() {
-		log.Printf("server running on %s (%s mode)", addr, cfg.Env)
-		log.Printf("api documentation: http://localhost:%s/", cfg.Server.Port)
-
-		if err := server.ListenAndServe(); err != nil && err != http.ErrServerClosed {
-			log.Fatal("server error:", err)
-		}
-	}()
-
-	<-ctx.Done()
-	log.Println("\n shutting down server..")
-
-	shutdownCtx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
-	defer cancel()
-
-	if err := server.Shutdown(shutdownCtx); err != nil {
-		log.Fatal("server forced to shutdown:", err)
-	}
-
-	log.Println("server stopped gracefully")
-}
-
-func healthHandler(cfg *config.Config) http.HandlerFunc {
-	return func(w http.ResponseWriter, r *http.Request) {
-		w.Header().Set("Content-Type", "application/json")
-		json.NewEncoder(w).Encode(map[string]interface{}{
-			"status":    "healthy",
-			"service":   cfg.App.Name,
-			"version":   cfg.App.Version,
-			"env":       cfg.Env,
-			"timestamp": time.Now().Format(time.RFC3339),
-		})
-	}
-}
-
-func homeHandler(cfg *config.Config) http.HandlerFunc {
-	return func(w http.ResponseWriter, r *http.Request) {
-		if r.URL.Path != "/" {
-			http.NotFound(w, r)
-			return
-		}
-		w.Header().Set("Content-Type", "text/plain; charset=utf-8")
-		fmt.Fprintf(w, "=================================================\n")
-		fmt.Fprintf(w, "      %s v%s (%s)\n", cfg.App.Name, cfg.App.Version, cfg.Env)
-		fmt.Fprintf(w, "=================================================\n\n")
-		fmt.Fprintf(w, "ENDPOINTS:\n")
-		fmt.Fprintf(w, "  GET    /health              Health check\n")
-		fmt.Fprintf(w, "  GET    /api/product         List products\n")
-		fmt.Fprintf(w, "  POST   /api/product         Create product\n")
-		fmt.Fprintf(w, "  GET    /api/product/{id}    Get product by ID\n")
-		fmt.Fprintf(w, "  PUT    /api/product/{id}    Update product\n")
-		fmt.Fprintf(w, "  DELETE /api/product/{id}    Delete product\n")
-		fmt.Fprintf(w, "  POST   /api/checkout        Checkout transaction\n")
-		fmt.Fprintf(w, "  GET    /api/report/hari-ini Today's sales report\n")
-		fmt.Fprintf(w, "  GET    /api/report          Sales report by date\n\n")
-		fmt.Fprintf(w, "=================================================\n")
-	}
-}
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/anggakrnwn/kasir-api/config"
+	"github.com/anggakrnwn/kasir-api/database"
+	"github.com/anggakrnwn/kasir-api/handlers"
+	"github.com/anggakrnwn/kasir-api/middlewares"
+	"github.com/anggakrnwn/kasir-api/models"
+	"github.com/anggakrnwn/kasir-api/repositories"
+	"github.com/anggakrnwn/kasir-api/services"
+)
+
+func main() {
+
+	cfg, err := config.Init()
+	if err != nil {
+		log.Fatal("failed to load config:", err)
+	}
+
+	// Setup database
+	db, err := database.InitDB(cfg.Database.ConnectionString)
+	if err != nil {
+		log.Fatal("Failed to initialize database:", err)
+	}
+	defer db.Close()
+
+	auditRepo := repositories.NewAuditRepository(db)
+	auditService := services.NewAuditService(auditRepo, cfg.Store.Location)
+	auditHandler := handlers.NewAuditHandler(auditService)
+	outletRepo := repositories.NewOutletRepository(db)
+	outletService := services.NewOutletService(outletRepo)
+	outletHandler := handlers.NewOutletHandler(outletService, auditService)
+	cashierRepo := repositories.NewCashierRepository(db)
+	cashierService := services.NewCashierService(cashierRepo, outletService)
+	cashierHandler := handlers.NewCashierHandler(cashierService, auditService)
+
+	apiKeyMiddleware := middlewares.APIKey(cfg.Auth.APIKey, cashierService, cfg.Auth.TrustedProxies)
+	ownerMiddleware := func(next http.HandlerFunc) http.HandlerFunc {
+		return apiKeyMiddleware(middlewares.OwnerOnly(next))
+	}
+	// kasir boleh membaca, perubahan hanya oleh owner
+	ownerWriteMiddleware := func(next http.HandlerFunc) http.HandlerFunc {
+		return apiKeyMiddleware(middlewares.OwnerOnlyWrites(next))
+	}
+
+	productRepo := repositories.NewProductRepository(db)
+	productService := services.NewProductService(productRepo, outletService, cfg.Scale.PricePrefixes)
+	productHandler := handlers.NewProductHandler(productService, auditService)
+	stockRepo := repositories.NewStockRepository(db)
+	valuationRepo := repositories.NewValuationRepository(db)
+	stockService := services.NewStockService(stockRepo, valuationRepo, outletService, cfg.Stock.CostingMethod, cfg.Store.Location)
+	stockHandler := handlers.NewStockHandler(stockService, auditService)
+	transferRepo := repositories.NewTransferRepository(db)
+	transferService := services.NewTransferService(transferRepo, outletService)
+	transferHandler := handlers.NewTransferHandler(transferService, auditService)
+	stockTakeRepo := repositories.NewStockTakeRepository(db)
+	stockTakeService := services.NewStockTakeService(stockTakeRepo, outletService)
+	stockTakeHandler := handlers.NewStockTakeHandler(stockTakeService, auditService)
+	customerRepo := repositories.NewCustomerRepository(db)
+	customerService := services.NewCustomerService(customerRepo)
+	customerHandler := handlers.NewCustomerHandler(customerService, auditService)
+	reorderRepo := repositories.NewReorderRepository(db)
+	reorderService := services.NewReorderService(reorderRepo, outletService, cfg.Alert.WebhookURL, cfg.Alert.WebhookTimeout)
+	reorderHandler := handlers.NewReorderHandler(reorderService, auditService)
+	shiftRepo := repositories.NewShiftRepository(db)
+	cashMovementRepo := repositories.NewCashMovementRepository(db)
+	shiftService := services.NewShiftService(shiftRepo, cashMovementRepo, cfg.Store.Location)
+	shiftHandler := handlers.NewShiftHandler(shiftService, auditService)
+	transactionRepo := repositories.NewTransactionRepository(db)
+	transactionService := services.NewTransactionService(transactionRepo, shiftService, cashMovementRepo, productService, reorderService, cfg.Store.Location, cfg.Store.TaxRate, cfg.Receipt.NumberFormat)
+	receiptTemplate := services.DefaultReceiptTemplate
+	if cfg.Receipt.TemplatePath != "" {
+		content, err := os.ReadFile(cfg.Receipt.TemplatePath)
+		if err != nil {
+			log.Fatal("failed to read receipt template:", err)
+		}
+		receiptTemplate = string(content)
+	}
+	store := models.StoreProfile{
+		Name:      cfg.Store.Name,
+		LegalName: cfg.Store.LegalName,
+		Address:   cfg.Store.Address,
+		Phone:     cfg.Store.Phone,
+		TaxID:     cfg.Store.TaxID,
+	}
+	receiptService, err := services.NewReceiptService(store, cfg.Receipt.Footer, receiptTemplate, cfg.Receipt.PaperWidth)
+	if err != nil {
+		log.Fatal("failed to load receipt template:", err)
+	}
+	invoiceRepo := repositories.NewInvoiceRepository(db)
+	invoiceService := services.NewInvoiceService(invoiceRepo, store, cfg.Store.Location)
+	transactionHandler := handlers.NewTransactionHandler(transactionService, receiptService, invoiceService, auditService)
+
+	cartRepo := repositories.NewCartRepository(db)
+	cartService := services.NewCartService(cartRepo, transactionService, cfg.Stock.ReservationTTL)
+	cartHandler := handlers.NewCartHandler(cartService, auditService)
+
+	reservationRepo := repositories.NewReservationRepository(db)
+	reservationService := services.NewReservationService(reservationRepo, outletService, cfg.Stock.ReservationTTL)
+	reservationHandler := handlers.NewReservationHandler(reservationService, auditService)
+
+	closingRepo := repositories.NewClosingRepository(db)
+	closingService := services.NewClosingService(closingRepo, outletService, cfg.Store.Location)
+	closingHandler := handlers.NewClosingHandler(closingService, auditService)
+
+	// setup routes
+	mux := http.NewServeMux()
+
+	mux.HandleFunc("/health", healthHandler(cfg))
+	mux.HandleFunc("/", homeHandler(cfg))
+
+	mux.HandleFunc("/api/product", ownerWriteMiddleware(productHandler.HandleProduct))
+	mux.HandleFunc("/api/product/", ownerWriteMiddleware(productHandler.HandleProductByID))
+	mux.HandleFunc("/api/barcode/", apiKeyMiddleware(productHandler.HandleBarcode))
+	mux.HandleFunc("/api/categories", ownerWriteMiddleware(productHandler.HandleCategories))
+	mux.HandleFunc("/api/checkout", apiKeyMiddleware(transactionHandler.HandleCheckout))
+	mux.HandleFunc("/api/carts", apiKeyMiddleware(cartHandler.HandleCarts))
+	mux.HandleFunc("/api/carts/", apiKeyMiddleware(cartHandler.HandleCartByID))
+	mux.HandleFunc("/api/reservations", apiKeyMiddleware(reservationHandler.HandleReservations))
+	mux.HandleFunc("/api/reservations/", apiKeyMiddleware(reservationHandler.HandleReservationByID))
+	mux.HandleFunc("/api/transactions", apiKeyMiddleware(transactionHandler.HandleTransactions))
+	mux.HandleFunc("/api/transactions/", apiKeyMiddleware(transactionHandler.HandleTransactionByID))
+	mux.HandleFunc("/api/report/hari-ini", apiKeyMiddleware(transactionHandler.GetReport))
+	mux.HandleFunc("/api/report", apiKeyMiddleware(transactionHandler.HandleReport))
+	mux.HandleFunc("/api/report/cash-flow", apiKeyMiddleware(transactionHandler.HandleCashFlowReport))
+	mux.HandleFunc("/api/report/sales-trend", apiKeyMiddleware(transactionHandler.HandleSalesTrend))
+	mux.HandleFunc("/api/report/products", apiKeyMiddleware(transactionHandler.HandleProductPerformance))
+	mux.HandleFunc("/api/report/inventory-valuation", apiKeyMiddleware(stockHandler.HandleInventoryValuation))
+	mux.HandleFunc("/api/outlets", ownerMiddleware(outletHandler.HandleOutlets))
+	mux.HandleFunc("/api/outlets/", ownerMiddleware(outletHandler.HandleOutletByID))
+	mux.HandleFunc("/api/cashiers", ownerMiddleware(cashierHandler.HandleCashiers))
+	mux.HandleFunc("/api/cashiers/", ownerMiddleware(cashierHandler.HandleCashierByID))
+	mux.HandleFunc("/api/shifts/open", apiKeyMiddleware(shiftHandler.HandleOpen))
+	mux.HandleFunc("/api/shifts/close", apiKeyMiddleware(shiftHandler.HandleClose))
+	mux.HandleFunc("/api/shifts/pay-in", apiKeyMiddleware(shiftHandler.HandlePayIn))
+	mux.HandleFunc("/api/shifts/pay-out", apiKeyMiddleware(shiftHandler.HandlePayOut))
+	mux.HandleFunc("/api/shifts/current", apiKeyMiddleware(shiftHandler.HandleCurrent))
+	mux.HandleFunc("/api/shifts/", apiKeyMiddleware(shiftHandler.HandleShiftByID))
+	mux.HandleFunc("/api/stock/receipts", apiKeyMiddleware(stockHandler.HandleReceipts))
+	mux.HandleFunc("/api/stock/receipts/", apiKeyMiddleware(stockHandler.HandleReceiptByID))
+	mux.HandleFunc("/api/stock/movements", apiKeyMiddleware(stockHandler.HandleMovements))
+	mux.HandleFunc("/api/stock-transfers", apiKeyMiddleware(transferHandler.HandleTransfers))
+	mux.HandleFunc("/api/stock-transfers/", apiKeyMiddleware(transferHandler.HandleTransferByID))
+	mux.HandleFunc("/api/stock-takes", apiKeyMiddleware(stockTakeHandler.HandleStockTakes))
+	mux.HandleFunc("/api/stock-takes/", apiKeyMiddleware(stockTakeHandler.HandleStockTakeByID))
+	mux.HandleFunc("/api/inventory/expiring", apiKeyMiddleware(stockHandler.HandleExpiring))
+	mux.HandleFunc("/api/inventory/low-stock", apiKeyMiddleware(reorderHandler.HandleLowStock))
+	mux.HandleFunc("/api/inventory/alerts", apiKeyMiddleware(reorderHandler.HandleAlerts))
+	mux.HandleFunc("/api/purchase-orders", ownerMiddleware(reorderHandler.HandlePurchaseOrders))
+	mux.HandleFunc("/api/purchase-orders/", ownerMiddleware(reorderHandler.HandlePurchaseOrderByID))
+	mux.HandleFunc("/api/customer-groups", apiKeyMiddleware(customerHandler.HandleGroups))
+	mux.HandleFunc("/api/customers", apiKeyMiddleware(customerHandler.HandleCustomers))
+	mux.HandleFunc("/api/customers/", apiKeyMiddleware(customerHandler.HandleCustomerByID))
+	mux.HandleFunc("/api/closings", ownerMiddleware(closingHandler.HandleClosings))
+	mux.HandleFunc("/api/closings/", ownerMiddleware(closingHandler.HandleClosingByID))
+	mux.HandleFunc("/api/audit", ownerMiddleware(auditHandler.HandleAudit))
+
+	addr := "0.0.0.0:" + cfg.Server.Port
+	server := &http.Server{
+		Addr:         addr,
+		Handler:      mux,
+		ReadTimeout:  cfg.Server.ReadTimeout,
+		WriteTimeout: cfg.Server.WriteTimeout,
+		IdleTimeout:  cfg.Server.IdleTimeout,
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+
+	reservationService.StartSweeper(ctx, cfg.Stock.ReservationSweepInterval)
+
+	go func() {
+		log.Printf("server running on %s (%s mode)", addr, cfg.Env)
+		log.Printf("api documentation: http://localhost:%s/", cfg.Server.Port)
+
+		if err := server.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+			log.Fatal("server error:", err)
+		}
+	}()
+
+	<-ctx.Done()
+	log.Println("\n shutting down server..")
+
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	if err := server.Shutdown(shutdownCtx); err != nil {
+		log.Fatal("server forced to shutdown:", err)
+	}
+
+	log.Println("server stopped gracefully")
+}
+
+func healthHandler(cfg *config.Config) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"status":    "healthy",
+			"service":   cfg.App.Name,
+			"version":   cfg.App.Version,
+			"env":       cfg.Env,
+			"timestamp": time.Now().Format(time.RFC3339),
+		})
+	}
+}
+
+func homeHandler(cfg *config.Config) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/" {
+			http.NotFound(w, r)
+			return
+		}
+		w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+		fmt.Fprintf(w, "=================================================\n")
+		fmt.Fprintf(w, "      %s v%s (%s)\n", cfg.App.Name, cfg.App.Version, cfg.Env)
+		fmt.Fprintf(w, "=================================================\n\n")
+		fmt.Fprintf(w, "ENDPOINTS:\n")
+		fmt.Fprintf(w, "  GET    /health              Health check\n")
+		fmt.Fprintf(w, "  GET    /api/product         List products\n")
+		fmt.Fprintf(w, "  POST   /api/product         Create product (owner)\n")
+		fmt.Fprintf(w, "  GET    /api/product/{id}    Get product by ID\n")
+		fmt.Fprintf(w, "  PUT    /api/product/{id}    Update product (owner)\n")
+		fmt.Fprintf(w, "  DELETE /api/product/{id}    Delete product (owner)\n")
+		fmt.Fprintf(w, "  GET    /api/product/{id}/price-history  Price history\n")
+		fmt.Fprintf(w, "  POST   /api/product/{id}/prices         Schedule price change (owner)\n")
+		fmt.Fprintf(w, "  DELETE /api/product/{id}/prices/{pid}   Cancel scheduled price (owner)\n")
+		fmt.Fprintf(w, "  GET    /api/product/{id}/price-tiers    List price tiers\n")
+		fmt.Fprintf(w, "  POST   /api/product/{id}/price-tiers    Set price tier (owner)\n")
+		fmt.Fprintf(w, "  DELETE /api/product/{id}/price-tiers/{tid} Delete price tier (owner)\n")
+		fmt.Fprintf(w, "  GET    /api/product/{id}/units          List product units\n")
+		fmt.Fprintf(w, "  POST   /api/product/{id}/units          Set product unit (owner)\n")
+		fmt.Fprintf(w, "  DELETE /api/product/{id}/units/{uid}    Delete product unit (owner)\n")
+		fmt.Fprintf(w, "  GET    /api/product/{id}/components     List bundle components\n")
+		fmt.Fprintf(w, "  PUT    /api/product/{id}/components     Replace bundle components (owner)\n")
+		fmt.Fprintf(w, "  GET    /api/barcode/{code}  Resolve product or scale barcode\n")
+		fmt.Fprintf(w, "  GET    /api/categories      List product categories\n")
+		fmt.Fprintf(w, "  POST   /api/categories      Create product category (owner)\n")
+		fmt.Fprintf(w, "  POST   /api/stock/receipts  Receive stock from supplier\n")
+		fmt.Fprintf(w, "  GET    /api/stock/receipts/{id} Get stock receipt\n")
+		fmt.Fprintf(w, "  GET    /api/stock/movements Stock ledger\n")
+		fmt.Fprintf(w, "  GET    /api/inventory/expiring Near-expiry stock lots\n")
+		fmt.Fprintf(w, "  GET    /api/inventory/low-stock Products below minimum stock\n")
+		fmt.Fprintf(w, "  GET    /api/inventory/alerts Low-stock alerts\n")
+		fmt.Fprintf(w, "  GET    /api/purchase-orders List purchase orders (owner)\n")
+		fmt.Fprintf(w, "  POST   /api/purchase-orders Draft purchase order from low stock (owner)\n")
+		fmt.Fprintf(w, "  GET    /api/purchase-orders/{id} Get purchase order (owner)\n")
+		fmt.Fprintf(w, "  GET    /api/stock-transfers List stock transfers\n")
+		fmt.Fprintf(w, "  POST   /api/stock-transfers Send stock to another outlet\n")
+		fmt.Fprintf(w, "  GET    /api/stock-transfers/{id} Get stock transfer\n")
+		fmt.Fprintf(w, "  POST   /api/stock-transfers/{id}/receive Receive stock transfer\n")
+		fmt.Fprintf(w, "  GET    /api/stock-takes     List stock takes\n")
+		fmt.Fprintf(w, "  POST   /api/stock-takes     Start stock take (snapshot)\n")
+		fmt.Fprintf(w, "  GET    /api/stock-takes/{id} Review stock take variances\n")
+		fmt.Fprintf(w, "  POST   /api/stock-takes/{id}/counts Submit counted quantities\n")
+		fmt.Fprintf(w, "  POST   /api/stock-takes/{id}/post Post stock take adjustments\n")
+		fmt.Fprintf(w, "  POST   /api/stock-takes/{id}/cancel Cancel stock take\n")
+		fmt.Fprintf(w, "  GET    /api/stock-takes/{id}/shrinkage Shrinkage valuation\n")
+		fmt.Fprintf(w, "  GET    /api/customer-groups Customer groups\n")
+		fmt.Fprintf(w, "  GET    /api/customers       List customers\n")
+		fmt.Fprintf(w, "  POST   /api/customers       Create customer\n")
+		fmt.Fprintf(w, "  PUT    /api/customers/{id}  Update customer\n")
+		fmt.Fprintf(w, "  POST   /api/checkout        Checkout transaction\n")
+		fmt.Fprintf(w, "  GET    /api/carts           List carts (?status=parked)\n")
+		fmt.Fprintf(w, "  POST   /api/carts           Open a cart\n")
+		fmt.Fprintf(w, "  GET    /api/carts/{id}      Get cart\n")
+		fmt.Fprintf(w, "  POST   /api/carts/{id}/items Add item to cart\n")
+		fmt.Fprintf(w, "  DELETE /api/carts/{id}/items/{item_id} Remove item from cart\n")
+		fmt.Fprintf(w, "  POST   /api/carts/{id}/park Park (hold) cart\n")
+		fmt.Fprintf(w, "  POST   /api/carts/{id}/resume Resume parked cart\n")
+		fmt.Fprintf(w, "  POST   /api/carts/{id}/cancel Cancel cart\n")
+		fmt.Fprintf(w, "  POST   /api/carts/{id}/checkout Checkout cart\n")
+		fmt.Fprintf(w, "  GET    /api/reservations    List stock reservations\n")
+		fmt.Fprintf(w, "  POST   /api/reservations    Reserve stock for an online order\n")
+		fmt.Fprintf(w, "  GET    /api/reservations/{id} Get stock reservation\n")
+		fmt.Fprintf(w, "  POST   /api/reservations/{id}/release Release stock reservation\n")
+		fmt.Fprintf(w, "  GET    /api/transactions    Transaction lines by date or receipt number (json/csv/xlsx)\n")
+		fmt.Fprintf(w, "  GET    /api/transactions/{id}/receipt Printable receipt (escpos/text/html)\n")
+		fmt.Fprintf(w, "  POST   /api/transactions/{id}/invoice.pdf Issue an A4 invoice (faktur) for a customer\n")
+		fmt.Fprintf(w, "  GET    /api/transactions/{id}/invoice.pdf Reprint an issued invoice\n")
+		fmt.Fprintf(w, "  GET    /api/report/hari-ini Today's sales report\n")
+		fmt.Fprintf(w, "  GET    /api/report          Sales report by date\n")
+		fmt.Fprintf(w, "  GET    /api/report/cash-flow Daily cash-flow report\n")
+		fmt.Fprintf(w, "  GET    /api/report/sales-trend Sales time series by hour/day/week/month\n")
+		fmt.Fprintf(w, "  GET    /api/report/products Product & category performance ranking\n")
+		fmt.Fprintf(w, "  GET    /api/report/inventory-valuation Inventory value at a date\n")
+		fmt.Fprintf(w, "                              Reports accept ?format=csv|xlsx or Accept: text/csv\n")
+		fmt.Fprintf(w, "  GET    /api/outlets         List outlets (owner)\n")
+		fmt.Fprintf(w, "  POST   /api/outlets         Create outlet (owner)\n")
+		fmt.Fprintf(w, "  PUT    /api/outlets/{id}    Update outlet (owner)\n")
+		fmt.Fprintf(w, "  GET    /api/cashiers        List cashiers (owner)\n")
+		fmt.Fprintf(w, "  POST   /api/cashiers        Create cashier + API key (owner)\n")
+		fmt.Fprintf(w, "  DELETE /api/cashiers/{id}   Deactivate cashier (owner)\n")
+		fmt.Fprintf(w, "  POST   /api/shifts/open     Open shift with opening float\n")
+		fmt.Fprintf(w, "  POST   /api/shifts/close    Close shift with counted cash\n")
+		fmt.Fprintf(w, "  POST   /api/shifts/pay-in   Record cash pay-in\n")
+		fmt.Fprintf(w, "  POST   /api/shifts/pay-out  Record cash pay-out\n")
+		fmt.Fprintf(w, "  GET    /api/shifts/current  Current shift report\n")
+		fmt.Fprintf(w, "  GET    /api/shifts/{id}     Shift report by ID\n")
+		fmt.Fprintf(w, "  POST   /api/shifts/{id}/close Force-close an abandoned shift (owner)\n")
+		fmt.Fprintf(w, "  GET    /api/closings        List daily closings / Z reports (owner)\n")
+		fmt.Fprintf(w, "  POST   /api/closings        Close a business day per outlet (owner)\n")
+		fmt.Fprintf(w, "  GET    /api/closings/{id}   Daily closing (Z report) by ID (owner)\n")
+		fmt.Fprintf(w, "  GET    /api/audit           Audit log (owner)\n\n")
+		fmt.Fprintf(w, "=================================================\n")
+	}
+}
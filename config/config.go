@@ -1,138 +1,303 @@
-package config
-
-import (
-	"fmt"
-	"log"
-	"os"
-	"strings"
-	"time"
-
-	"github.com/joho/godotenv"
-	"github.com/spf13/viper"
-)
-
-type Config struct {
-	App      AppConfig
-	Server   ServerConfig
-	Database DatabaseConfig
-	Auth     AuthConfig
-	Env      string
-}
-
-type AppConfig struct {
-	Name    string
-	Version string
-}
-
-type ServerConfig struct {
-	Port         string
-	ReadTimeout  time.Duration
-	WriteTimeout time.Duration
-	IdleTimeout  time.Duration
-}
-
-type DatabaseConfig struct {
-	ConnectionString string
-	MaxOpenConns     int
-	MaxIdleConns     int
-}
-
-type AuthConfig struct {
-	APIKey string
-}
-
-var cfg *Config
-
-func Init() (*Config, error) {
-	if cfg != nil {
-		return cfg, nil
-	}
-
-	env := os.Getenv("APP_ENV")
-	if env == "" {
-		env = "development"
-	}
-
-	envFile := fmt.Sprintf(".env.%s", env)
-	if _, err := os.Stat(envFile); err == nil {
-		if err := godotenv.Load(envFile); err != nil {
-			log.Printf("Warning: Failed to load %s: %v", envFile, err)
-		} else {
-			log.Printf("Loaded config from: %s", envFile)
-		}
-	}
-
-	viper.AutomaticEnv()
-	viper.SetEnvKeyReplacer(strings.NewReplacer(",", "_"))
-
-	cfg = &Config{
-		Env: env,
-		App: AppConfig{
-			Name:    getEnv("APP_NAME", "kasir-api"),
-			Version: getEnv("APP_VERSION", "1.0.0"),
-		},
-
-		Server: ServerConfig{
-			Port:         getEnv("PORT", "8080"),
-			ReadTimeout:  getDuration("READ_TIMEOUT", 15*time.Second),
-			WriteTimeout: getDuration("WRITE_TIMEOUT", 15*time.Second),
-			IdleTimeout:  getDuration("IDLE_TIMEOUT", 60*time.Second),
-		},
-
-		Database: DatabaseConfig{
-			ConnectionString: getEnv("DB_CONN", ""),
-			MaxOpenConns:     getInt("DB_MAX_OPEN_CONNS", 25),
-			MaxIdleConns:     getInt("DB_MAX_IDLE_CONNS", 5),
-		},
-
-		Auth: AuthConfig{
-			APIKey: getEnv("API_KEY", ""),
-		},
-	}
-
-	if cfg.Database.ConnectionString == "" {
-		return nil, fmt.Errorf("DB_CONN is required")
-	}
-
-	if cfg.Auth.APIKey == "" && env == "production" {
-		return nil, fmt.Errorf("API_KEY is required for production")
-	}
-
-	return cfg, nil
-
-}
-
-func Get() *Config {
-	if cfg == nil {
-		panic("config not initialized")
-	}
-	return cfg
-}
-
-func getEnv(key, defaultValue string) string {
-	if value := os.Getenv(key); value != "" {
-		return value
-	}
-	return defaultValue
-}
-
-func getInt(key string, defaultValue int) int {
-	if value := os.Getenv(key); value != "" {
-		var intVal int
-		if _, err := fmt.Sscanf(value, "%d", &intVal); err == nil {
-			return intVal
-		}
-	}
-
-	return defaultValue
-}
-
-func getDuration(key string, defaultValue time.Duration) time.Duration {
-	if value := os.Getenv(key); value != "" {
-		if d, err := time.ParseDuration(value); err == nil {
-			return d
-		}
-	}
-
-	return defaultValue
-}
+package config
+
+import (
+	"fmt"
+	"log"
+	"net"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	_ "time/tzdata"
+
+	"github.com/joho/godotenv"
+	"github.com/spf13/viper"
+)
+
+type Config struct {
+	App      AppConfig
+	Server   ServerConfig
+	Database DatabaseConfig
+	Auth     AuthConfig
+	Scale    ScaleConfig
+	Alert    AlertConfig
+	Stock    StockConfig
+	Store    StoreConfig
+	Receipt  ReceiptConfig
+	Env      string
+}
+
+type AppConfig struct {
+	Name    string
+	Version string
+}
+
+type ServerConfig struct {
+	Port         string
+	ReadTimeout  time.Duration
+	WriteTimeout time.Duration
+	IdleTimeout  time.Duration
+}
+
+type DatabaseConfig struct {
+	ConnectionString string
+	MaxOpenConns     int
+	MaxIdleConns     int
+}
+
+// AuthConfig.TrustedProxies adalah IP atau CIDR reverse proxy yang boleh
+// mengisi X-Forwarded-For. Tanpa daftar ini IP audit selalu dari koneksi.
+type AuthConfig struct {
+	APIKey         string
+	TrustedProxies []*net.IPNet
+}
+
+// ScaleConfig mengatur stiker barcode timbangan (EAN-13 prefix 20-29).
+// Prefix di PricePrefixes memuat harga, prefix lain memuat berat dalam gram.
+type ScaleConfig struct {
+	PricePrefixes []string
+}
+
+// AlertConfig mengatur pengiriman peringatan stok rendah. WebhookURL kosong
+// berarti peringatan hanya disimpan di tabel stock_alerts.
+type AlertConfig struct {
+	WebhookURL     string
+	WebhookTimeout time.Duration
+}
+
+// StockConfig.CostingMethod adalah metode penilaian persediaan bawaan:
+// average (weighted average) atau fifo. ReservationTTL adalah masa berlaku
+// reservasi stok keranjang dan pesanan online, 0 berarti mode reservasi mati.
+// ReservationSweepInterval adalah jeda sweeper yang melepas reservasi
+// kedaluwarsa.
+type StockConfig struct {
+	CostingMethod            string
+	ReservationTTL           time.Duration
+	ReservationSweepInterval time.Duration
+}
+
+// StoreConfig.Timezone adalah zona waktu toko (IANA, mis. Asia/Jakarta).
+// Batas "hari ini" dan rentang tanggal laporan dihitung di zona ini, bukan
+// zona waktu server database. Name, Address, Phone dan TaxID (NPWP) dicetak
+// di struk dan faktur, LegalName (nama badan usaha) di kop faktur. TaxRate
+// adalah tarif PPN dalam persen yang sudah termasuk di harga jual, 0 berarti
+// toko tidak memungut PPN.
+type StoreConfig struct {
+	Timezone  string
+	Location  *time.Location
+	Name      string
+	LegalName string
+	Address   string
+	Phone     string
+	TaxID     string
+	TaxRate   float64
+}
+
+// ReceiptConfig mengatur struk. TemplatePath kosong memakai template bawaan,
+// PaperWidth adalah lebar kertas printer thermal dalam mm (58 atau 80).
+// NumberFormat adalah format nomor struk dengan {outlet} (kode outlet),
+// {date} (YYYYMMDD) dan {seq} (urutan per outlet per hari).
+type ReceiptConfig struct {
+	TemplatePath string
+	PaperWidth   int
+	Footer       []string
+	NumberFormat string
+}
+
+var cfg *Config
+
+func Init() (*Config, error) {
+	if cfg != nil {
+		return cfg, nil
+	}
+
+	env := os.Getenv("APP_ENV")
+	if env == "" {
+		env = "development"
+	}
+
+	envFile := fmt.Sprintf(".env.%s", env)
+	if _, err := os.Stat(envFile); err == nil {
+		if err := godotenv.Load(envFile); err != nil {
+			log.Printf("Warning: Failed to load %s: %v", envFile, err)
+		} else {
+			log.Printf("Loaded config from: %s", envFile)
+		}
+	}
+
+	viper.AutomaticEnv()
+	viper.SetEnvKeyReplacer(strings.NewReplacer(",", "_"))
+
+	cfg = &Config{
+		Env: env,
+		App: AppConfig{
+			Name:    getEnv("APP_NAME", "kasir-api"),
+			Version: getEnv("APP_VERSION", "1.0.0"),
+		},
+
+		Server: ServerConfig{
+			Port:         getEnv("PORT", "8080"),
+			ReadTimeout:  getDuration("READ_TIMEOUT", 15*time.Second),
+			WriteTimeout: getDuration("WRITE_TIMEOUT", 15*time.Second),
+			IdleTimeout:  getDuration("IDLE_TIMEOUT", 60*time.Second),
+		},
+
+		Database: DatabaseConfig{
+			ConnectionString: getEnv("DB_CONN", ""),
+			MaxOpenConns:     getInt("DB_MAX_OPEN_CONNS", 25),
+			MaxIdleConns:     getInt("DB_MAX_IDLE_CONNS", 5),
+		},
+
+		Auth: AuthConfig{
+			APIKey: getEnv("API_KEY", ""),
+		},
+
+		Scale: ScaleConfig{
+			PricePrefixes: getList("SCALE_PRICE_PREFIXES", []string{"22"}),
+		},
+
+		Alert: AlertConfig{
+			WebhookURL:     getEnv("LOW_STOCK_WEBHOOK_URL", ""),
+			WebhookTimeout: getDuration("LOW_STOCK_WEBHOOK_TIMEOUT", 5*time.Second),
+		},
+
+		Stock: StockConfig{
+			CostingMethod:            getEnv("COSTING_METHOD", "average"),
+			ReservationTTL:           getDuration("STOCK_RESERVATION_TTL", 0),
+			ReservationSweepInterval: getDuration("STOCK_RESERVATION_SWEEP_INTERVAL", time.Minute),
+		},
+
+		Store: StoreConfig{
+			Timezone:  getEnv("STORE_TIMEZONE", "Asia/Jakarta"),
+			Name:      getEnv("STORE_NAME", "Kasir API"),
+			LegalName: getEnv("STORE_LEGAL_NAME", ""),
+			Address:   getEnv("STORE_ADDRESS", ""),
+			Phone:     getEnv("STORE_PHONE", ""),
+			TaxID:     getEnv("STORE_NPWP", ""),
+			TaxRate:   getFloat("TAX_RATE", 0),
+		},
+
+		Receipt: ReceiptConfig{
+			TemplatePath: getEnv("RECEIPT_TEMPLATE", ""),
+			PaperWidth:   getInt("RECEIPT_PAPER_WIDTH", 58),
+			Footer:       getList("RECEIPT_FOOTER", []string{"Terima kasih"}),
+			NumberFormat: getEnv("RECEIPT_NUMBER_FORMAT", "INV/{outlet}/{date}/{seq}"),
+		},
+	}
+
+	if cfg.Database.ConnectionString == "" {
+		return nil, fmt.Errorf("DB_CONN is required")
+	}
+
+	if cfg.Stock.CostingMethod != "average" && cfg.Stock.CostingMethod != "fifo" {
+		return nil, fmt.Errorf("COSTING_METHOD must be average or fifo")
+	}
+
+	if cfg.Stock.ReservationTTL < 0 {
+		return nil, fmt.Errorf("STOCK_RESERVATION_TTL cannot be negative")
+	}
+
+	loc, err := time.LoadLocation(cfg.Store.Timezone)
+	if err != nil {
+		return nil, fmt.Errorf("STORE_TIMEZONE is invalid: %w", err)
+	}
+	cfg.Store.Location = loc
+
+	if cfg.Store.TaxRate < 0 || cfg.Store.TaxRate >= 100 {
+		return nil, fmt.Errorf("TAX_RATE must be between 0 and 100")
+	}
+
+	if cfg.Receipt.PaperWidth != 58 && cfg.Receipt.PaperWidth != 80 {
+		return nil, fmt.Errorf("RECEIPT_PAPER_WIDTH must be 58 or 80")
+	}
+
+	// urutan diulang per outlet per hari, tanpa ketiganya nomor bisa bentrok
+	for _, placeholder := range []string{"{outlet}", "{date}", "{seq}"} {
+		if !strings.Contains(cfg.Receipt.NumberFormat, placeholder) {
+			return nil, fmt.Errorf("RECEIPT_NUMBER_FORMAT must contain {outlet}, {date} and {seq}")
+		}
+	}
+
+	for _, proxy := range getList("TRUSTED_PROXIES", nil) {
+		// IP tunggal diperlakukan sebagai /32 atau /128
+		if !strings.Contains(proxy, "/") {
+			if ip := net.ParseIP(proxy); ip != nil && ip.To4() != nil {
+				proxy += "/32"
+			} else {
+				proxy += "/128"
+			}
+		}
+		_, network, err := net.ParseCIDR(proxy)
+		if err != nil {
+			return nil, fmt.Errorf("TRUSTED_PROXIES contains an invalid IP or CIDR: %s", proxy)
+		}
+		cfg.Auth.TrustedProxies = append(cfg.Auth.TrustedProxies, network)
+	}
+
+	if cfg.Auth.APIKey == "" && env == "production" {
+		return nil, fmt.Errorf("API_KEY is required for production")
+	}
+
+	return cfg, nil
+
+}
+
+func Get() *Config {
+	if cfg == nil {
+		panic("config not initialized")
+	}
+	return cfg
+}
+
+func getEnv(key, defaultValue string) string {
+	if value := os.Getenv(key); value != "" {
+		return value
+	}
+	return defaultValue
+}
+
+func getInt(key string, defaultValue int) int {
+	if value := os.Getenv(key); value != "" {
+		var intVal int
+		if _, err := fmt.Sscanf(value, "%d", &intVal); err == nil {
+			return intVal
+		}
+	}
+
+	return defaultValue
+}
+
+func getFloat(key string, defaultValue float64) float64 {
+	if value := os.Getenv(key); value != "" {
+		if f, err := strconv.ParseFloat(value, 64); err == nil {
+			return f
+		}
+	}
+
+	return defaultValue
+}
+
+func getList(key string, defaultValue []string) []string {
+	if value := os.Getenv(key); value != "" {
+		var list []string
+		for _, item := range strings.Split(value, ",") {
+			if item = strings.TrimSpace(item); item != "" {
+				list = append(list, item)
+			}
+		}
+		return list
+	}
+
+	return defaultValue
+}
+
+func getDuration(key string, defaultValue time.Duration) time.Duration {
+	if value := os.Getenv(key); value != "" {
+		if d, err := time.ParseDuration(value); err == nil {
+			return d
+		}
+	}
+
+	return defaultValue
+}
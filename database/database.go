@@ -1,25 +1,25 @@
-package database
-
-import (
-	"database/sql"
-	"log"
-
-	_ "github.com/jackc/pgx/v5/stdlib"
-)
-
-func InitDB(connectionString string) (*sql.DB, error) {
-	db, err := sql.Open("pgx", connectionString)
-	if err != nil {
-		return nil, err
-	}
-
-	if err := db.Ping(); err != nil {
-		return nil, err
-	}
-
-	db.SetMaxOpenConns(25)
-	db.SetMaxIdleConns(5)
-
-	log.Println("Database connected successfully")
-	return db, nil
-}
+package database
+
+import (
+	"database/sql"
+	"log"
+
+	_ "github.com/jackc/pgx/v5/stdlib"
+)
+
+func InitDB(connectionString string) (*sql.DB, error) {
+	db, err := sql.Open("pgx", connectionString)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := db.Ping(); err != nil {
+		return nil, err
+	}
+
+	db.SetMaxOpenConns(25)
+	db.SetMaxIdleConns(5)
+
+	log.Println("Database connected successfully")
+	return db, nil
+}
@@ -0,0 +1,69 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+
+	"github.com/anggakrnwn/kasir-api/models"
+	"github.com/anggakrnwn/kasir-api/services"
+)
+
+type AuditHandler struct {
+	service *services.AuditService
+}
+
+func NewAuditHandler(service *services.AuditService) *AuditHandler {
+	return &AuditHandler{service: service}
+}
+
+// get /api/audit?entity_type=&entity_id=&action=&actor=&cashier_id=&start_date=&end_date=&limit=&offset=
+func (h *AuditHandler) HandleAudit(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	q := r.URL.Query()
+	filter := models.AuditFilter{
+		Actor:      q.Get("actor"),
+		Action:     q.Get("action"),
+		EntityType: q.Get("entity_type"),
+		StartDate:  q.Get("start_date"),
+		EndDate:    q.Get("end_date"),
+	}
+
+	intParams := map[string]*int{
+		"cashier_id": &filter.CashierID,
+		"entity_id":  &filter.EntityID,
+		"limit":      &filter.Limit,
+		"offset":     &filter.Offset,
+	}
+	for name, target := range intParams {
+		value := q.Get(name)
+		if value == "" {
+			continue
+		}
+		n, err := strconv.Atoi(value)
+		if err != nil {
+			http.Error(w, "Invalid "+name, http.StatusBadRequest)
+			return
+		}
+		*target = n
+	}
+
+	entries, err := h.service.GetAll(filter)
+	if err != nil {
+		switch err {
+		case services.ErrInvalidAuditLimit,
+			services.ErrInvalidDate:
+			http.Error(w, err.Error(), http.StatusBadRequest)
+		default:
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+		}
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(entries)
+}
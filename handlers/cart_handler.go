@@ -0,0 +1,252 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/anggakrnwn/kasir-api/middlewares"
+	"github.com/anggakrnwn/kasir-api/models"
+	"github.com/anggakrnwn/kasir-api/services"
+)
+
+type CartHandler struct {
+	service *services.CartService
+	audit   *services.AuditService
+}
+
+func NewCartHandler(service *services.CartService, audit *services.AuditService) *CartHandler {
+	return &CartHandler{service: service, audit: audit}
+}
+
+// get /api/carts & post /api/carts
+func (h *CartHandler) HandleCarts(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodGet:
+		h.GetAll(w, r)
+	case http.MethodPost:
+		h.Create(w, r)
+	default:
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+	}
+}
+
+func (h *CartHandler) GetAll(w http.ResponseWriter, r *http.Request) {
+	outletID, err := reportOutletID(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	filter := models.CartFilter{Status: r.URL.Query().Get("status"), OutletID: outletID}
+	carts, err := h.service.GetAll(filter)
+	if err != nil {
+		if err == services.ErrInvalidCartStatus {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(carts)
+}
+
+func (h *CartHandler) Create(w http.ResponseWriter, r *http.Request) {
+	cashier := middlewares.CashierFromContext(r.Context())
+	if cashier == nil {
+		http.Error(w, "Cashier API Key Required", http.StatusForbidden)
+		return
+	}
+
+	var req models.CartRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	cart, err := h.service.Create(req, cashier)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(cart)
+}
+
+// get /api/carts/{id}, post /api/carts/{id}/items,
+// delete /api/carts/{id}/items/{item_id}, post /park, /resume, /cancel & /checkout
+func (h *CartHandler) HandleCartByID(w http.ResponseWriter, r *http.Request) {
+	segments := strings.Split(strings.Trim(strings.TrimPrefix(r.URL.Path, "/api/carts/"), "/"), "/")
+	id, err := strconv.Atoi(segments[0])
+	if err != nil {
+		http.Error(w, "Invalid cart ID", http.StatusBadRequest)
+		return
+	}
+
+	// kasir hanya bisa mengakses keranjang outletnya sendiri
+	cashier := middlewares.CashierFromContext(r.Context())
+	cart, err := h.service.GetByID(id, cashier)
+	if err == services.ErrCartOtherOutlet {
+		writeCartError(w, err)
+		return
+	}
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+
+	action := ""
+	if len(segments) >= 2 {
+		action = segments[1]
+	}
+
+	switch {
+	case action == "" && len(segments) == 1:
+		if r.Method != http.MethodGet {
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(cart)
+	case action == "items" && len(segments) <= 3:
+		h.items(w, r, cart, segments[2:])
+	case len(segments) > 2:
+		http.NotFound(w, r)
+	case action == "checkout":
+		h.checkout(w, r, cart)
+	case action == "park" || action == "resume" || action == "cancel":
+		if r.Method != http.MethodPost {
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+
+		// pemilik hanya bisa membatalkan, selebihnya dilakukan kasir
+		if cashier == nil && action != "cancel" {
+			http.Error(w, "Cashier API Key Required", http.StatusForbidden)
+			return
+		}
+		h.updateStatus(w, r, cart, action, cashier)
+	default:
+		http.NotFound(w, r)
+	}
+}
+
+// post /api/carts/{id}/items & delete /api/carts/{id}/items/{item_id}
+func (h *CartHandler) items(w http.ResponseWriter, r *http.Request, cart *models.Cart, rest []string) {
+	cashier := middlewares.CashierFromContext(r.Context())
+	if cashier == nil {
+		http.Error(w, "Cashier API Key Required", http.StatusForbidden)
+		return
+	}
+
+	var updated *models.Cart
+	var err error
+	switch {
+	case r.Method == http.MethodPost && len(rest) == 0:
+		var item models.CheckoutItem
+		if err := json.NewDecoder(r.Body).Decode(&item); err != nil {
+			http.Error(w, "Invalid request body", http.StatusBadRequest)
+			return
+		}
+		updated, err = h.service.AddItem(cart.ID, cashier.ID, item)
+	case r.Method == http.MethodDelete && len(rest) == 1:
+		itemID, convErr := strconv.Atoi(rest[0])
+		if convErr != nil {
+			http.Error(w, "Invalid cart item ID", http.StatusBadRequest)
+			return
+		}
+		updated, err = h.service.RemoveItem(cart.ID, cashier.ID, itemID)
+	default:
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	if err != nil {
+		writeCartError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(updated)
+}
+
+// cashier nil berarti pemilik
+func (h *CartHandler) updateStatus(w http.ResponseWriter, r *http.Request, before *models.Cart, action string, cashier *models.Cashier) {
+	cashierID := 0
+	if cashier != nil {
+		cashierID = cashier.ID
+	}
+
+	var cart *models.Cart
+	var err error
+	switch action {
+	case "park":
+		cart, err = h.service.Park(before.ID, cashierID)
+	case "resume":
+		cart, err = h.service.Resume(before.ID, cashier)
+	case "cancel":
+		cart, err = h.service.Cancel(before.ID, cashier)
+	}
+	if err != nil {
+		writeCartError(w, err)
+		return
+	}
+
+	h.audit.Record(middlewares.ActorFromRequest(r), "cart."+action, "cart", cart.ID, before, cart)
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(cart)
+}
+
+func (h *CartHandler) checkout(w http.ResponseWriter, r *http.Request, cart *models.Cart) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	cashier := middlewares.CashierFromContext(r.Context())
+	if cashier == nil {
+		http.Error(w, "Cashier API Key Required", http.StatusForbidden)
+		return
+	}
+
+	var req models.CartCheckoutRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	transaction, err := h.service.Checkout(cart.ID, cashier.ID, req)
+	if err != nil {
+		switch err {
+		case services.ErrCartNotOpen, services.ErrCartHeldByOther, services.ErrEmptyCart:
+			writeCartError(w, err)
+		default:
+			writeCheckoutError(w, err)
+		}
+		return
+	}
+
+	h.audit.Record(middlewares.ActorFromRequest(r), "transaction.checkout", "transaction", transaction.ID, nil, transaction)
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(transaction)
+}
+
+func writeCartError(w http.ResponseWriter, err error) {
+	switch err {
+	case services.ErrCartNotOpen,
+		services.ErrCartNotParked,
+		services.ErrCartClosed,
+		services.ErrCartHeldByOther:
+		http.Error(w, err.Error(), http.StatusConflict)
+	case services.ErrCartOtherOutlet:
+		http.Error(w, err.Error(), http.StatusForbidden)
+	default:
+		http.Error(w, err.Error(), http.StatusBadRequest)
+	}
+}
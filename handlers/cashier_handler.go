@@ -0,0 +1,100 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/anggakrnwn/kasir-api/middlewares"
+	"github.com/anggakrnwn/kasir-api/models"
+	"github.com/anggakrnwn/kasir-api/services"
+)
+
+type CashierHandler struct {
+	service *services.CashierService
+	audit   *services.AuditService
+}
+
+func NewCashierHandler(service *services.CashierService, audit *services.AuditService) *CashierHandler {
+	return &CashierHandler{service: service, audit: audit}
+}
+
+// get /api/cashiers & post /api/cashiers
+func (h *CashierHandler) HandleCashiers(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodGet:
+		h.GetAll(w, r)
+	case http.MethodPost:
+		h.Create(w, r)
+	default:
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+	}
+}
+
+func (h *CashierHandler) GetAll(w http.ResponseWriter, r *http.Request) {
+	cashiers, err := h.service.GetAll()
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(cashiers)
+}
+
+func (h *CashierHandler) Create(w http.ResponseWriter, r *http.Request) {
+	var cashier models.Cashier
+	if err := json.NewDecoder(r.Body).Decode(&cashier); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	err := h.service.Create(&cashier)
+	if err != nil {
+		switch err {
+		case services.ErrInvalidCashierName,
+			services.ErrInvalidOutlet:
+			http.Error(w, err.Error(), http.StatusBadRequest)
+		default:
+			http.Error(w, "internal server error", http.StatusInternalServerError)
+		}
+		return
+	}
+
+	// API key tidak ikut dicatat di audit log
+	logged := cashier
+	logged.APIKey = ""
+	h.audit.Record(middlewares.ActorFromRequest(r), "cashier.create", "cashier", cashier.ID, nil, logged)
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(cashier)
+}
+
+// delete /api/cashiers/{id} menonaktifkan kasir, riwayat shift tetap tersimpan
+func (h *CashierHandler) HandleCashierByID(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodDelete {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	idStr := strings.TrimPrefix(r.URL.Path, "/api/cashiers/")
+	id, err := strconv.Atoi(idStr)
+	if err != nil {
+		http.Error(w, "Invalid cashier ID", http.StatusBadRequest)
+		return
+	}
+
+	if err := h.service.Deactivate(id); err != nil {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+
+	h.audit.Record(middlewares.ActorFromRequest(r), "cashier.deactivate", "cashier", id, nil, nil)
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]string{
+		"message": "Cashier deactivated successfully",
+	})
+}
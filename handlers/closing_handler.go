@@ -0,0 +1,109 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/anggakrnwn/kasir-api/middlewares"
+	"github.com/anggakrnwn/kasir-api/models"
+	"github.com/anggakrnwn/kasir-api/services"
+)
+
+type ClosingHandler struct {
+	service *services.ClosingService
+	audit   *services.AuditService
+}
+
+func NewClosingHandler(service *services.ClosingService, audit *services.AuditService) *ClosingHandler {
+	return &ClosingHandler{service: service, audit: audit}
+}
+
+// get /api/closings & post /api/closings
+func (h *ClosingHandler) HandleClosings(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodGet:
+		h.GetAll(w, r)
+	case http.MethodPost:
+		h.Close(w, r)
+	default:
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+	}
+}
+
+func (h *ClosingHandler) GetAll(w http.ResponseWriter, r *http.Request) {
+	outletID, err := reportOutletID(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	query := r.URL.Query()
+	filter := models.DailyClosingFilter{
+		OutletID:  outletID,
+		StartDate: query.Get("start_date"),
+		EndDate:   query.Get("end_date"),
+	}
+	closings, err := h.service.GetAll(filter)
+	if err != nil {
+		if err == services.ErrInvalidDate {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(closings)
+}
+
+func (h *ClosingHandler) Close(w http.ResponseWriter, r *http.Request) {
+	var req models.DailyClosingRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	actor := middlewares.ActorFromRequest(r)
+	closing, err := h.service.Close(req, actor.Name)
+	if err != nil {
+		switch err {
+		case services.ErrDayClosed, services.ErrShiftsStillOpen:
+			http.Error(w, err.Error(), http.StatusConflict)
+		default:
+			http.Error(w, err.Error(), http.StatusBadRequest)
+		}
+		return
+	}
+
+	h.audit.Record(actor, "daily_closing.create", "daily_closing", closing.ID, nil, closing)
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(closing)
+}
+
+// get /api/closings/{id}
+func (h *ClosingHandler) HandleClosingByID(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	id, err := strconv.Atoi(strings.Trim(strings.TrimPrefix(r.URL.Path, "/api/closings/"), "/"))
+	if err != nil {
+		http.Error(w, "Invalid closing ID", http.StatusBadRequest)
+		return
+	}
+
+	closing, err := h.service.GetByID(id)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(closing)
+}
@@ -0,0 +1,148 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/anggakrnwn/kasir-api/middlewares"
+	"github.com/anggakrnwn/kasir-api/models"
+	"github.com/anggakrnwn/kasir-api/services"
+)
+
+type CustomerHandler struct {
+	service *services.CustomerService
+	audit   *services.AuditService
+}
+
+func NewCustomerHandler(service *services.CustomerService, audit *services.AuditService) *CustomerHandler {
+	return &CustomerHandler{service: service, audit: audit}
+}
+
+// get /api/customer-groups & post /api/customer-groups
+func (h *CustomerHandler) HandleGroups(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodGet:
+		groups, err := h.service.GetAllGroups()
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(groups)
+	case http.MethodPost:
+		var group models.CustomerGroup
+		if err := json.NewDecoder(r.Body).Decode(&group); err != nil {
+			http.Error(w, "Invalid request body", http.StatusBadRequest)
+			return
+		}
+
+		if err := h.service.CreateGroup(&group); err != nil {
+			writeCustomerError(w, err)
+			return
+		}
+
+		h.audit.Record(middlewares.ActorFromRequest(r), "customer_group.create", "customer_group", group.ID, nil, group)
+
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusCreated)
+		json.NewEncoder(w).Encode(group)
+	default:
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+	}
+}
+
+// get /api/customers & post /api/customers
+func (h *CustomerHandler) HandleCustomers(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodGet:
+		customers, err := h.service.GetAll(r.URL.Query().Get("name"))
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(customers)
+	case http.MethodPost:
+		var customer models.Customer
+		if err := json.NewDecoder(r.Body).Decode(&customer); err != nil {
+			http.Error(w, "Invalid request body", http.StatusBadRequest)
+			return
+		}
+
+		if err := h.service.Create(&customer); err != nil {
+			writeCustomerError(w, err)
+			return
+		}
+
+		h.audit.Record(middlewares.ActorFromRequest(r), "customer.create", "customer", customer.ID, nil, customer)
+
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusCreated)
+		json.NewEncoder(w).Encode(customer)
+	default:
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+	}
+}
+
+// get /api/customers/{id} & put /api/customers/{id}
+func (h *CustomerHandler) HandleCustomerByID(w http.ResponseWriter, r *http.Request) {
+	idStr := strings.TrimPrefix(r.URL.Path, "/api/customers/")
+	id, err := strconv.Atoi(idStr)
+	if err != nil {
+		http.Error(w, "Invalid customer ID", http.StatusBadRequest)
+		return
+	}
+
+	switch r.Method {
+	case http.MethodGet:
+		customer, err := h.service.GetByID(id)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusNotFound)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(customer)
+	case http.MethodPut:
+		before, err := h.service.GetByID(id)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusNotFound)
+			return
+		}
+
+		var customer models.Customer
+		if err := json.NewDecoder(r.Body).Decode(&customer); err != nil {
+			http.Error(w, "Invalid request body", http.StatusBadRequest)
+			return
+		}
+
+		customer.ID = id
+		if err := h.service.Update(&customer); err != nil {
+			writeCustomerError(w, err)
+			return
+		}
+
+		h.audit.Record(middlewares.ActorFromRequest(r), "customer.update", "customer", id, before, customer)
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(customer)
+	default:
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+	}
+}
+
+func writeCustomerError(w http.ResponseWriter, err error) {
+	switch err {
+	case services.ErrInvalidCustomerName,
+		services.ErrInvalidGroupCode,
+		services.ErrCustomerGroupMissing,
+		services.ErrInvalidTaxID:
+		http.Error(w, err.Error(), http.StatusBadRequest)
+	default:
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+}
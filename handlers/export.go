@@ -0,0 +1,289 @@
+package handlers
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/csv"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	formatJSON = "json"
+	formatCSV  = "csv"
+	formatXLSX = "xlsx"
+
+	csvContentType  = "text/csv"
+	xlsxContentType = "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet"
+
+	// exportWriteTimeout menggantikan WRITE_TIMEOUT server selama ekspor:
+	// batas waktu diperpanjang setiap kali baris masih mengalir, sehingga
+	// ekspor besar tidak terpotong tetapi klien yang macet tetap diputus
+	exportWriteTimeout = 30 * time.Second
+)
+
+// exportFormat membaca format respons dari query format (json, csv, xlsx),
+// atau dari header Accept jika query kosong. Default json.
+func exportFormat(r *http.Request) (string, error) {
+	switch format := r.URL.Query().Get("format"); format {
+	case "":
+	case formatJSON, formatCSV, formatXLSX:
+		return format, nil
+	default:
+		return "", errors.New("format must be json, csv or xlsx")
+	}
+
+	accept := r.Header.Get("Accept")
+	switch {
+	case strings.Contains(accept, csvContentType):
+		return formatCSV, nil
+	case strings.Contains(accept, xlsxContentType):
+		return formatXLSX, nil
+	}
+
+	return formatJSON, nil
+}
+
+// tableWriter menulis baris tabel ke respons satu per satu, sehingga data
+// besar tidak perlu dimuat seluruhnya ke memori
+type tableWriter interface {
+	WriteRow(cells ...interface{}) error
+	Close() error
+}
+
+// newTableWriter menyiapkan header unduhan dan writer csv atau xlsx.
+// name dipakai sebagai nama file dan nama sheet.
+func newTableWriter(w http.ResponseWriter, format, name string) (tableWriter, error) {
+	deadline := &deadlineTable{rc: http.NewResponseController(w)}
+	if err := deadline.extend(); err != nil {
+		return nil, err
+	}
+
+	if format == formatXLSX {
+		w.Header().Set("Content-Type", xlsxContentType)
+		w.Header().Set("Content-Disposition", fmt.Sprintf(`attachment; filename="%s.xlsx"`, name))
+		xlsx, err := newXLSXWriter(w, name)
+		if err != nil {
+			return nil, err
+		}
+		deadline.tableWriter = xlsx
+		return deadline, nil
+	}
+
+	w.Header().Set("Content-Type", csvContentType+"; charset=utf-8")
+	w.Header().Set("Content-Disposition", fmt.Sprintf(`attachment; filename="%s.csv"`, name))
+	deadline.tableWriter = &csvWriter{w: csv.NewWriter(w)}
+	return deadline, nil
+}
+
+// deadlineTable memperpanjang batas waktu tulis koneksi paling sering sekali
+// per detik selama baris ditulis
+type deadlineTable struct {
+	tableWriter
+	rc       *http.ResponseController
+	extended time.Time
+}
+
+func (d *deadlineTable) WriteRow(cells ...interface{}) error {
+	if time.Since(d.extended) >= time.Second {
+		if err := d.extend(); err != nil {
+			return err
+		}
+	}
+	return d.tableWriter.WriteRow(cells...)
+}
+
+// extend mengabaikan ResponseWriter yang tidak mendukung batas waktu
+func (d *deadlineTable) extend() error {
+	d.extended = time.Now()
+	err := d.rc.SetWriteDeadline(d.extended.Add(exportWriteTimeout))
+	if errors.Is(err, http.ErrNotSupported) {
+		return nil
+	}
+	return err
+}
+
+// writeTable menulis laporan kecil yang sudah ada di memori, rows[0] adalah
+// baris judul kolom. Header respons sudah terkirim saat error terjadi, jadi
+// error hanya dicatat di log.
+func writeTable(w http.ResponseWriter, format, name string, rows [][]interface{}) {
+	table, err := newTableWriter(w, format, name)
+	if err == nil {
+		for _, row := range rows {
+			if err = table.WriteRow(row...); err != nil {
+				break
+			}
+		}
+		if closeErr := table.Close(); err == nil {
+			err = closeErr
+		}
+	}
+	if err != nil {
+		log.Printf("export %s: %v", name, err)
+	}
+}
+
+// cellText mengubah nilai sel menjadi teks, numeric menandai sel angka
+func cellText(value interface{}) (text string, numeric bool) {
+	switch v := value.(type) {
+	case nil:
+		return "", false
+	case string:
+		return v, false
+	case int:
+		return strconv.Itoa(v), true
+	case *int:
+		if v == nil {
+			return "", false
+		}
+		return strconv.Itoa(*v), true
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64), true
+	case time.Time:
+		return v.Format("2006-01-02 15:04:05"), false
+	default:
+		return fmt.Sprint(v), false
+	}
+}
+
+// csvFormulaPrefixes adalah awalan yang membuat spreadsheet membaca sel teks
+// sebagai rumus
+const csvFormulaPrefixes = "=+-@\t\r"
+
+// csvSafe menambahkan ' di depan teks yang bisa dibaca sebagai rumus, mis.
+// nama produk atau catatan "=HYPERLINK(...)". Sel angka tidak diubah supaya
+// nilai minus tetap angka. Sel xlsx tidak perlu karena ditulis sebagai
+// inline string yang tidak pernah dievaluasi.
+func csvSafe(text string) string {
+	if text != "" && strings.ContainsRune(csvFormulaPrefixes, rune(text[0])) {
+		return "'" + text
+	}
+	return text
+}
+
+type csvWriter struct {
+	w *csv.Writer
+}
+
+func (c *csvWriter) WriteRow(cells ...interface{}) error {
+	record := make([]string, len(cells))
+	for i, cell := range cells {
+		text, numeric := cellText(cell)
+		if !numeric {
+			text = csvSafe(text)
+		}
+		record[i] = text
+	}
+	return c.w.Write(record)
+}
+
+func (c *csvWriter) Close() error {
+	c.w.Flush()
+	return c.w.Error()
+}
+
+// xlsxWriter menulis workbook satu sheet langsung ke zip stream. Teks ditulis
+// sebagai inline string supaya tidak perlu sharedStrings yang harus dikumpulkan
+// dulu sebelum sheet ditulis.
+type xlsxWriter struct {
+	zip   *zip.Writer
+	sheet io.Writer
+	row   int
+	buf   bytes.Buffer
+}
+
+func newXLSXWriter(w io.Writer, sheetName string) (*xlsxWriter, error) {
+	var name bytes.Buffer
+	if len(sheetName) > 31 {
+		sheetName = sheetName[:31]
+	}
+	xml.EscapeText(&name, []byte(sheetName))
+
+	parts := []struct{ path, body string }{
+		{"[Content_Types].xml", `<Types xmlns="http://schemas.openxmlformats.org/package/2006/content-types">` +
+			`<Default Extension="rels" ContentType="application/vnd.openxmlformats-package.relationships+xml"/>` +
+			`<Default Extension="xml" ContentType="application/xml"/>` +
+			`<Override PartName="/xl/workbook.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.sheet.main+xml"/>` +
+			`<Override PartName="/xl/worksheets/sheet1.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.worksheet+xml"/>` +
+			`</Types>`},
+		{"_rels/.rels", `<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">` +
+			`<Relationship Id="rId1" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/officeDocument" Target="xl/workbook.xml"/>` +
+			`</Relationships>`},
+		{"xl/workbook.xml", `<workbook xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main" xmlns:r="http://schemas.openxmlformats.org/officeDocument/2006/relationships">` +
+			`<sheets><sheet name="` + name.String() + `" sheetId="1" r:id="rId1"/></sheets>` +
+			`</workbook>`},
+		{"xl/_rels/workbook.xml.rels", `<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">` +
+			`<Relationship Id="rId1" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/worksheet" Target="worksheets/sheet1.xml"/>` +
+			`</Relationships>`},
+	}
+
+	x := &xlsxWriter{zip: zip.NewWriter(w)}
+	for _, part := range parts {
+		f, err := x.zip.Create(part.path)
+		if err != nil {
+			return nil, err
+		}
+		if _, err := io.WriteString(f, xml.Header+part.body); err != nil {
+			return nil, err
+		}
+	}
+
+	sheet, err := x.zip.Create("xl/worksheets/sheet1.xml")
+	if err != nil {
+		return nil, err
+	}
+	_, err = io.WriteString(sheet, xml.Header+`<worksheet xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main"><sheetData>`)
+	if err != nil {
+		return nil, err
+	}
+	x.sheet = sheet
+
+	return x, nil
+}
+
+func (x *xlsxWriter) WriteRow(cells ...interface{}) error {
+	x.row++
+	x.buf.Reset()
+	fmt.Fprintf(&x.buf, `<row r="%d">`, x.row)
+	for i, cell := range cells {
+		text, numeric := cellText(cell)
+		if text == "" {
+			continue
+		}
+		ref := xlsxColumn(i) + strconv.Itoa(x.row)
+		if numeric {
+			fmt.Fprintf(&x.buf, `<c r="%s"><v>%s</v></c>`, ref, text)
+			continue
+		}
+		fmt.Fprintf(&x.buf, `<c r="%s" t="inlineStr"><is><t xml:space="preserve">`, ref)
+		xml.EscapeText(&x.buf, []byte(text))
+		x.buf.WriteString(`</t></is></c>`)
+	}
+	x.buf.WriteString(`</row>`)
+
+	_, err := x.sheet.Write(x.buf.Bytes())
+	return err
+}
+
+func (x *xlsxWriter) Close() error {
+	if _, err := io.WriteString(x.sheet, `</sheetData></worksheet>`); err != nil {
+		return err
+	}
+	return x.zip.Close()
+}
+
+// xlsxColumn mengubah indeks kolom (mulai 0) menjadi huruf kolom: A, B, ..., AA
+func xlsxColumn(index int) string {
+	name := ""
+	for index++; index > 0; index = (index - 1) / 26 {
+		name = string(rune('A'+(index-1)%26)) + name
+	}
+	return name
+}
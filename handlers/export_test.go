@@ -0,0 +1,124 @@
+package handlers
+
+import (
+	"bytes"
+	"encoding/csv"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestXLSXColumn(t *testing.T) {
+	tests := []struct {
+		index int
+		want  string
+	}{
+		{0, "A"},
+		{1, "B"},
+		{25, "Z"},
+		{26, "AA"},
+		{27, "AB"},
+		{51, "AZ"},
+		{52, "BA"},
+		{701, "ZZ"},
+		{702, "AAA"},
+	}
+
+	for _, tt := range tests {
+		if got := xlsxColumn(tt.index); got != tt.want {
+			t.Errorf("xlsxColumn(%d) = %q, want %q", tt.index, got, tt.want)
+		}
+	}
+}
+
+func TestCellText(t *testing.T) {
+	id := 7
+	var noID *int
+
+	tests := []struct {
+		name        string
+		value       interface{}
+		wantText    string
+		wantNumeric bool
+	}{
+		{"nil", nil, "", false},
+		{"string", "Indomie", "Indomie", false},
+		{"int", 12500, "12500", true},
+		{"negative int", -5000, "-5000", true},
+		{"int pointer", &id, "7", true},
+		{"nil int pointer", noID, "", false},
+		{"float", 1.25, "1.25", true},
+		{"whole float", 2.0, "2", true},
+		{"time", time.Date(2024, 1, 20, 9, 5, 0, 0, time.UTC), "2024-01-20 09:05:00", false},
+		{"other", true, "true", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			text, numeric := cellText(tt.value)
+			if text != tt.wantText || numeric != tt.wantNumeric {
+				t.Errorf("cellText(%v) = (%q, %v), want (%q, %v)", tt.value, text, numeric, tt.wantText, tt.wantNumeric)
+			}
+		})
+	}
+}
+
+func TestCSVSafe(t *testing.T) {
+	tests := []struct {
+		text string
+		want string
+	}{
+		{"Indomie Goreng", "Indomie Goreng"},
+		{"=HYPERLINK(\"http://x\")", "'=HYPERLINK(\"http://x\")"},
+		{"+6281234", "'+6281234"},
+		{"-1+1", "'-1+1"},
+		{"@SUM(A1)", "'@SUM(A1)"},
+		{"\tcmd", "'\tcmd"},
+		{"\rcmd", "'\rcmd"},
+		{"a=b", "a=b"},
+		{"", ""},
+	}
+
+	for _, tt := range tests {
+		if got := csvSafe(tt.text); got != tt.want {
+			t.Errorf("csvSafe(%q) = %q, want %q", tt.text, got, tt.want)
+		}
+	}
+}
+
+func TestCSVWriterGuardsTextOnly(t *testing.T) {
+	var buf bytes.Buffer
+	table := &csvWriter{w: csv.NewWriter(&buf)}
+	if err := table.WriteRow("=cmd|'/C calc'!A0", -5000, -1.5, "Kopi"); err != nil {
+		t.Fatal(err)
+	}
+	if err := table.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	want := "'=cmd|'/C calc'!A0,-5000,-1.5,Kopi\n"
+	if buf.String() != want {
+		t.Errorf("csv row = %q, want %q", buf.String(), want)
+	}
+}
+
+func TestNewTableWriterWithoutDeadlineSupport(t *testing.T) {
+	rec := httptest.NewRecorder()
+	table, err := newTableWriter(rec, formatCSV, "transactions")
+	if err != nil {
+		t.Fatalf("newTableWriter: %v", err)
+	}
+	if err := table.WriteRow("id", "name"); err != nil {
+		t.Fatal(err)
+	}
+	if err := table.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	if got := rec.Body.String(); got != "id,name\n" {
+		t.Errorf("body = %q", got)
+	}
+	if got := rec.Header().Get("Content-Disposition"); got != `attachment; filename="transactions.csv"` {
+		t.Errorf("Content-Disposition = %q", got)
+	}
+}
@@ -0,0 +1,128 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/anggakrnwn/kasir-api/middlewares"
+	"github.com/anggakrnwn/kasir-api/models"
+	"github.com/anggakrnwn/kasir-api/services"
+)
+
+type OutletHandler struct {
+	service *services.OutletService
+	audit   *services.AuditService
+}
+
+func NewOutletHandler(service *services.OutletService, audit *services.AuditService) *OutletHandler {
+	return &OutletHandler{service: service, audit: audit}
+}
+
+// get /api/outlets & post /api/outlets
+func (h *OutletHandler) HandleOutlets(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodGet:
+		outlets, err := h.service.GetAll()
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(outlets)
+	case http.MethodPost:
+		h.Create(w, r)
+	default:
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+	}
+}
+
+func (h *OutletHandler) Create(w http.ResponseWriter, r *http.Request) {
+	var outlet models.Outlet
+	if err := json.NewDecoder(r.Body).Decode(&outlet); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	if err := h.service.Create(&outlet); err != nil {
+		if err == services.ErrInvalidOutletName || err == services.ErrInvalidOutletType || err == services.ErrInvalidOutletCode {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		http.Error(w, "internal server error", http.StatusInternalServerError)
+		return
+	}
+
+	h.audit.Record(middlewares.ActorFromRequest(r), "outlet.create", "outlet", outlet.ID, nil, outlet)
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(outlet)
+}
+
+// get /api/outlets/{id} & put /api/outlets/{id}
+func (h *OutletHandler) HandleOutletByID(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(strings.TrimPrefix(r.URL.Path, "/api/outlets/"))
+	if err != nil {
+		http.Error(w, "Invalid outlet ID", http.StatusBadRequest)
+		return
+	}
+
+	before, err := h.service.GetByID(id)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+
+	switch r.Method {
+	case http.MethodGet:
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(before)
+	case http.MethodPut:
+		var outlet models.Outlet
+		if err := json.NewDecoder(r.Body).Decode(&outlet); err != nil {
+			http.Error(w, "Invalid request body", http.StatusBadRequest)
+			return
+		}
+
+		outlet.ID = id
+		outlet.CreatedAt = before.CreatedAt
+		if outlet.Code == "" {
+			outlet.Code = before.Code
+		}
+		if err := h.service.Update(&outlet); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		h.audit.Record(middlewares.ActorFromRequest(r), "outlet.update", "outlet", id, before, outlet)
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(outlet)
+	default:
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+	}
+}
+
+// reportOutletID menentukan outlet untuk laporan dan daftar: kasir selalu
+// dibatasi ke outletnya sendiri, owner memakai query outlet_id (0 = semua)
+func reportOutletID(r *http.Request) (int, error) {
+	if cashier := middlewares.CashierFromContext(r.Context()); cashier != nil {
+		return cashier.OutletID, nil
+	}
+
+	value := r.URL.Query().Get("outlet_id")
+	if value == "" {
+		return 0, nil
+	}
+
+	id, err := strconv.Atoi(value)
+	if err != nil || id < 0 {
+		return 0, errors.New("Invalid outlet_id")
+	}
+
+	return id, nil
+}
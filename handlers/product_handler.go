@@ -1,165 +1,534 @@
-package handlers
-
-import (
-	"encoding/json"
-	"io"
-	"net/http"
-	"strconv"
-	"strings"
-
-	"github.com/anggakrnwn/kasir-api/models"
-	"github.com/anggakrnwn/kasir-api/services"
-)
-
-type ProductHandler struct {
-	service *services.ProductService
-}
-
-func NewProductHandler(service *services.ProductService) *ProductHandler {
-	return &ProductHandler{service: service}
-}
-
-// get /api/product & post /api/product
-func (h *ProductHandler) HandleProduct(w http.ResponseWriter, r *http.Request) {
-	switch r.Method {
-	case http.MethodGet:
-		h.GetAll(w, r)
-	case http.MethodPost:
-		h.Create(w, r)
-	default:
-		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
-	}
-}
-
-func (h *ProductHandler) GetAll(w http.ResponseWriter, r *http.Request) {
-	name := r.URL.Query().Get("name")
-	products, err := h.service.GetAll(name)
-	if err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
-		return
-	}
-
-	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(products)
-}
-
-func (h *ProductHandler) Create(w http.ResponseWriter, r *http.Request) {
-
-	r.Body = http.MaxBytesReader(w, r.Body, 1<<20)
-	defer r.Body.Close()
-
-	decoder := json.NewDecoder(r.Body)
-	decoder.DisallowUnknownFields()
-
-	var product models.Product
-
-	if err := decoder.Decode(&product); err != nil {
-		http.Error(w, "incalid req body", http.StatusBadRequest)
-		return
-	}
-
-	// memastikan 1 req obj
-	if decoder.Decode(&struct{}{}) != io.EOF {
-		http.Error(w, "incalid req body", http.StatusBadRequest)
-		return
-	}
-
-	err := h.service.Create(&product)
-	if err != nil {
-		switch err {
-		case services.ErrInvalidProductName,
-			services.ErrInvalidProductPrice,
-			services.ErrInvalidProductStock:
-			http.Error(w, err.Error(), http.StatusBadRequest)
-		default:
-			http.Error(w, "internal server error", http.StatusInternalServerError)
-		}
-
-		return
-	}
-
-	w.Header().Set("Content-Type", "application/json")
-	w.WriteHeader(http.StatusCreated)
-
-	if err := json.NewEncoder(w).Encode(product); err != nil {
-		http.Error(w, "failed to encode response", http.StatusInternalServerError)
-	}
-
-}
-
-func (h *ProductHandler) HandleProductByID(w http.ResponseWriter, r *http.Request) {
-	switch r.Method {
-	case http.MethodGet:
-		h.GetByID(w, r)
-	case http.MethodPut:
-		h.Update(w, r)
-	case http.MethodDelete:
-		h.Delete(w, r)
-	default:
-		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
-	}
-}
-
-func (h *ProductHandler) GetByID(w http.ResponseWriter, r *http.Request) {
-	idStr := strings.TrimPrefix(r.URL.Path, "/api/product/")
-	id, err := strconv.Atoi(idStr)
-	if err != nil {
-		http.Error(w, "Invalid product ID", http.StatusBadRequest)
-		return
-	}
-
-	product, err := h.service.GetByID(id)
-	if err != nil {
-		http.Error(w, err.Error(), http.StatusNotFound)
-		return
-	}
-
-	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(product)
-}
-
-func (h *ProductHandler) Update(w http.ResponseWriter, r *http.Request) {
-	idStr := strings.TrimPrefix(r.URL.Path, "/api/product/")
-	id, err := strconv.Atoi(idStr)
-	if err != nil {
-		http.Error(w, "Invalid product ID", http.StatusBadRequest)
-		return
-	}
-
-	var product models.Product
-	err = json.NewDecoder(r.Body).Decode(&product)
-	if err != nil {
-		http.Error(w, "Invalid request body", http.StatusBadRequest)
-		return
-	}
-
-	product.ID = id
-	err = h.service.Update(&product)
-	if err != nil {
-		http.Error(w, err.Error(), http.StatusBadRequest)
-		return
-	}
-
-	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(product)
-}
-
-func (h *ProductHandler) Delete(w http.ResponseWriter, r *http.Request) {
-	idStr := strings.TrimPrefix(r.URL.Path, "/api/product/")
-	id, err := strconv.Atoi(idStr)
-	if err != nil {
-		http.Error(w, "Invalid product ID", http.StatusBadRequest)
-		return
-	}
-
-	err = h.service.Delete(id)
-	if err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
-		return
-	}
-
-	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(map[string]string{
-		"message": "Product deleted successfully",
-	})
-}
+package handlers
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/anggakrnwn/kasir-api/middlewares"
+	"github.com/anggakrnwn/kasir-api/models"
+	"github.com/anggakrnwn/kasir-api/services"
+)
+
+type ProductHandler struct {
+	service *services.ProductService
+	audit   *services.AuditService
+}
+
+func NewProductHandler(service *services.ProductService, audit *services.AuditService) *ProductHandler {
+	return &ProductHandler{service: service, audit: audit}
+}
+
+// get /api/product & post /api/product
+func (h *ProductHandler) HandleProduct(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodGet:
+		h.GetAll(w, r)
+	case http.MethodPost:
+		h.Create(w, r)
+	default:
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+	}
+}
+
+func (h *ProductHandler) GetAll(w http.ResponseWriter, r *http.Request) {
+	name := r.URL.Query().Get("name")
+	products, err := h.service.GetAll(name)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(products)
+}
+
+func (h *ProductHandler) Create(w http.ResponseWriter, r *http.Request) {
+
+	r.Body = http.MaxBytesReader(w, r.Body, 1<<20)
+	defer r.Body.Close()
+
+	decoder := json.NewDecoder(r.Body)
+	decoder.DisallowUnknownFields()
+
+	var product models.Product
+
+	if err := decoder.Decode(&product); err != nil {
+		http.Error(w, "incalid req body", http.StatusBadRequest)
+		return
+	}
+
+	// memastikan 1 req obj
+	if decoder.Decode(&struct{}{}) != io.EOF {
+		http.Error(w, "incalid req body", http.StatusBadRequest)
+		return
+	}
+
+	// kasir hanya bisa mengubah stok outletnya sendiri
+	if cashier := middlewares.CashierFromContext(r.Context()); cashier != nil {
+		product.OutletID = cashier.OutletID
+	}
+
+	err := h.service.Create(&product)
+	if err != nil {
+		switch err {
+		case services.ErrInvalidProductName,
+			services.ErrInvalidProductPrice,
+			services.ErrInvalidProductStock,
+			services.ErrInvalidParent,
+			services.ErrInvalidPLU,
+			services.ErrInvalidBundle,
+			services.ErrInvalidOutlet,
+			services.ErrInvalidReorderLevel,
+			services.ErrInvalidProductCost,
+			services.ErrCategoryMissing:
+			http.Error(w, err.Error(), http.StatusBadRequest)
+		default:
+			http.Error(w, "internal server error", http.StatusInternalServerError)
+		}
+
+		return
+	}
+
+	h.audit.Record(middlewares.ActorFromRequest(r), "product.create", "product", product.ID, nil, product)
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+
+	if err := json.NewEncoder(w).Encode(product); err != nil {
+		http.Error(w, "failed to encode response", http.StatusInternalServerError)
+	}
+
+}
+
+// get /api/categories & post /api/categories
+func (h *ProductHandler) HandleCategories(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodGet:
+		categories, err := h.service.GetAllCategories()
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(categories)
+	case http.MethodPost:
+		var category models.Category
+		if err := json.NewDecoder(r.Body).Decode(&category); err != nil {
+			http.Error(w, "Invalid request body", http.StatusBadRequest)
+			return
+		}
+
+		if err := h.service.CreateCategory(&category); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		h.audit.Record(middlewares.ActorFromRequest(r), "category.create", "category", category.ID, nil, category)
+
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusCreated)
+		json.NewEncoder(w).Encode(category)
+	default:
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+	}
+}
+
+func (h *ProductHandler) HandleProductByID(w http.ResponseWriter, r *http.Request) {
+	// sub-resource: /api/product/{id}/price-history, /api/product/{id}/prices[/{price_id}],
+	// /api/product/{id}/price-tiers[/{tier_id}], /api/product/{id}/units[/{unit_id}],
+	// /api/product/{id}/components
+	segments := strings.Split(strings.Trim(strings.TrimPrefix(r.URL.Path, "/api/product/"), "/"), "/")
+	if len(segments) > 1 {
+		h.handleProductSubresource(w, r, segments)
+		return
+	}
+
+	switch r.Method {
+	case http.MethodGet:
+		h.GetByID(w, r)
+	case http.MethodPut:
+		h.Update(w, r)
+	case http.MethodDelete:
+		h.Delete(w, r)
+	default:
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+	}
+}
+
+// HandleBarcode melayani GET /api/barcode/{code} untuk pemindai di kasir
+func (h *ProductHandler) HandleBarcode(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	code := strings.Trim(strings.TrimPrefix(r.URL.Path, "/api/barcode/"), "/")
+	if code == "" {
+		http.Error(w, "Invalid barcode", http.StatusBadRequest)
+		return
+	}
+
+	item, err := h.service.ResolveBarcode(code)
+	if err != nil {
+		if err == services.ErrBarcodeNotFound {
+			http.Error(w, err.Error(), http.StatusNotFound)
+			return
+		}
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(item)
+}
+
+func (h *ProductHandler) GetByID(w http.ResponseWriter, r *http.Request) {
+	idStr := strings.TrimPrefix(r.URL.Path, "/api/product/")
+	id, err := strconv.Atoi(idStr)
+	if err != nil {
+		http.Error(w, "Invalid product ID", http.StatusBadRequest)
+		return
+	}
+
+	product, err := h.service.GetByID(id)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(product)
+}
+
+func (h *ProductHandler) Update(w http.ResponseWriter, r *http.Request) {
+	idStr := strings.TrimPrefix(r.URL.Path, "/api/product/")
+	id, err := strconv.Atoi(idStr)
+	if err != nil {
+		http.Error(w, "Invalid product ID", http.StatusBadRequest)
+		return
+	}
+
+	var product models.Product
+	err = json.NewDecoder(r.Body).Decode(&product)
+	if err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	before, err := h.service.GetByID(id)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+
+	product.ID = id
+	if cashier := middlewares.CashierFromContext(r.Context()); cashier != nil {
+		product.OutletID = cashier.OutletID
+	}
+	err = h.service.Update(&product)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	h.audit.Record(middlewares.ActorFromRequest(r), "product.update", "product", id, before, product)
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(product)
+}
+
+func (h *ProductHandler) Delete(w http.ResponseWriter, r *http.Request) {
+	idStr := strings.TrimPrefix(r.URL.Path, "/api/product/")
+	id, err := strconv.Atoi(idStr)
+	if err != nil {
+		http.Error(w, "Invalid product ID", http.StatusBadRequest)
+		return
+	}
+
+	before, err := h.service.GetByID(id)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+
+	err = h.service.Delete(id)
+	if err != nil {
+		if err == services.ErrProductHasHistory {
+			http.Error(w, err.Error(), http.StatusConflict)
+			return
+		}
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	h.audit.Record(middlewares.ActorFromRequest(r), "product.delete", "product", id, before, nil)
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]string{
+		"message": "Product deleted successfully",
+	})
+}
+
+func (h *ProductHandler) handleProductSubresource(w http.ResponseWriter, r *http.Request, segments []string) {
+	id, err := strconv.Atoi(segments[0])
+	if err != nil {
+		http.Error(w, "Invalid product ID", http.StatusBadRequest)
+		return
+	}
+
+	switch {
+	case len(segments) == 2 && segments[1] == "price-history" && r.Method == http.MethodGet:
+		h.GetPriceHistory(w, r, id)
+	case len(segments) == 2 && segments[1] == "prices" && r.Method == http.MethodPost:
+		h.SchedulePrice(w, r, id)
+	case len(segments) == 3 && segments[1] == "prices" && r.Method == http.MethodDelete:
+		priceID, err := strconv.Atoi(segments[2])
+		if err != nil {
+			http.Error(w, "Invalid price ID", http.StatusBadRequest)
+			return
+		}
+		h.CancelScheduledPrice(w, r, id, priceID)
+	case len(segments) == 2 && segments[1] == "price-tiers" && r.Method == http.MethodGet:
+		h.GetPriceTiers(w, r, id)
+	case len(segments) == 2 && segments[1] == "price-tiers" && r.Method == http.MethodPost:
+		h.SetPriceTier(w, r, id)
+	case len(segments) == 3 && segments[1] == "price-tiers" && r.Method == http.MethodDelete:
+		tierID, err := strconv.Atoi(segments[2])
+		if err != nil {
+			http.Error(w, "Invalid tier ID", http.StatusBadRequest)
+			return
+		}
+		h.DeletePriceTier(w, r, id, tierID)
+	case len(segments) == 2 && segments[1] == "units" && r.Method == http.MethodGet:
+		h.GetUnits(w, r, id)
+	case len(segments) == 2 && segments[1] == "units" && r.Method == http.MethodPost:
+		h.SetUnit(w, r, id)
+	case len(segments) == 3 && segments[1] == "units" && r.Method == http.MethodDelete:
+		unitID, err := strconv.Atoi(segments[2])
+		if err != nil {
+			http.Error(w, "Invalid unit ID", http.StatusBadRequest)
+			return
+		}
+		h.DeleteUnit(w, r, id, unitID)
+	case len(segments) == 2 && segments[1] == "components" && r.Method == http.MethodGet:
+		h.GetComponents(w, r, id)
+	case len(segments) == 2 && segments[1] == "components" && r.Method == http.MethodPut:
+		h.SetComponents(w, r, id)
+	case len(segments) <= 3 && (segments[1] == "price-history" || segments[1] == "prices" ||
+		segments[1] == "price-tiers" || segments[1] == "units" || segments[1] == "components"):
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+	default:
+		http.NotFound(w, r)
+	}
+}
+
+// get /api/product/{id}/price-history
+func (h *ProductHandler) GetPriceHistory(w http.ResponseWriter, r *http.Request, id int) {
+	prices, err := h.service.GetPriceHistory(id)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(prices)
+}
+
+// post /api/product/{id}/prices
+func (h *ProductHandler) SchedulePrice(w http.ResponseWriter, r *http.Request, id int) {
+	var req models.SchedulePriceRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	price, err := h.service.SchedulePrice(id, req)
+	if err != nil {
+		switch err {
+		case services.ErrInvalidProductPrice,
+			services.ErrInvalidEffectiveAt:
+			http.Error(w, err.Error(), http.StatusBadRequest)
+		default:
+			http.Error(w, err.Error(), http.StatusNotFound)
+		}
+		return
+	}
+
+	h.audit.Record(middlewares.ActorFromRequest(r), "product.price_schedule", "product", id, nil, price)
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(price)
+}
+
+// delete /api/product/{id}/prices/{price_id}
+func (h *ProductHandler) CancelScheduledPrice(w http.ResponseWriter, r *http.Request, id, priceID int) {
+	if err := h.service.CancelScheduledPrice(id, priceID); err != nil {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+
+	h.audit.Record(middlewares.ActorFromRequest(r), "product.price_cancel", "product", id, map[string]int{"price_id": priceID}, nil)
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]string{
+		"message": "Scheduled price cancelled successfully",
+	})
+}
+
+// get /api/product/{id}/price-tiers
+func (h *ProductHandler) GetPriceTiers(w http.ResponseWriter, r *http.Request, id int) {
+	tiers, err := h.service.GetPriceTiers(id)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(tiers)
+}
+
+// post /api/product/{id}/price-tiers
+func (h *ProductHandler) SetPriceTier(w http.ResponseWriter, r *http.Request, id int) {
+	var tier models.PriceTier
+	if err := json.NewDecoder(r.Body).Decode(&tier); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	tier.ProductID = id
+	if err := h.service.SetPriceTier(&tier); err != nil {
+		switch err {
+		case services.ErrInvalidMinQuantity,
+			services.ErrInvalidProductPrice:
+			http.Error(w, err.Error(), http.StatusBadRequest)
+		default:
+			http.Error(w, err.Error(), http.StatusNotFound)
+		}
+		return
+	}
+
+	h.audit.Record(middlewares.ActorFromRequest(r), "product.price_tier_set", "product", id, nil, tier)
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(tier)
+}
+
+// delete /api/product/{id}/price-tiers/{tier_id}
+func (h *ProductHandler) DeletePriceTier(w http.ResponseWriter, r *http.Request, id, tierID int) {
+	if err := h.service.DeletePriceTier(id, tierID); err != nil {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+
+	h.audit.Record(middlewares.ActorFromRequest(r), "product.price_tier_delete", "product", id, map[string]int{"tier_id": tierID}, nil)
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]string{
+		"message": "Price tier deleted successfully",
+	})
+}
+
+// get /api/product/{id}/units
+func (h *ProductHandler) GetUnits(w http.ResponseWriter, r *http.Request, id int) {
+	units, err := h.service.GetUnits(id)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(units)
+}
+
+// post /api/product/{id}/units
+func (h *ProductHandler) SetUnit(w http.ResponseWriter, r *http.Request, id int) {
+	var unit models.ProductUnit
+	if err := json.NewDecoder(r.Body).Decode(&unit); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	unit.ProductID = id
+	if err := h.service.SetUnit(&unit); err != nil {
+		switch err {
+		case services.ErrInvalidUnit,
+			services.ErrInvalidProductPrice:
+			http.Error(w, err.Error(), http.StatusBadRequest)
+		default:
+			http.Error(w, err.Error(), http.StatusNotFound)
+		}
+		return
+	}
+
+	h.audit.Record(middlewares.ActorFromRequest(r), "product.unit_set", "product", id, nil, unit)
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(unit)
+}
+
+// delete /api/product/{id}/units/{unit_id}
+func (h *ProductHandler) DeleteUnit(w http.ResponseWriter, r *http.Request, id, unitID int) {
+	if err := h.service.DeleteUnit(id, unitID); err != nil {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+
+	h.audit.Record(middlewares.ActorFromRequest(r), "product.unit_delete", "product", id, map[string]int{"unit_id": unitID}, nil)
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]string{
+		"message": "Unit deleted successfully",
+	})
+}
+
+// get /api/product/{id}/components
+func (h *ProductHandler) GetComponents(w http.ResponseWriter, r *http.Request, id int) {
+	components, err := h.service.GetComponents(id)
+	if err != nil {
+		if err == services.ErrNotBundle {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(components)
+}
+
+// put /api/product/{id}/components
+func (h *ProductHandler) SetComponents(w http.ResponseWriter, r *http.Request, id int) {
+	var components []models.BundleComponent
+	if err := json.NewDecoder(r.Body).Decode(&components); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	before, err := h.service.GetComponents(id)
+	if err != nil {
+		if err == services.ErrNotBundle {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+
+	if err := h.service.SetComponents(id, components); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	h.audit.Record(middlewares.ActorFromRequest(r), "product.components_set", "product", id, before, components)
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(components)
+}
@@ -0,0 +1,140 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/anggakrnwn/kasir-api/middlewares"
+	"github.com/anggakrnwn/kasir-api/models"
+	"github.com/anggakrnwn/kasir-api/services"
+)
+
+type ReservationHandler struct {
+	service *services.ReservationService
+	audit   *services.AuditService
+}
+
+func NewReservationHandler(service *services.ReservationService, audit *services.AuditService) *ReservationHandler {
+	return &ReservationHandler{service: service, audit: audit}
+}
+
+// get /api/reservations & post /api/reservations
+func (h *ReservationHandler) HandleReservations(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodGet:
+		h.GetAll(w, r)
+	case http.MethodPost:
+		h.Create(w, r)
+	default:
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+	}
+}
+
+func (h *ReservationHandler) GetAll(w http.ResponseWriter, r *http.Request) {
+	outletID, err := reportOutletID(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	filter := models.StockReservationFilter{Status: r.URL.Query().Get("status"), OutletID: outletID}
+	reservations, err := h.service.GetAll(filter)
+	if err != nil {
+		if err == services.ErrInvalidReservationStatus {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(reservations)
+}
+
+func (h *ReservationHandler) Create(w http.ResponseWriter, r *http.Request) {
+	var req models.StockReservationRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	// kasir hanya bisa menahan stok outletnya sendiri
+	if cashier := middlewares.CashierFromContext(r.Context()); cashier != nil {
+		req.OutletID = cashier.OutletID
+	}
+
+	actor := middlewares.ActorFromRequest(r)
+	reservation, err := h.service.Create(req, actor.Name)
+	if err != nil {
+		if err == services.ErrReservationsDisabled {
+			http.Error(w, err.Error(), http.StatusConflict)
+			return
+		}
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	h.audit.Record(actor, "stock_reservation.create", "stock_reservation", reservation.ID, nil, reservation)
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(reservation)
+}
+
+// get /api/reservations/{id} & post /api/reservations/{id}/release
+func (h *ReservationHandler) HandleReservationByID(w http.ResponseWriter, r *http.Request) {
+	segments := strings.Split(strings.Trim(strings.TrimPrefix(r.URL.Path, "/api/reservations/"), "/"), "/")
+	id, err := strconv.Atoi(segments[0])
+	if err != nil {
+		http.Error(w, "Invalid reservation ID", http.StatusBadRequest)
+		return
+	}
+
+	reservation, err := h.service.GetByID(id)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+
+	// kasir hanya bisa mengakses reservasi outletnya sendiri
+	cashier := middlewares.CashierFromContext(r.Context())
+	if cashier != nil && cashier.OutletID != reservation.OutletID {
+		http.Error(w, "Reservation belongs to another outlet", http.StatusForbidden)
+		return
+	}
+
+	switch {
+	case len(segments) == 1:
+		if r.Method != http.MethodGet {
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(reservation)
+	case len(segments) == 2 && segments[1] == "release":
+		if r.Method != http.MethodPost {
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+
+		released, err := h.service.Release(id)
+		if err != nil {
+			if err == services.ErrReservationNotActive {
+				http.Error(w, err.Error(), http.StatusConflict)
+				return
+			}
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		h.audit.Record(middlewares.ActorFromRequest(r), "stock_reservation.release", "stock_reservation", id, reservation, released)
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(released)
+	default:
+		http.NotFound(w, r)
+	}
+}
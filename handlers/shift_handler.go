@@ -0,0 +1,240 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/anggakrnwn/kasir-api/middlewares"
+	"github.com/anggakrnwn/kasir-api/models"
+	"github.com/anggakrnwn/kasir-api/services"
+)
+
+type ShiftHandler struct {
+	service *services.ShiftService
+	audit   *services.AuditService
+}
+
+func NewShiftHandler(service *services.ShiftService, audit *services.AuditService) *ShiftHandler {
+	return &ShiftHandler{service: service, audit: audit}
+}
+
+// post /api/shifts/open
+func (h *ShiftHandler) HandleOpen(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	cashier := middlewares.CashierFromContext(r.Context())
+	if cashier == nil {
+		http.Error(w, "Cashier API Key Required", http.StatusForbidden)
+		return
+	}
+
+	var req models.OpenShiftRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	shift, err := h.service.Open(cashier.ID, req)
+	if err != nil {
+		writeShiftError(w, err)
+		return
+	}
+
+	h.audit.Record(middlewares.ActorFromRequest(r), "shift.open", "shift", shift.ID, nil, shift)
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(shift)
+}
+
+// post /api/shifts/close
+func (h *ShiftHandler) HandleClose(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	cashier := middlewares.CashierFromContext(r.Context())
+	if cashier == nil {
+		http.Error(w, "Cashier API Key Required", http.StatusForbidden)
+		return
+	}
+
+	var req models.CloseShiftRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	report, err := h.service.Close(cashier.ID, req)
+	if err != nil {
+		writeShiftError(w, err)
+		return
+	}
+
+	h.audit.Record(middlewares.ActorFromRequest(r), "shift.close", "shift", report.Shift.ID, nil, report.Shift)
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(report)
+}
+
+// post /api/shifts/pay-in
+func (h *ShiftHandler) HandlePayIn(w http.ResponseWriter, r *http.Request) {
+	h.handleCashMovement(w, r, "shift.pay_in", h.service.PayIn)
+}
+
+// post /api/shifts/pay-out
+func (h *ShiftHandler) HandlePayOut(w http.ResponseWriter, r *http.Request) {
+	h.handleCashMovement(w, r, "shift.pay_out", h.service.PayOut)
+}
+
+func (h *ShiftHandler) handleCashMovement(w http.ResponseWriter, r *http.Request, action string,
+	record func(int, models.CashMovementRequest) (*models.CashMovement, error)) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	cashier := middlewares.CashierFromContext(r.Context())
+	if cashier == nil {
+		http.Error(w, "Cashier API Key Required", http.StatusForbidden)
+		return
+	}
+
+	var req models.CashMovementRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	movement, err := record(cashier.ID, req)
+	if err != nil {
+		writeShiftError(w, err)
+		return
+	}
+
+	h.audit.Record(middlewares.ActorFromRequest(r), action, "cash_movement", movement.ID, nil, movement)
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(movement)
+}
+
+// get /api/shifts/current
+func (h *ShiftHandler) HandleCurrent(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	cashier := middlewares.CashierFromContext(r.Context())
+	if cashier == nil {
+		http.Error(w, "Cashier API Key Required", http.StatusForbidden)
+		return
+	}
+
+	report, err := h.service.GetCurrentReport(cashier.ID)
+	if err != nil {
+		writeShiftError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(report)
+}
+
+// get /api/shifts/{id} & post /api/shifts/{id}/close
+func (h *ShiftHandler) HandleShiftByID(w http.ResponseWriter, r *http.Request) {
+	segments := strings.Split(strings.Trim(strings.TrimPrefix(r.URL.Path, "/api/shifts/"), "/"), "/")
+	id, err := strconv.Atoi(segments[0])
+	if err != nil {
+		http.Error(w, "Invalid shift ID", http.StatusBadRequest)
+		return
+	}
+
+	switch {
+	case len(segments) == 2 && segments[1] == "close":
+		h.forceClose(w, r, id)
+		return
+	case len(segments) != 1:
+		http.NotFound(w, r)
+		return
+	}
+
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	report, err := h.service.GetReport(id)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+
+	// kasir hanya boleh melihat laporan shift miliknya sendiri
+	if cashier := middlewares.CashierFromContext(r.Context()); cashier != nil && cashier.ID != report.Shift.CashierID {
+		http.Error(w, "shift tidak ditemukan", http.StatusNotFound)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(report)
+}
+
+// forceClose menutup shift kasir lain, hanya untuk owner
+func (h *ShiftHandler) forceClose(w http.ResponseWriter, r *http.Request, id int) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	if middlewares.CashierFromContext(r.Context()) != nil {
+		http.Error(w, "Owner API Key Required", http.StatusForbidden)
+		return
+	}
+
+	before, err := h.service.GetReport(id)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+
+	var req models.CloseShiftRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	report, err := h.service.ForceClose(id, req)
+	if err != nil {
+		writeShiftError(w, err)
+		return
+	}
+
+	h.audit.Record(middlewares.ActorFromRequest(r), "shift.force_close", "shift", id, before.Shift, report.Shift)
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(report)
+}
+
+func writeShiftError(w http.ResponseWriter, err error) {
+	switch err {
+	case services.ErrInvalidOpeningFloat,
+		services.ErrInvalidCountedCash,
+		services.ErrInvalidCashAmount,
+		services.ErrEmptyCashReason:
+		http.Error(w, err.Error(), http.StatusBadRequest)
+	case services.ErrShiftAlreadyOpen,
+		services.ErrNoOpenShift,
+		services.ErrShiftNotOpen,
+		services.ErrDayClosed:
+		http.Error(w, err.Error(), http.StatusConflict)
+	default:
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+}
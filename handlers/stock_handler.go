@@ -0,0 +1,208 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/anggakrnwn/kasir-api/middlewares"
+	"github.com/anggakrnwn/kasir-api/models"
+	"github.com/anggakrnwn/kasir-api/services"
+)
+
+type StockHandler struct {
+	service *services.StockService
+	audit   *services.AuditService
+}
+
+func NewStockHandler(service *services.StockService, audit *services.AuditService) *StockHandler {
+	return &StockHandler{service: service, audit: audit}
+}
+
+// post /api/stock/receipts
+func (h *StockHandler) HandleReceipts(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	var req models.StockReceiptRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	// kasir hanya bisa menerima barang untuk outletnya sendiri
+	if cashier := middlewares.CashierFromContext(r.Context()); cashier != nil {
+		req.OutletID = cashier.OutletID
+	}
+
+	receipt, err := h.service.CreateReceipt(req)
+	if err != nil {
+		switch err {
+		case services.ErrEmptyReceipt,
+			services.ErrInvalidQuantity,
+			services.ErrInvalidExpiryDate,
+			services.ErrInvalidUnitCost,
+			services.ErrInvalidOutlet:
+			http.Error(w, err.Error(), http.StatusBadRequest)
+		default:
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+		}
+		return
+	}
+
+	h.audit.Record(middlewares.ActorFromRequest(r), "stock.receipt", "stock_receipt", receipt.ID, nil, receipt)
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(receipt)
+}
+
+// get /api/stock/receipts/{id}
+func (h *StockHandler) HandleReceiptByID(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	idStr := strings.TrimPrefix(r.URL.Path, "/api/stock/receipts/")
+	id, err := strconv.Atoi(idStr)
+	if err != nil {
+		http.Error(w, "Invalid receipt ID", http.StatusBadRequest)
+		return
+	}
+
+	receipt, err := h.service.GetReceipt(id)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(receipt)
+}
+
+// get /api/stock/movements?product_id=&reason=&limit=&offset=
+func (h *StockHandler) HandleMovements(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	q := r.URL.Query()
+	filter := models.StockMovementFilter{Reason: q.Get("reason")}
+
+	intParams := map[string]*int{
+		"product_id": &filter.ProductID,
+		"limit":      &filter.Limit,
+		"offset":     &filter.Offset,
+	}
+	for name, target := range intParams {
+		value := q.Get(name)
+		if value == "" {
+			continue
+		}
+		n, err := strconv.Atoi(value)
+		if err != nil {
+			http.Error(w, "Invalid "+name, http.StatusBadRequest)
+			return
+		}
+		*target = n
+	}
+
+	outletID, err := reportOutletID(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	filter.OutletID = outletID
+
+	movements, err := h.service.GetMovements(filter)
+	if err != nil {
+		if err == services.ErrInvalidMovementLimit {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(movements)
+}
+
+// get /api/inventory/expiring?days=30
+func (h *StockHandler) HandleExpiring(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	var days *int
+	if value := r.URL.Query().Get("days"); value != "" {
+		n, err := strconv.Atoi(value)
+		if err != nil {
+			http.Error(w, "Invalid days", http.StatusBadRequest)
+			return
+		}
+		days = &n
+	}
+
+	outletID, err := reportOutletID(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	lots, err := h.service.GetExpiringLots(days, outletID)
+	if err != nil {
+		if err == services.ErrInvalidDays {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(lots)
+}
+
+// get /api/report/inventory-valuation?date=YYYY-MM-DD&method=average|fifo
+func (h *StockHandler) HandleInventoryValuation(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	format, err := exportFormat(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	valuation, err := h.service.GetInventoryValuation(r.URL.Query().Get("date"), r.URL.Query().Get("method"))
+	if err != nil {
+		if err == services.ErrInvalidDate || err == services.ErrInvalidCostingMethod {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	if format != formatJSON {
+		rows := [][]interface{}{{"product_id", "product_name", "category_name", "unit", "quantity", "unit_cost", "value"}}
+		for _, p := range valuation.Products {
+			rows = append(rows, []interface{}{p.ProductID, p.ProductName, p.CategoryName, p.Unit, p.Quantity, p.UnitCost, p.Value})
+		}
+		rows = append(rows, []interface{}{nil, "Total", nil, nil, nil, nil, valuation.TotalValue})
+		writeTable(w, format, "inventory-valuation-"+valuation.Date, rows)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(valuation)
+}
@@ -0,0 +1,183 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/anggakrnwn/kasir-api/middlewares"
+	"github.com/anggakrnwn/kasir-api/models"
+	"github.com/anggakrnwn/kasir-api/services"
+)
+
+type StockTakeHandler struct {
+	service *services.StockTakeService
+	audit   *services.AuditService
+}
+
+func NewStockTakeHandler(service *services.StockTakeService, audit *services.AuditService) *StockTakeHandler {
+	return &StockTakeHandler{service: service, audit: audit}
+}
+
+// get /api/stock-takes & post /api/stock-takes
+func (h *StockTakeHandler) HandleStockTakes(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodGet:
+		h.GetAll(w, r)
+	case http.MethodPost:
+		h.Start(w, r)
+	default:
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+	}
+}
+
+func (h *StockTakeHandler) GetAll(w http.ResponseWriter, r *http.Request) {
+	outletID, err := reportOutletID(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	filter := models.StockTakeFilter{Status: r.URL.Query().Get("status"), OutletID: outletID}
+	takes, err := h.service.GetAll(filter)
+	if err != nil {
+		if err == services.ErrInvalidStockTakeStatus {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(takes)
+}
+
+func (h *StockTakeHandler) Start(w http.ResponseWriter, r *http.Request) {
+	var req models.StockTakeRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	// kasir hanya bisa membuka opname di outletnya sendiri
+	if cashier := middlewares.CashierFromContext(r.Context()); cashier != nil {
+		req.OutletID = cashier.OutletID
+	}
+
+	actor := middlewares.ActorFromRequest(r)
+	take, err := h.service.Start(req, actor.Name)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	h.audit.Record(actor, "stock_take.start", "stock_take", take.ID, nil, take)
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(take)
+}
+
+// get /api/stock-takes/{id}, get /api/stock-takes/{id}/shrinkage,
+// post /api/stock-takes/{id}/counts, /post & /cancel
+func (h *StockTakeHandler) HandleStockTakeByID(w http.ResponseWriter, r *http.Request) {
+	segments := strings.Split(strings.Trim(strings.TrimPrefix(r.URL.Path, "/api/stock-takes/"), "/"), "/")
+	id, err := strconv.Atoi(segments[0])
+	if err != nil {
+		http.Error(w, "Invalid stock take ID", http.StatusBadRequest)
+		return
+	}
+
+	take, err := h.service.GetByID(id)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+
+	// kasir hanya bisa mengakses opname outletnya sendiri
+	cashier := middlewares.CashierFromContext(r.Context())
+	if cashier != nil && cashier.OutletID != take.OutletID {
+		http.Error(w, "Stock take belongs to another outlet", http.StatusForbidden)
+		return
+	}
+
+	action := ""
+	if len(segments) == 2 {
+		action = segments[1]
+	}
+
+	switch {
+	case len(segments) > 2:
+		http.NotFound(w, r)
+	case action == "" || action == "shrinkage":
+		if r.Method != http.MethodGet {
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+
+		var result interface{} = take
+		if action == "shrinkage" {
+			if result, err = h.service.GetShrinkageReport(id); err != nil {
+				http.Error(w, err.Error(), http.StatusInternalServerError)
+				return
+			}
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(result)
+	case action == "counts" || action == "post" || action == "cancel":
+		if r.Method != http.MethodPost {
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+
+		// posting dan pembatalan mengubah stok, hanya pemilik
+		if action != "counts" && cashier != nil {
+			http.Error(w, "Only the owner can post or cancel a stock take", http.StatusForbidden)
+			return
+		}
+
+		h.update(w, r, take, action)
+	default:
+		http.NotFound(w, r)
+	}
+}
+
+func (h *StockTakeHandler) update(w http.ResponseWriter, r *http.Request, before *models.StockTake, action string) {
+	actor := middlewares.ActorFromRequest(r)
+
+	var take *models.StockTake
+	var err error
+	switch action {
+	case "counts":
+		var req models.StockTakeCountRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			http.Error(w, "Invalid request body", http.StatusBadRequest)
+			return
+		}
+		take, err = h.service.SubmitCounts(before.ID, req, actor.Name)
+	case "post":
+		take, err = h.service.Post(before.ID, actor.Name)
+	case "cancel":
+		take, err = h.service.Cancel(before.ID)
+	}
+	if err != nil {
+		if err == services.ErrStockTakeNotOpen {
+			http.Error(w, err.Error(), http.StatusConflict)
+			return
+		}
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	// hitungan per perangkat bisa sangat sering, yang diaudit hanya posting
+	// dan pembatalan
+	if action != "counts" {
+		h.audit.Record(actor, "stock_take."+action, "stock_take", take.ID, before, take)
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(take)
+}
@@ -1,77 +1,512 @@
-package handlers
-
-import (
-	"encoding/json"
-	"net/http"
-
-	"github.com/anggakrnwn/kasir-api/models"
-	"github.com/anggakrnwn/kasir-api/services"
-)
-
-type TransactionHandler struct {
-	service *services.TransactionService
-}
-
-func NewTransactionHandler(service *services.TransactionService) *TransactionHandler {
-	return &TransactionHandler{service: service}
-}
-
-func (h *TransactionHandler) HandleCheckout(w http.ResponseWriter, r *http.Request) {
-	switch r.Method {
-	case http.MethodPost:
-		h.Checkout(w, r)
-	default:
-		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
-	}
-}
-
-func (h *TransactionHandler) Checkout(w http.ResponseWriter, r *http.Request) {
-	var req models.CheckoutRequest
-	err := json.NewDecoder(r.Body).Decode(&req)
-	if err != nil {
-		http.Error(w, "Invalid request body", http.StatusBadRequest)
-		return
-	}
-
-	transaction, err := h.service.Checkout(req.Items)
-	if err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
-		return
-	}
-
-	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(transaction)
-}
-
-func (h *TransactionHandler) HandleReport(w http.ResponseWriter, r *http.Request) {
-	switch r.Method {
-	case http.MethodGet:
-		h.GetReport(w, r)
-	default:
-		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
-	}
-}
-
-func (h *TransactionHandler) GetReport(w http.ResponseWriter, r *http.Request) {
-	startDate := r.URL.Query().Get("start_date")
-	endDate := r.URL.Query().Get("end_date")
-
-	var summary *models.SalesSummary
-	var err error
-
-	if startDate != "" && endDate != "" {
-		// Get report for date range
-		summary, err = h.service.GetSalesReport(startDate, endDate)
-	} else {
-		// Get today's report
-		summary, err = h.service.GetTodaySalesSummary()
-	}
-
-	if err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
-		return
-	}
-
-	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(summary)
-}
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/anggakrnwn/kasir-api/middlewares"
+	"github.com/anggakrnwn/kasir-api/models"
+	"github.com/anggakrnwn/kasir-api/services"
+)
+
+type TransactionHandler struct {
+	service  *services.TransactionService
+	receipts *services.ReceiptService
+	invoices *services.InvoiceService
+	audit    *services.AuditService
+}
+
+func NewTransactionHandler(service *services.TransactionService, receipts *services.ReceiptService, invoices *services.InvoiceService, audit *services.AuditService) *TransactionHandler {
+	return &TransactionHandler{service: service, receipts: receipts, invoices: invoices, audit: audit}
+}
+
+func (h *TransactionHandler) HandleCheckout(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodPost:
+		h.Checkout(w, r)
+	default:
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+	}
+}
+
+func (h *TransactionHandler) Checkout(w http.ResponseWriter, r *http.Request) {
+	cashier := middlewares.CashierFromContext(r.Context())
+	if cashier == nil {
+		http.Error(w, "Cashier API Key Required", http.StatusForbidden)
+		return
+	}
+
+	var req models.CheckoutRequest
+	err := json.NewDecoder(r.Body).Decode(&req)
+	if err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	transaction, err := h.service.Checkout(cashier.ID, req)
+	if err != nil {
+		writeCheckoutError(w, err)
+		return
+	}
+
+	h.audit.Record(middlewares.ActorFromRequest(r), "transaction.checkout", "transaction", transaction.ID, nil, transaction)
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(transaction)
+}
+
+// writeCheckoutError dipakai checkout langsung dan checkout keranjang. Error
+// dari repository membawa pesan rinci, jadi dicocokkan dengan errors.Is.
+func writeCheckoutError(w http.ResponseWriter, err error) {
+	switch {
+	case isAnyError(err,
+		services.ErrEmptyCheckout,
+		services.ErrInvalidQuantity,
+		services.ErrInvalidPaymentMethod,
+		services.ErrInvalidAmountPaid,
+		services.ErrBarcodeNotFound,
+		services.ErrProductNotFound,
+		services.ErrProductHasVariants,
+		services.ErrNotWeighed,
+		services.ErrUnknownUnit,
+		services.ErrAmountPaidTooLow,
+		services.ErrCustomerNotFound,
+		services.ErrReservationNotFound):
+		http.Error(w, err.Error(), http.StatusBadRequest)
+	case isAnyError(err,
+		services.ErrNoOpenShift,
+		services.ErrShiftNotOpen,
+		services.ErrDayClosed,
+		services.ErrInsufficientStock,
+		services.ErrEmptyBundle,
+		services.ErrReservationNotActive,
+		services.ErrCartNotCheckoutable):
+		http.Error(w, err.Error(), http.StatusConflict)
+	default:
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+}
+
+func isAnyError(err error, targets ...error) bool {
+	for _, target := range targets {
+		if errors.Is(err, target) {
+			return true
+		}
+	}
+	return false
+}
+
+func (h *TransactionHandler) HandleReport(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodGet:
+		h.GetReport(w, r)
+	default:
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+	}
+}
+
+func (h *TransactionHandler) GetReport(w http.ResponseWriter, r *http.Request) {
+	startDate := r.URL.Query().Get("start_date")
+	endDate := r.URL.Query().Get("end_date")
+
+	format, err := exportFormat(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	outletID, err := reportOutletID(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	var summary *models.SalesSummary
+
+	if startDate != "" && endDate != "" {
+		// Get report for date range
+		summary, err = h.service.GetSalesReport(startDate, endDate, outletID)
+	} else {
+		// Get today's report
+		summary, err = h.service.GetTodaySalesSummary(outletID)
+	}
+
+	if err != nil {
+		if err == services.ErrInvalidDate || err == services.ErrInvalidDateRange {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	if format != formatJSON {
+		writeTable(w, format, "sales-report", salesSummaryTable(summary))
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(summary)
+}
+
+// salesSummaryTable: satu baris per outlet (laporan gabungan) lalu baris total
+func salesSummaryTable(summary *models.SalesSummary) [][]interface{} {
+	rows := [][]interface{}{{"outlet_id", "outlet_name", "total_revenue", "total_transaksi"}}
+	for _, o := range summary.Outlets {
+		rows = append(rows, []interface{}{o.OutletID, o.OutletName, o.TotalRevenue, o.TotalTransactions})
+	}
+	rows = append(rows, []interface{}{summary.OutletID, "Total", summary.TotalRevenue, summary.TotalTransactions})
+
+	return rows
+}
+
+// get /api/report/cash-flow?date=YYYY-MM-DD
+func (h *TransactionHandler) HandleCashFlowReport(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	format, err := exportFormat(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	outletID, err := reportOutletID(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	report, err := h.service.GetCashFlowReport(r.URL.Query().Get("date"), outletID)
+	if err != nil {
+		if err == services.ErrInvalidDate {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	if format != formatJSON {
+		writeTable(w, format, "cash-flow-"+report.Date, cashFlowTable(report))
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(report)
+}
+
+// cashFlowTable: penjualan per metode bayar, setiap pay-in/pay-out, lalu
+// ringkasan kas
+func cashFlowTable(report *models.CashFlowReport) [][]interface{} {
+	rows := [][]interface{}{{"time", "type", "description", "transactions", "amount"}}
+	for _, p := range report.Payments {
+		rows = append(rows, []interface{}{report.Date, "sales", p.Method, p.TotalTransactions, p.TotalAmount})
+	}
+	for _, m := range report.Movements {
+		rows = append(rows, []interface{}{m.CreatedAt, m.Type, m.CashierName + ": " + m.Reason, nil, m.Amount})
+	}
+	rows = append(rows,
+		[]interface{}{report.Date, "cash_sales", nil, nil, report.CashSales},
+		[]interface{}{report.Date, "pay_ins", nil, nil, report.PayIns},
+		[]interface{}{report.Date, "pay_outs", nil, nil, report.PayOuts},
+		[]interface{}{report.Date, "net_cash", nil, nil, report.NetCash},
+	)
+
+	return rows
+}
+
+// get /api/report/sales-trend?start_date=&end_date=&granularity=hour|day|week|month
+func (h *TransactionHandler) HandleSalesTrend(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	format, err := exportFormat(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	outletID, err := reportOutletID(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	query := r.URL.Query()
+	trend, err := h.service.GetSalesTrend(query.Get("start_date"), query.Get("end_date"), query.Get("granularity"), outletID)
+	if err != nil {
+		switch err {
+		case services.ErrInvalidDate,
+			services.ErrInvalidDateRange,
+			services.ErrInvalidGranularity,
+			services.ErrTooManyBuckets:
+			http.Error(w, err.Error(), http.StatusBadRequest)
+		default:
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+		}
+		return
+	}
+
+	if format != formatJSON {
+		rows := [][]interface{}{{"start", "total_revenue", "total_transaksi", "items_sold", "average_basket"}}
+		for _, b := range trend.Buckets {
+			rows = append(rows, []interface{}{b.Start, b.TotalRevenue, b.TotalTransactions, b.ItemsSold, b.AverageBasket})
+		}
+		writeTable(w, format, "sales-trend", rows)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(trend)
+}
+
+// get /api/report/products?start_date=&end_date=&sort=revenue&order=desc&limit=10
+func (h *TransactionHandler) HandleProductPerformance(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	format, err := exportFormat(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	outletID, err := reportOutletID(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	query := r.URL.Query()
+	limit := 0
+	if value := query.Get("limit"); value != "" {
+		if limit, err = strconv.Atoi(value); err != nil {
+			http.Error(w, "Invalid limit", http.StatusBadRequest)
+			return
+		}
+	}
+
+	report, err := h.service.GetProductPerformance(query.Get("start_date"), query.Get("end_date"),
+		query.Get("sort"), query.Get("order"), limit, outletID)
+	if err != nil {
+		switch err {
+		case services.ErrInvalidDate,
+			services.ErrInvalidDateRange,
+			services.ErrInvalidSort,
+			services.ErrInvalidLimit:
+			http.Error(w, err.Error(), http.StatusBadRequest)
+		default:
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+		}
+		return
+	}
+
+	if format != formatJSON {
+		rows := [][]interface{}{{"rank", "product_id", "product_name", "category_name", "quantity", "revenue", "total_transaksi", "revenue_share"}}
+		for _, p := range report.Products {
+			rows = append(rows, []interface{}{p.Rank, p.ProductID, p.ProductName, p.CategoryName, p.Quantity, p.Revenue, p.Transactions, p.RevenueShare})
+		}
+		writeTable(w, format, "product-performance", rows)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(report)
+}
+
+// get /api/transactions?start_date=&end_date=&receipt_number=&outlet_id=&format=json|csv|xlsx
+// Satu baris per detail transaksi. Baris ditulis langsung ke respons selama
+// dibaca dari database, jadi rentang panjang tidak dimuat ke memori.
+func (h *TransactionHandler) HandleTransactions(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	format, err := exportFormat(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	outletID, err := reportOutletID(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	// respons baru dimulai setelah rentang tanggal lolos validasi, supaya
+	// error validasi masih bisa dikirim sebagai 400
+	var table tableWriter
+	var encoder *json.Encoder
+	started := false
+	begin := func() error {
+		started = true
+		if format == formatJSON {
+			w.Header().Set("Content-Type", "application/json")
+			encoder = json.NewEncoder(w)
+			_, err := io.WriteString(w, "[")
+			return err
+		}
+
+		var err error
+		table, err = newTableWriter(w, format, "transactions")
+		if err != nil {
+			return err
+		}
+		return table.WriteRow("created_at", "transaction_id", "receipt_number", "outlet", "payment_method",
+			"product_id", "product_name", "quantity", "unit", "unit_price", "subtotal")
+	}
+
+	lines := 0
+	query := r.URL.Query()
+	err = h.service.EachTransactionLine(query.Get("start_date"), query.Get("end_date"), query.Get("receipt_number"), outletID, func(line models.TransactionLine) error {
+		if !started {
+			if err := begin(); err != nil {
+				return err
+			}
+		}
+		lines++
+
+		if format == formatJSON {
+			if lines > 1 {
+				if _, err := io.WriteString(w, ","); err != nil {
+					return err
+				}
+			}
+			return encoder.Encode(line)
+		}
+		return table.WriteRow(line.CreatedAt, line.TransactionID, line.ReceiptNumber, line.OutletName, line.PaymentMethod,
+			line.ProductID, line.ProductName, line.Quantity, line.Unit, line.UnitPrice, line.Subtotal)
+	})
+	if err == nil && !started {
+		err = begin()
+	}
+	if err != nil {
+		if started {
+			log.Printf("export transactions: %v", err)
+			return
+		}
+		switch err {
+		case services.ErrInvalidDate, services.ErrInvalidDateRange:
+			http.Error(w, err.Error(), http.StatusBadRequest)
+		default:
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+		}
+		return
+	}
+
+	if format == formatJSON {
+		_, err = io.WriteString(w, "]\n")
+	} else {
+		err = table.Close()
+	}
+	if err != nil {
+		log.Printf("export transactions: %v", err)
+	}
+}
+
+// get /api/transactions/{id}/receipt?format=escpos|text|html&width=58|80,
+// get|post /api/transactions/{id}/invoice.pdf
+func (h *TransactionHandler) HandleTransactionByID(w http.ResponseWriter, r *http.Request) {
+	segments := strings.Split(strings.Trim(strings.TrimPrefix(r.URL.Path, "/api/transactions/"), "/"), "/")
+	id, err := strconv.Atoi(segments[0])
+	if err != nil {
+		http.Error(w, "Invalid transaction ID", http.StatusBadRequest)
+		return
+	}
+	if len(segments) != 2 || (segments[1] != "receipt" && segments[1] != "invoice.pdf") {
+		http.Error(w, "Not found", http.StatusNotFound)
+		return
+	}
+	if r.Method != http.MethodGet && (r.Method != http.MethodPost || segments[1] != "invoice.pdf") {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	transaction, err := h.service.GetByID(id)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+
+	// kasir hanya bisa mencetak ulang struk outletnya sendiri
+	cashier := middlewares.CashierFromContext(r.Context())
+	if cashier != nil && cashier.OutletID != transaction.OutletID {
+		http.Error(w, "Transaction belongs to another outlet", http.StatusForbidden)
+		return
+	}
+
+	if segments[1] == "invoice.pdf" {
+		h.Invoice(w, r, transaction)
+		return
+	}
+
+	width := 0
+	if value := r.URL.Query().Get("width"); value != "" {
+		if width, err = strconv.Atoi(value); err != nil {
+			http.Error(w, services.ErrInvalidPaperWidth.Error(), http.StatusBadRequest)
+			return
+		}
+	}
+
+	receipt, contentType, err := h.receipts.Render(transaction, r.URL.Query().Get("format"), width)
+	if err != nil {
+		switch err {
+		case services.ErrInvalidReceiptFormat, services.ErrInvalidPaperWidth:
+			http.Error(w, err.Error(), http.StatusBadRequest)
+		default:
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+		}
+		return
+	}
+
+	w.Header().Set("Content-Type", contentType)
+	w.Write(receipt)
+}
+
+// Invoice menerbitkan faktur pada POST (POST berikutnya mengembalikan faktur
+// yang sama). GET hanya mencetak ulang faktur yang sudah terbit supaya
+// prefetch atau pratinjau tautan tidak memakai nomor faktur.
+func (h *TransactionHandler) Invoice(w http.ResponseWriter, r *http.Request, transaction *models.Transaction) {
+	var invoice *models.Invoice
+	var created bool
+	var err error
+	if r.Method == http.MethodPost {
+		invoice, created, err = h.invoices.Issue(transaction)
+	} else {
+		invoice, err = h.invoices.Get(transaction)
+	}
+	if err != nil {
+		switch err {
+		case services.ErrInvoiceRequiresCustomer:
+			http.Error(w, err.Error(), http.StatusBadRequest)
+		case services.ErrInvoiceNotIssued:
+			http.Error(w, err.Error(), http.StatusNotFound)
+		default:
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+		}
+		return
+	}
+
+	if created {
+		h.audit.Record(middlewares.ActorFromRequest(r), "invoice.issue", "invoice", invoice.ID, nil, invoice)
+	}
+
+	w.Header().Set("Content-Type", "application/pdf")
+	w.Header().Set("Content-Disposition", fmt.Sprintf(`inline; filename="%s.pdf"`, strings.ReplaceAll(invoice.Number, "/", "-")))
+	w.Write(h.invoices.RenderPDF(transaction, invoice))
+}
@@ -0,0 +1,152 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/anggakrnwn/kasir-api/middlewares"
+	"github.com/anggakrnwn/kasir-api/models"
+	"github.com/anggakrnwn/kasir-api/services"
+)
+
+type TransferHandler struct {
+	service *services.TransferService
+	audit   *services.AuditService
+}
+
+func NewTransferHandler(service *services.TransferService, audit *services.AuditService) *TransferHandler {
+	return &TransferHandler{service: service, audit: audit}
+}
+
+// get /api/stock-transfers & post /api/stock-transfers
+func (h *TransferHandler) HandleTransfers(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodGet:
+		h.GetAll(w, r)
+	case http.MethodPost:
+		h.Send(w, r)
+	default:
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+	}
+}
+
+func (h *TransferHandler) GetAll(w http.ResponseWriter, r *http.Request) {
+	outletID, err := reportOutletID(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	filter := models.StockTransferFilter{Status: r.URL.Query().Get("status"), OutletID: outletID}
+	transfers, err := h.service.GetAll(filter)
+	if err != nil {
+		if err == services.ErrInvalidTransferStatus {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(transfers)
+}
+
+func (h *TransferHandler) Send(w http.ResponseWriter, r *http.Request) {
+	var req models.StockTransferRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	// kasir hanya bisa mengirim dari outletnya sendiri
+	if cashier := middlewares.CashierFromContext(r.Context()); cashier != nil {
+		req.FromOutletID = cashier.OutletID
+	}
+
+	actor := middlewares.ActorFromRequest(r)
+	transfer, err := h.service.Send(req, actor.Name)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	h.audit.Record(actor, "stock_transfer.send", "stock_transfer", transfer.ID, nil, transfer)
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(transfer)
+}
+
+// get /api/stock-transfers/{id} & post /api/stock-transfers/{id}/receive
+func (h *TransferHandler) HandleTransferByID(w http.ResponseWriter, r *http.Request) {
+	segments := strings.Split(strings.Trim(strings.TrimPrefix(r.URL.Path, "/api/stock-transfers/"), "/"), "/")
+	id, err := strconv.Atoi(segments[0])
+	if err != nil {
+		http.Error(w, "Invalid transfer ID", http.StatusBadRequest)
+		return
+	}
+
+	switch {
+	case len(segments) == 1 && r.Method == http.MethodGet:
+		transfer, err := h.service.GetByID(id)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusNotFound)
+			return
+		}
+
+		// kasir hanya bisa melihat mutasi dari atau ke outletnya sendiri
+		cashier := middlewares.CashierFromContext(r.Context())
+		if cashier != nil && cashier.OutletID != transfer.FromOutletID && cashier.OutletID != transfer.ToOutletID {
+			http.Error(w, "Transfer belongs to another outlet", http.StatusForbidden)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(transfer)
+	case len(segments) == 2 && segments[1] == "receive" && r.Method == http.MethodPost:
+		h.Receive(w, r, id)
+	case len(segments) == 1 || (len(segments) == 2 && segments[1] == "receive"):
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+	default:
+		http.NotFound(w, r)
+	}
+}
+
+func (h *TransferHandler) Receive(w http.ResponseWriter, r *http.Request, id int) {
+	var req models.ReceiveTransferRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	before, err := h.service.GetByID(id)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+
+	// kasir hanya bisa menerima kiriman untuk outletnya sendiri
+	if cashier := middlewares.CashierFromContext(r.Context()); cashier != nil && cashier.OutletID != before.ToOutletID {
+		http.Error(w, "Transfer is addressed to another outlet", http.StatusForbidden)
+		return
+	}
+
+	actor := middlewares.ActorFromRequest(r)
+	transfer, err := h.service.Receive(id, req, actor.Name)
+	if err != nil {
+		if err == services.ErrTransferAlreadyReceived {
+			http.Error(w, err.Error(), http.StatusConflict)
+			return
+		}
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	h.audit.Record(actor, "stock_transfer.receive", "stock_transfer", id, before, transfer)
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(transfer)
+}
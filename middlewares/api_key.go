@@ -1,24 +1,144 @@
-package middlewares
-
-import "net/http"
-
-func APIKey(validApiKey string) func(http.HandlerFunc) http.HandlerFunc {
-	return func(next http.HandlerFunc) http.HandlerFunc {
-		return func(w http.ResponseWriter, r *http.Request) {
-			apiKey := r.Header.Get("X-API-Key")
-
-			if apiKey == "" {
-				http.Error(w, "API Key Required", http.StatusUnauthorized)
-				return
-			}
-
-			if apiKey != validApiKey {
-				http.Error(w, "Invalid API Key", http.StatusUnauthorized)
-				return
-			}
-
-			next(w, r)
-		}
-
-	}
-}
+package middlewares
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"strings"
+
+	"github.com/anggakrnwn/kasir-api/models"
+	"github.com/anggakrnwn/kasir-api/services"
+)
+
+type contextKey string
+
+const (
+	cashierContextKey contextKey = "cashier"
+	actorContextKey   contextKey = "actor"
+)
+
+// APIKey menerima API key utama (owner) atau API key milik kasir aktif.
+// Kasir yang terautentikasi disimpan di context request. X-Forwarded-For
+// hanya dipercaya jika koneksi datang dari trustedProxies.
+func APIKey(validApiKey string, cashiers *services.CashierService, trustedProxies []*net.IPNet) func(http.HandlerFunc) http.HandlerFunc {
+	return func(next http.HandlerFunc) http.HandlerFunc {
+		return func(w http.ResponseWriter, r *http.Request) {
+			apiKey := r.Header.Get("X-API-Key")
+
+			if apiKey == "" {
+				http.Error(w, "API Key Required", http.StatusUnauthorized)
+				return
+			}
+
+			ip := clientIP(r, trustedProxies)
+
+			if apiKey == validApiKey {
+				actor := models.Actor{Name: "owner", APIKeyHint: maskAPIKey(apiKey), IP: ip}
+				ctx := context.WithValue(r.Context(), actorContextKey, actor)
+				next(w, r.WithContext(ctx))
+				return
+			}
+
+			cashier, err := cashiers.GetByAPIKey(apiKey)
+			if err != nil {
+				http.Error(w, "Invalid API Key", http.StatusUnauthorized)
+				return
+			}
+
+			actor := models.Actor{Name: cashier.Name, CashierID: &cashier.ID, APIKeyHint: maskAPIKey(apiKey), IP: ip}
+			ctx := context.WithValue(r.Context(), cashierContextKey, cashier)
+			ctx = context.WithValue(ctx, actorContextKey, actor)
+			next(w, r.WithContext(ctx))
+		}
+
+	}
+}
+
+// OwnerOnly menolak request yang memakai API key kasir
+func OwnerOnly(next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if CashierFromContext(r.Context()) != nil {
+			http.Error(w, "Owner API Key Required", http.StatusForbidden)
+			return
+		}
+
+		next(w, r)
+	}
+}
+
+// OwnerOnlyWrites mengizinkan API key kasir hanya untuk GET, perubahan data
+// tetap memerlukan API key owner
+func OwnerOnlyWrites(next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet && CashierFromContext(r.Context()) != nil {
+			http.Error(w, "Owner API Key Required", http.StatusForbidden)
+			return
+		}
+
+		next(w, r)
+	}
+}
+
+// CashierFromContext mengembalikan nil jika request memakai API key owner
+func CashierFromContext(ctx context.Context) *models.Cashier {
+	cashier, _ := ctx.Value(cashierContextKey).(*models.Cashier)
+	return cashier
+}
+
+// ActorFromRequest mengembalikan pemanggil API beserta IP-nya untuk audit log
+func ActorFromRequest(r *http.Request) models.Actor {
+	actor, _ := r.Context().Value(actorContextKey).(models.Actor)
+	if actor.Name == "" {
+		actor.Name = "anonymous"
+	}
+	if actor.IP == "" {
+		actor.IP = clientIP(r, nil)
+	}
+	return actor
+}
+
+// clientIP memakai alamat koneksi. Jika koneksi dari proxy terpercaya,
+// X-Forwarded-For dibaca dari kanan dan IP pertama yang bukan proxy
+// terpercaya dianggap klien, karena entri paling kiri bisa diisi siapa saja.
+func clientIP(r *http.Request, trustedProxies []*net.IPNet) string {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		host = r.RemoteAddr
+	}
+	if !isTrustedProxy(net.ParseIP(host), trustedProxies) {
+		return host
+	}
+
+	hops := strings.Split(r.Header.Get("X-Forwarded-For"), ",")
+	for i := len(hops) - 1; i >= 0; i-- {
+		ip := net.ParseIP(strings.TrimSpace(hops[i]))
+		if ip == nil {
+			break
+		}
+		if !isTrustedProxy(ip, trustedProxies) {
+			return ip.String()
+		}
+	}
+
+	return host
+}
+
+func isTrustedProxy(ip net.IP, trustedProxies []*net.IPNet) bool {
+	if ip == nil {
+		return false
+	}
+	for _, network := range trustedProxies {
+		if network.Contains(ip) {
+			return true
+		}
+	}
+	return false
+}
+
+// hanya 4 karakter terakhir yang disimpan, API key asli tidak pernah masuk log
+func maskAPIKey(apiKey string) string {
+	if len(apiKey) <= 4 {
+		return "****"
+	}
+	return "****" + apiKey[len(apiKey)-4:]
+}
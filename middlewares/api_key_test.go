@@ -0,0 +1,150 @@
+package middlewares
+
+import (
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func mustCIDRs(t *testing.T, cidrs ...string) []*net.IPNet {
+	t.Helper()
+	networks := make([]*net.IPNet, 0, len(cidrs))
+	for _, cidr := range cidrs {
+		_, network, err := net.ParseCIDR(cidr)
+		if err != nil {
+			t.Fatalf("ParseCIDR(%q): %v", cidr, err)
+		}
+		networks = append(networks, network)
+	}
+	return networks
+}
+
+func TestClientIP(t *testing.T) {
+	proxies := mustCIDRs(t, "10.0.0.0/8", "fd00::/8")
+
+	tests := []struct {
+		name       string
+		remoteAddr string
+		xff        string
+		trusted    []*net.IPNet
+		want       string
+	}{
+		{
+			name:       "no trusted proxies ignores header",
+			remoteAddr: "203.0.113.7:51234",
+			xff:        "198.51.100.1",
+			want:       "203.0.113.7",
+		},
+		{
+			name:       "untrusted connection ignores header",
+			remoteAddr: "203.0.113.7:51234",
+			xff:        "198.51.100.1",
+			trusted:    proxies,
+			want:       "203.0.113.7",
+		},
+		{
+			name:       "trusted proxy without header",
+			remoteAddr: "10.0.0.5:443",
+			trusted:    proxies,
+			want:       "10.0.0.5",
+		},
+		{
+			name:       "single hop",
+			remoteAddr: "10.0.0.5:443",
+			xff:        "198.51.100.1",
+			trusted:    proxies,
+			want:       "198.51.100.1",
+		},
+		{
+			name:       "spoofed leftmost entry is skipped",
+			remoteAddr: "10.0.0.5:443",
+			xff:        "1.1.1.1, 198.51.100.1",
+			trusted:    proxies,
+			want:       "198.51.100.1",
+		},
+		{
+			name:       "multiple proxy hops",
+			remoteAddr: "10.0.0.5:443",
+			xff:        "1.1.1.1, 198.51.100.1, 10.1.2.3, 10.0.0.9",
+			trusted:    proxies,
+			want:       "198.51.100.1",
+		},
+		{
+			name:       "all hops trusted falls back to connection",
+			remoteAddr: "10.0.0.5:443",
+			xff:        "10.1.2.3, 10.0.0.9",
+			trusted:    proxies,
+			want:       "10.0.0.5",
+		},
+		{
+			name:       "ipv6 connection and client",
+			remoteAddr: "[fd00::1]:443",
+			xff:        "2001:db8::1, fd00::2",
+			trusted:    proxies,
+			want:       "2001:db8::1",
+		},
+		{
+			name:       "untrusted ipv6 connection",
+			remoteAddr: "[2001:db8::5]:443",
+			xff:        "2001:db8::1",
+			trusted:    proxies,
+			want:       "2001:db8::5",
+		},
+		{
+			name:       "malformed header falls back to connection",
+			remoteAddr: "10.0.0.5:443",
+			xff:        "not-an-ip",
+			trusted:    proxies,
+			want:       "10.0.0.5",
+		},
+		{
+			name:       "malformed entry stops the walk",
+			remoteAddr: "10.0.0.5:443",
+			xff:        "198.51.100.1, garbage, 10.0.0.9",
+			trusted:    proxies,
+			want:       "10.0.0.5",
+		},
+		{
+			name:       "remote addr without port",
+			remoteAddr: "203.0.113.7",
+			want:       "203.0.113.7",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := httptest.NewRequest(http.MethodGet, "/", nil)
+			r.RemoteAddr = tt.remoteAddr
+			if tt.xff != "" {
+				r.Header.Set("X-Forwarded-For", tt.xff)
+			}
+
+			if got := clientIP(r, tt.trusted); got != tt.want {
+				t.Errorf("clientIP() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestIsTrustedProxy(t *testing.T) {
+	proxies := mustCIDRs(t, "10.0.0.0/8", "192.168.1.10/32", "fd00::/8")
+
+	tests := []struct {
+		ip   string
+		want bool
+	}{
+		{"10.20.30.40", true},
+		{"192.168.1.10", true},
+		{"192.168.1.11", false},
+		{"fd00::1", true},
+		{"2001:db8::1", false},
+		{"", false},
+	}
+
+	for _, tt := range tests {
+		if got := isTrustedProxy(net.ParseIP(tt.ip), proxies); got != tt.want {
+			t.Errorf("isTrustedProxy(%q) = %v, want %v", tt.ip, got, tt.want)
+		}
+	}
+}
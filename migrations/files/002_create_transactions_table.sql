@@ -1,10 +1,10 @@
-
-CREATE TABLE IF NOT EXISTS transactions (
-    id SERIAL PRIMARY KEY,
-    total_amount INTEGER NOT NULL CHECK (total_amount >= 0),
-    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
-);
-
-CREATE INDEX idx_transactions_created_at ON transactions(created_at);
-
+
+CREATE TABLE IF NOT EXISTS transactions (
+    id SERIAL PRIMARY KEY,
+    total_amount INTEGER NOT NULL CHECK (total_amount >= 0),
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX idx_transactions_created_at ON transactions(created_at);
+
 COMMENT ON TABLE transactions IS 'Tabel transaksi penjualan';
\ No newline at end of file
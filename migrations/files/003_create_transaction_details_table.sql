@@ -1,17 +1,17 @@
-
-CREATE TABLE IF NOT EXISTS transaction_details (
-    id SERIAL PRIMARY KEY,
-    transaction_id INTEGER NOT NULL,
-    product_id INTEGER NOT NULL,
-    quantity INTEGER NOT NULL CHECK (quantity > 0),
-    subtotal INTEGER NOT NULL CHECK (subtotal >= 0),
-    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
-    
-    FOREIGN KEY (transaction_id) REFERENCES transactions(id) ON DELETE CASCADE,
-    FOREIGN KEY (product_id) REFERENCES products(id) ON DELETE RESTRICT
-);
-
-CREATE INDEX idx_transaction_details_transaction_id ON transaction_details(transaction_id);
-CREATE INDEX idx_transaction_details_product_id ON transaction_details(product_id);
-
+
+CREATE TABLE IF NOT EXISTS transaction_details (
+    id SERIAL PRIMARY KEY,
+    transaction_id INTEGER NOT NULL,
+    product_id INTEGER NOT NULL,
+    quantity INTEGER NOT NULL CHECK (quantity > 0),
+    subtotal INTEGER NOT NULL CHECK (subtotal >= 0),
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    
+    FOREIGN KEY (transaction_id) REFERENCES transactions(id) ON DELETE CASCADE,
+    FOREIGN KEY (product_id) REFERENCES products(id) ON DELETE RESTRICT
+);
+
+CREATE INDEX idx_transaction_details_transaction_id ON transaction_details(transaction_id);
+CREATE INDEX idx_transaction_details_product_id ON transaction_details(product_id);
+
 COMMENT ON TABLE transaction_details IS 'Detail item dari transaksi';
\ No newline at end of file
@@ -0,0 +1,20 @@
+CREATE TABLE IF NOT EXISTS shifts (
+    id SERIAL PRIMARY KEY,
+    cashier_id INTEGER NOT NULL,
+    status VARCHAR(20) NOT NULL DEFAULT 'open' CHECK (status IN ('open', 'closed')),
+    opening_float INTEGER NOT NULL CHECK (opening_float >= 0),
+    expected_cash INTEGER,
+    counted_cash INTEGER CHECK (counted_cash >= 0),
+    difference INTEGER,
+    note TEXT,
+    opened_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    closed_at TIMESTAMP WITH TIME ZONE,
+
+    FOREIGN KEY (cashier_id) REFERENCES cashiers(id) ON DELETE RESTRICT
+);
+
+-- satu kasir hanya boleh punya satu shift terbuka
+CREATE UNIQUE INDEX idx_shifts_open_cashier ON shifts(cashier_id) WHERE status = 'open';
+CREATE INDEX idx_shifts_opened_at ON shifts(opened_at);
+
+COMMENT ON TABLE shifts IS 'Sesi shift kasir dengan modal awal dan hitungan kas akhir';
@@ -0,0 +1,5 @@
+ALTER TABLE transactions ADD COLUMN cashier_id INTEGER REFERENCES cashiers(id) ON DELETE RESTRICT;
+ALTER TABLE transactions ADD COLUMN shift_id INTEGER REFERENCES shifts(id) ON DELETE RESTRICT;
+ALTER TABLE transactions ADD COLUMN payment_method VARCHAR(20) NOT NULL DEFAULT 'cash';
+
+CREATE INDEX idx_transactions_shift_id ON transactions(shift_id);
@@ -0,0 +1,17 @@
+CREATE TABLE IF NOT EXISTS cash_movements (
+    id SERIAL PRIMARY KEY,
+    shift_id INTEGER NOT NULL,
+    cashier_id INTEGER NOT NULL,
+    type VARCHAR(10) NOT NULL CHECK (type IN ('pay_in', 'pay_out')),
+    amount INTEGER NOT NULL CHECK (amount > 0),
+    reason VARCHAR(255) NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+
+    FOREIGN KEY (shift_id) REFERENCES shifts(id) ON DELETE RESTRICT,
+    FOREIGN KEY (cashier_id) REFERENCES cashiers(id) ON DELETE RESTRICT
+);
+
+CREATE INDEX idx_cash_movements_shift_id ON cash_movements(shift_id);
+CREATE INDEX idx_cash_movements_created_at ON cash_movements(created_at);
+
+COMMENT ON TABLE cash_movements IS 'Uang masuk/keluar laci kas di luar penjualan';
@@ -0,0 +1,17 @@
+CREATE TABLE IF NOT EXISTS product_prices (
+    id SERIAL PRIMARY KEY,
+    product_id INTEGER NOT NULL,
+    price INTEGER NOT NULL CHECK (price > 0),
+    effective_from TIMESTAMP WITH TIME ZONE NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+
+    FOREIGN KEY (product_id) REFERENCES products(id) ON DELETE CASCADE
+);
+
+CREATE INDEX idx_product_prices_product_effective ON product_prices(product_id, effective_from DESC);
+
+-- harga yang sudah ada menjadi baris pertama riwayat harga
+INSERT INTO product_prices (product_id, price, effective_from)
+SELECT id, price, COALESCE(created_at, CURRENT_TIMESTAMP) FROM products;
+
+COMMENT ON TABLE product_prices IS 'Riwayat dan jadwal perubahan harga produk';
@@ -0,0 +1,26 @@
+CREATE TABLE IF NOT EXISTS customer_groups (
+    id SERIAL PRIMARY KEY,
+    code VARCHAR(50) NOT NULL UNIQUE,
+    name VARCHAR(255) NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE TABLE IF NOT EXISTS customers (
+    id SERIAL PRIMARY KEY,
+    name VARCHAR(255) NOT NULL,
+    phone VARCHAR(50),
+    customer_group_id INTEGER,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+
+    FOREIGN KEY (customer_group_id) REFERENCES customer_groups(id) ON DELETE SET NULL
+);
+
+CREATE INDEX idx_customers_name ON customers(name);
+
+INSERT INTO customer_groups (code, name) VALUES
+    ('reseller', 'Reseller / Grosir'),
+    ('member', 'Member')
+ON CONFLICT DO NOTHING;
+
+COMMENT ON TABLE customer_groups IS 'Kelompok pelanggan untuk daftar harga khusus';
+COMMENT ON TABLE customers IS 'Tabel master pelanggan';
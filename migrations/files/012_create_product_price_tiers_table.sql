@@ -0,0 +1,16 @@
+CREATE TABLE IF NOT EXISTS product_price_tiers (
+    id SERIAL PRIMARY KEY,
+    product_id INTEGER NOT NULL,
+    customer_group_id INTEGER,
+    min_quantity INTEGER NOT NULL CHECK (min_quantity >= 1),
+    price INTEGER NOT NULL CHECK (price > 0),
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+
+    FOREIGN KEY (product_id) REFERENCES products(id) ON DELETE CASCADE,
+    FOREIGN KEY (customer_group_id) REFERENCES customer_groups(id) ON DELETE CASCADE,
+    UNIQUE NULLS NOT DISTINCT (product_id, customer_group_id, min_quantity)
+);
+
+CREATE INDEX idx_product_price_tiers_product_id ON product_price_tiers(product_id);
+
+COMMENT ON TABLE product_price_tiers IS 'Harga bertingkat per jumlah beli, umum atau per kelompok pelanggan';
@@ -0,0 +1,7 @@
+ALTER TABLE transactions ADD COLUMN customer_id INTEGER REFERENCES customers(id) ON DELETE SET NULL;
+
+ALTER TABLE transaction_details ADD COLUMN unit_price INTEGER;
+ALTER TABLE transaction_details ADD COLUMN price_tier_id INTEGER REFERENCES product_price_tiers(id) ON DELETE SET NULL;
+ALTER TABLE transaction_details ADD COLUMN pricing VARCHAR(20) NOT NULL DEFAULT 'base';
+
+UPDATE transaction_details SET unit_price = subtotal / quantity WHERE unit_price IS NULL;
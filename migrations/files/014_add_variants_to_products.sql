@@ -0,0 +1,8 @@
+ALTER TABLE products ADD COLUMN parent_id INTEGER REFERENCES products(id) ON DELETE RESTRICT;
+ALTER TABLE products ADD COLUMN variant_name VARCHAR(100);
+ALTER TABLE products ADD COLUMN sku VARCHAR(64) UNIQUE;
+ALTER TABLE products ADD COLUMN barcode VARCHAR(64) UNIQUE;
+
+CREATE INDEX idx_products_parent_id ON products(parent_id);
+
+COMMENT ON COLUMN products.parent_id IS 'Produk induk, diisi untuk varian (ukuran, rasa, kemasan)';
@@ -0,0 +1,16 @@
+ALTER TABLE products ADD COLUMN unit VARCHAR(20) NOT NULL DEFAULT 'pcs';
+
+CREATE TABLE IF NOT EXISTS product_units (
+    id SERIAL PRIMARY KEY,
+    product_id INTEGER NOT NULL,
+    name VARCHAR(20) NOT NULL,
+    factor INTEGER NOT NULL CHECK (factor > 1),
+    price INTEGER CHECK (price > 0),
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+
+    FOREIGN KEY (product_id) REFERENCES products(id) ON DELETE CASCADE,
+    UNIQUE (product_id, name)
+);
+
+COMMENT ON TABLE product_units IS 'Satuan tambahan produk beserta konversi ke satuan dasar';
+COMMENT ON COLUMN product_units.factor IS 'Jumlah satuan dasar dalam satu satuan ini, mis. 1 karton = 40 pcs';
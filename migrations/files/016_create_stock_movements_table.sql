@@ -0,0 +1,25 @@
+CREATE TABLE IF NOT EXISTS stock_movements (
+    id BIGSERIAL PRIMARY KEY,
+    product_id INTEGER NOT NULL,
+    quantity INTEGER NOT NULL,
+    unit VARCHAR(20) NOT NULL,
+    unit_quantity INTEGER NOT NULL,
+    factor INTEGER NOT NULL DEFAULT 1,
+    reason VARCHAR(30) NOT NULL,
+    reference_type VARCHAR(30),
+    reference_id INTEGER,
+    note TEXT,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+
+    FOREIGN KEY (product_id) REFERENCES products(id) ON DELETE CASCADE
+);
+
+CREATE INDEX idx_stock_movements_product_created ON stock_movements(product_id, created_at);
+CREATE INDEX idx_stock_movements_reference ON stock_movements(reference_type, reference_id);
+
+-- stok yang sudah ada menjadi saldo awal ledger
+INSERT INTO stock_movements (product_id, quantity, unit, unit_quantity, factor, reason)
+SELECT id, stock, unit, stock, 1, 'opening' FROM products WHERE stock > 0;
+
+COMMENT ON TABLE stock_movements IS 'Ledger pergerakan stok dalam satuan dasar';
+COMMENT ON COLUMN stock_movements.quantity IS 'Positif untuk stok masuk, negatif untuk stok keluar (satuan dasar)';
@@ -0,0 +1,29 @@
+CREATE TABLE IF NOT EXISTS stock_receipts (
+    id SERIAL PRIMARY KEY,
+    supplier VARCHAR(255),
+    note TEXT,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE TABLE IF NOT EXISTS stock_receipt_items (
+    id SERIAL PRIMARY KEY,
+    receipt_id INTEGER NOT NULL,
+    product_id INTEGER NOT NULL,
+    unit VARCHAR(20) NOT NULL,
+    unit_quantity INTEGER NOT NULL CHECK (unit_quantity > 0),
+    factor INTEGER NOT NULL DEFAULT 1,
+    quantity INTEGER NOT NULL CHECK (quantity > 0),
+
+    FOREIGN KEY (receipt_id) REFERENCES stock_receipts(id) ON DELETE CASCADE,
+    FOREIGN KEY (product_id) REFERENCES products(id) ON DELETE RESTRICT
+);
+
+CREATE INDEX idx_stock_receipt_items_receipt_id ON stock_receipt_items(receipt_id);
+
+ALTER TABLE transaction_details ADD COLUMN unit VARCHAR(20) NOT NULL DEFAULT 'pcs';
+ALTER TABLE transaction_details ADD COLUMN unit_quantity INTEGER;
+ALTER TABLE transaction_details ADD COLUMN factor INTEGER NOT NULL DEFAULT 1;
+
+UPDATE transaction_details SET unit_quantity = quantity WHERE unit_quantity IS NULL;
+
+COMMENT ON TABLE stock_receipts IS 'Penerimaan barang dari supplier';
@@ -0,0 +1,13 @@
+ALTER TABLE products ADD COLUMN is_weighed BOOLEAN NOT NULL DEFAULT FALSE;
+ALTER TABLE products ADD COLUMN plu VARCHAR(5) UNIQUE;
+
+ALTER TABLE products ALTER COLUMN stock TYPE NUMERIC(12, 3);
+ALTER TABLE transaction_details ALTER COLUMN quantity TYPE NUMERIC(12, 3);
+ALTER TABLE transaction_details ALTER COLUMN unit_quantity TYPE NUMERIC(12, 3);
+ALTER TABLE stock_movements ALTER COLUMN quantity TYPE NUMERIC(12, 3);
+ALTER TABLE stock_movements ALTER COLUMN unit_quantity TYPE NUMERIC(12, 3);
+ALTER TABLE stock_receipt_items ALTER COLUMN quantity TYPE NUMERIC(12, 3);
+ALTER TABLE stock_receipt_items ALTER COLUMN unit_quantity TYPE NUMERIC(12, 3);
+
+COMMENT ON COLUMN products.is_weighed IS 'Produk timbang, boleh dijual dengan jumlah desimal';
+COMMENT ON COLUMN products.plu IS 'Kode item 5 digit pada barcode timbangan (prefix 20-29)';
@@ -0,0 +1,31 @@
+ALTER TABLE products ADD COLUMN is_bundle BOOLEAN NOT NULL DEFAULT FALSE;
+
+CREATE TABLE IF NOT EXISTS bundle_components (
+    id SERIAL PRIMARY KEY,
+    bundle_id INTEGER NOT NULL,
+    component_id INTEGER NOT NULL,
+    quantity NUMERIC(12, 3) NOT NULL CHECK (quantity > 0),
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+
+    FOREIGN KEY (bundle_id) REFERENCES products(id) ON DELETE CASCADE,
+    FOREIGN KEY (component_id) REFERENCES products(id),
+    UNIQUE (bundle_id, component_id),
+    CHECK (bundle_id <> component_id)
+);
+
+CREATE TABLE IF NOT EXISTS transaction_detail_components (
+    id SERIAL PRIMARY KEY,
+    transaction_detail_id INTEGER NOT NULL,
+    product_id INTEGER NOT NULL,
+    quantity NUMERIC(12, 3) NOT NULL,
+
+    FOREIGN KEY (transaction_detail_id) REFERENCES transaction_details(id) ON DELETE CASCADE,
+    FOREIGN KEY (product_id) REFERENCES products(id)
+);
+
+CREATE INDEX idx_bundle_components_component ON bundle_components(component_id);
+CREATE INDEX idx_transaction_detail_components_detail ON transaction_detail_components(transaction_detail_id);
+
+COMMENT ON TABLE bundle_components IS 'Isi produk paket/bundle, stok dikurangi dari komponen saat paket terjual';
+COMMENT ON COLUMN bundle_components.quantity IS 'Jumlah komponen (satuan dasar) dalam satu paket';
+COMMENT ON TABLE transaction_detail_components IS 'Rincian komponen yang keluar untuk setiap paket yang terjual';
\ No newline at end of file
@@ -0,0 +1,33 @@
+CREATE TABLE IF NOT EXISTS stock_lots (
+    id SERIAL PRIMARY KEY,
+    product_id INTEGER NOT NULL,
+    receipt_item_id INTEGER,
+    lot_number VARCHAR(50),
+    expiry_date DATE,
+    quantity NUMERIC(12, 3) NOT NULL CHECK (quantity > 0),
+    remaining NUMERIC(12, 3) NOT NULL CHECK (remaining >= 0 AND remaining <= quantity),
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+
+    FOREIGN KEY (product_id) REFERENCES products(id) ON DELETE CASCADE,
+    FOREIGN KEY (receipt_item_id) REFERENCES stock_receipt_items(id)
+);
+
+CREATE TABLE IF NOT EXISTS transaction_detail_lots (
+    id SERIAL PRIMARY KEY,
+    transaction_detail_id INTEGER NOT NULL,
+    lot_id INTEGER NOT NULL,
+    product_id INTEGER NOT NULL,
+    quantity NUMERIC(12, 3) NOT NULL,
+
+    FOREIGN KEY (transaction_detail_id) REFERENCES transaction_details(id) ON DELETE CASCADE,
+    FOREIGN KEY (lot_id) REFERENCES stock_lots(id),
+    FOREIGN KEY (product_id) REFERENCES products(id)
+);
+
+CREATE INDEX idx_stock_lots_fefo ON stock_lots(product_id, expiry_date) WHERE remaining > 0;
+CREATE INDEX idx_stock_lots_expiry ON stock_lots(expiry_date) WHERE remaining > 0;
+CREATE INDEX idx_transaction_detail_lots_detail ON transaction_detail_lots(transaction_detail_id);
+
+COMMENT ON TABLE stock_lots IS 'Batch/lot stok dari penerimaan barang, dipakai first-expired-first-out';
+COMMENT ON COLUMN stock_lots.remaining IS 'Sisa lot dalam satuan dasar, total sisa semua lot tidak pernah melebihi products.stock';
+COMMENT ON TABLE transaction_detail_lots IS 'Lot yang terpakai untuk setiap baris transaksi'
\ No newline at end of file
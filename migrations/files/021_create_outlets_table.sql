@@ -0,0 +1,37 @@
+CREATE TABLE IF NOT EXISTS outlets (
+    id SERIAL PRIMARY KEY,
+    name VARCHAR(100) NOT NULL,
+    address TEXT,
+    active BOOLEAN NOT NULL DEFAULT TRUE,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+
+INSERT INTO outlets (id, name) VALUES (1, 'Toko Utama');
+SELECT setval(pg_get_serial_sequence('outlets', 'id'), 1);
+
+CREATE TABLE IF NOT EXISTS outlet_stocks (
+    outlet_id INTEGER NOT NULL,
+    product_id INTEGER NOT NULL,
+    stock NUMERIC(12, 3) NOT NULL DEFAULT 0,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+
+    PRIMARY KEY (outlet_id, product_id),
+    FOREIGN KEY (outlet_id) REFERENCES outlets(id),
+    FOREIGN KEY (product_id) REFERENCES products(id) ON DELETE CASCADE
+);
+
+INSERT INTO outlet_stocks (outlet_id, product_id, stock) SELECT 1, id, stock FROM products;
+
+ALTER TABLE cashiers ADD COLUMN outlet_id INTEGER NOT NULL DEFAULT 1 REFERENCES outlets(id);
+ALTER TABLE shifts ADD COLUMN outlet_id INTEGER NOT NULL DEFAULT 1 REFERENCES outlets(id);
+ALTER TABLE transactions ADD COLUMN outlet_id INTEGER NOT NULL DEFAULT 1 REFERENCES outlets(id);
+ALTER TABLE stock_movements ADD COLUMN outlet_id INTEGER NOT NULL DEFAULT 1 REFERENCES outlets(id);
+ALTER TABLE stock_receipts ADD COLUMN outlet_id INTEGER NOT NULL DEFAULT 1 REFERENCES outlets(id);
+ALTER TABLE stock_lots ADD COLUMN outlet_id INTEGER NOT NULL DEFAULT 1 REFERENCES outlets(id);
+
+CREATE INDEX idx_transactions_outlet_created_at ON transactions(outlet_id, created_at);
+CREATE INDEX idx_stock_movements_outlet_product ON stock_movements(outlet_id, product_id);
+
+COMMENT ON TABLE outlets IS 'Toko/cabang yang memakai database yang sama';
+COMMENT ON TABLE outlet_stocks IS 'Stok per outlet, products.stock adalah total semua outlet';
+COMMENT ON COLUMN cashiers.outlet_id IS 'Outlet tempat kasir bertugas, checkout mengurangi stok outlet ini'
\ No newline at end of file
@@ -0,0 +1,48 @@
+ALTER TABLE outlets ADD COLUMN type VARCHAR(20) NOT NULL DEFAULT 'store' CHECK (type IN ('store', 'warehouse'));
+
+CREATE TABLE IF NOT EXISTS stock_transfers (
+    id SERIAL PRIMARY KEY,
+    from_outlet_id INTEGER NOT NULL,
+    to_outlet_id INTEGER NOT NULL,
+    status VARCHAR(20) NOT NULL DEFAULT 'sent' CHECK (status IN ('sent', 'received')),
+    note TEXT,
+    sent_by VARCHAR(100) NOT NULL,
+    received_by VARCHAR(100),
+    sent_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    received_at TIMESTAMP WITH TIME ZONE,
+
+    FOREIGN KEY (from_outlet_id) REFERENCES outlets(id),
+    FOREIGN KEY (to_outlet_id) REFERENCES outlets(id),
+    CHECK (from_outlet_id <> to_outlet_id)
+);
+
+CREATE TABLE IF NOT EXISTS stock_transfer_items (
+    id SERIAL PRIMARY KEY,
+    transfer_id INTEGER NOT NULL,
+    product_id INTEGER NOT NULL,
+    quantity_sent NUMERIC(12, 3) NOT NULL CHECK (quantity_sent > 0),
+    quantity_received NUMERIC(12, 3) CHECK (quantity_received >= 0),
+    discrepancy NUMERIC(12, 3),
+    note TEXT,
+
+    FOREIGN KEY (transfer_id) REFERENCES stock_transfers(id) ON DELETE CASCADE,
+    FOREIGN KEY (product_id) REFERENCES products(id),
+    UNIQUE (transfer_id, product_id)
+);
+
+CREATE TABLE IF NOT EXISTS stock_transfer_item_lots (
+    id SERIAL PRIMARY KEY,
+    transfer_item_id INTEGER NOT NULL,
+    lot_id INTEGER NOT NULL,
+    quantity NUMERIC(12, 3) NOT NULL,
+
+    FOREIGN KEY (transfer_item_id) REFERENCES stock_transfer_items(id) ON DELETE CASCADE,
+    FOREIGN KEY (lot_id) REFERENCES stock_lots(id)
+);
+
+CREATE INDEX idx_stock_transfers_status ON stock_transfers(status);
+CREATE INDEX idx_stock_transfers_to_outlet ON stock_transfers(to_outlet_id, status);
+
+COMMENT ON COLUMN outlets.type IS 'store = toko yang berjualan, warehouse = gudang';
+COMMENT ON TABLE stock_transfers IS 'Mutasi stok antar outlet: stok keluar saat sent, masuk saat received, di antaranya dalam perjalanan';
+COMMENT ON COLUMN stock_transfer_items.discrepancy IS 'quantity_received - quantity_sent, negatif berarti barang kurang'
\ No newline at end of file
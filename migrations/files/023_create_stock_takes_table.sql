@@ -0,0 +1,47 @@
+CREATE TABLE IF NOT EXISTS stock_takes (
+    id SERIAL PRIMARY KEY,
+    outlet_id INTEGER NOT NULL,
+    status VARCHAR(20) NOT NULL DEFAULT 'open' CHECK (status IN ('open', 'posted', 'cancelled')),
+    note TEXT,
+    started_by VARCHAR(100) NOT NULL,
+    posted_by VARCHAR(100),
+    started_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    posted_at TIMESTAMP WITH TIME ZONE,
+
+    FOREIGN KEY (outlet_id) REFERENCES outlets(id)
+);
+
+CREATE UNIQUE INDEX idx_stock_takes_one_open_per_outlet ON stock_takes(outlet_id) WHERE status = 'open';
+
+CREATE TABLE IF NOT EXISTS stock_take_items (
+    id SERIAL PRIMARY KEY,
+    stock_take_id INTEGER NOT NULL,
+    product_id INTEGER NOT NULL,
+    system_quantity NUMERIC(12, 3) NOT NULL,
+    counted_quantity NUMERIC(12, 3),
+    variance NUMERIC(12, 3),
+    unit_value INTEGER,
+
+    FOREIGN KEY (stock_take_id) REFERENCES stock_takes(id) ON DELETE CASCADE,
+    FOREIGN KEY (product_id) REFERENCES products(id) ON DELETE CASCADE,
+    UNIQUE (stock_take_id, product_id)
+);
+
+CREATE TABLE IF NOT EXISTS stock_take_counts (
+    id SERIAL PRIMARY KEY,
+    stock_take_id INTEGER NOT NULL,
+    product_id INTEGER NOT NULL,
+    device VARCHAR(100) NOT NULL,
+    quantity NUMERIC(12, 3) NOT NULL CHECK (quantity >= 0),
+    counted_by VARCHAR(100) NOT NULL,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+
+    FOREIGN KEY (stock_take_id) REFERENCES stock_takes(id) ON DELETE CASCADE,
+    FOREIGN KEY (product_id) REFERENCES products(id) ON DELETE CASCADE,
+    UNIQUE (stock_take_id, product_id, device)
+);
+
+COMMENT ON TABLE stock_takes IS 'Sesi stock opname per outlet, stok sistem di-snapshot saat sesi dimulai';
+COMMENT ON COLUMN stock_take_items.system_quantity IS 'Stok sistem saat sesi dimulai';
+COMMENT ON COLUMN stock_take_items.unit_value IS 'Nilai per satuan dasar saat diposting, untuk valuasi susut';
+COMMENT ON TABLE stock_take_counts IS 'Hasil hitung per perangkat, jumlah semua perangkat menjadi counted_quantity'
\ No newline at end of file
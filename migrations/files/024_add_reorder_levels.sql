@@ -0,0 +1,51 @@
+ALTER TABLE products ADD COLUMN min_stock NUMERIC(12, 3) NOT NULL DEFAULT 0 CHECK (min_stock >= 0);
+ALTER TABLE products ADD COLUMN reorder_qty NUMERIC(12, 3) NOT NULL DEFAULT 0 CHECK (reorder_qty >= 0);
+
+CREATE TABLE IF NOT EXISTS purchase_orders (
+    id SERIAL PRIMARY KEY,
+    outlet_id INTEGER NOT NULL,
+    supplier VARCHAR(255),
+    status VARCHAR(20) NOT NULL DEFAULT 'draft',
+    note TEXT,
+    created_by VARCHAR(100) NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+
+    FOREIGN KEY (outlet_id) REFERENCES outlets(id)
+);
+
+CREATE TABLE IF NOT EXISTS purchase_order_items (
+    id SERIAL PRIMARY KEY,
+    purchase_order_id INTEGER NOT NULL,
+    product_id INTEGER NOT NULL,
+    quantity NUMERIC(12, 3) NOT NULL CHECK (quantity > 0),
+    stock NUMERIC(12, 3) NOT NULL,
+    min_stock NUMERIC(12, 3) NOT NULL,
+
+    FOREIGN KEY (purchase_order_id) REFERENCES purchase_orders(id) ON DELETE CASCADE,
+    FOREIGN KEY (product_id) REFERENCES products(id) ON DELETE CASCADE,
+    UNIQUE (purchase_order_id, product_id)
+);
+
+CREATE TABLE IF NOT EXISTS stock_alerts (
+    id SERIAL PRIMARY KEY,
+    outlet_id INTEGER NOT NULL,
+    product_id INTEGER NOT NULL,
+    stock NUMERIC(12, 3) NOT NULL,
+    min_stock NUMERIC(12, 3) NOT NULL,
+    transaction_id INTEGER,
+    status VARCHAR(20) NOT NULL DEFAULT 'open' CHECK (status IN ('open', 'ordered', 'resolved')),
+    purchase_order_id INTEGER,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    resolved_at TIMESTAMP WITH TIME ZONE,
+
+    FOREIGN KEY (outlet_id) REFERENCES outlets(id),
+    FOREIGN KEY (product_id) REFERENCES products(id) ON DELETE CASCADE,
+    FOREIGN KEY (transaction_id) REFERENCES transactions(id) ON DELETE SET NULL,
+    FOREIGN KEY (purchase_order_id) REFERENCES purchase_orders(id) ON DELETE SET NULL
+);
+
+CREATE INDEX idx_stock_alerts_outlet_product ON stock_alerts(outlet_id, product_id) WHERE status <> 'resolved';
+
+COMMENT ON COLUMN products.min_stock IS 'Stok minimum per outlet, 0 berarti tanpa peringatan';
+COMMENT ON COLUMN products.reorder_qty IS 'Jumlah pesan ulang dalam satuan dasar, 0 berarti sampai stok minimum';
+COMMENT ON TABLE stock_alerts IS 'Peringatan saat checkout membuat stok outlet turun di bawah min_stock'
\ No newline at end of file
@@ -0,0 +1,17 @@
+CREATE TABLE IF NOT EXISTS categories (
+    id SERIAL PRIMARY KEY,
+    name VARCHAR(100) NOT NULL UNIQUE,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+
+ALTER TABLE products ADD COLUMN category_id INTEGER REFERENCES categories(id) ON DELETE SET NULL;
+ALTER TABLE products ADD COLUMN cost NUMERIC(14, 4) NOT NULL DEFAULT 0 CHECK (cost >= 0);
+
+ALTER TABLE stock_receipt_items ADD COLUMN unit_cost INTEGER CHECK (unit_cost >= 0);
+ALTER TABLE stock_movements ADD COLUMN unit_cost NUMERIC(14, 4);
+
+CREATE INDEX idx_products_category_id ON products(category_id);
+
+COMMENT ON COLUMN products.cost IS 'Harga pokok per satuan dasar, diperbarui dari penerimaan barang terakhir';
+COMMENT ON COLUMN stock_receipt_items.unit_cost IS 'Harga beli per satuan yang diterima (mis. per karton)';
+COMMENT ON COLUMN stock_movements.unit_cost IS 'Harga pokok per satuan dasar untuk stok masuk, NULL berarti dinilai dengan harga pokok berjalan'
\ No newline at end of file
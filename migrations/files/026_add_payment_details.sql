@@ -0,0 +1,6 @@
+ALTER TABLE transactions ADD COLUMN tax_rate NUMERIC(5, 2) NOT NULL DEFAULT 0;
+ALTER TABLE transactions ADD COLUMN tax_amount INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE transactions ADD COLUMN amount_paid INTEGER;
+ALTER TABLE transactions ADD COLUMN change_amount INTEGER NOT NULL DEFAULT 0;
+
+UPDATE transactions SET amount_paid = total_amount WHERE amount_paid IS NULL;
\ No newline at end of file
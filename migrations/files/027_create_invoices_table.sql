@@ -0,0 +1,25 @@
+ALTER TABLE customers ADD COLUMN address TEXT;
+ALTER TABLE customers ADD COLUMN tax_id VARCHAR(30);
+
+CREATE TABLE IF NOT EXISTS invoice_sequences (
+    year INTEGER PRIMARY KEY,
+    last_number INTEGER NOT NULL
+);
+
+CREATE TABLE IF NOT EXISTS invoices (
+    id SERIAL PRIMARY KEY,
+    transaction_id INTEGER NOT NULL UNIQUE,
+    number VARCHAR(50) NOT NULL UNIQUE,
+    customer_id INTEGER NOT NULL,
+    customer_name VARCHAR(255) NOT NULL,
+    customer_address TEXT,
+    customer_tax_id VARCHAR(30),
+    issued_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+
+    FOREIGN KEY (transaction_id) REFERENCES transactions(id),
+    FOREIGN KEY (customer_id) REFERENCES customers(id)
+);
+
+COMMENT ON COLUMN customers.tax_id IS 'NPWP pelanggan untuk faktur';
+COMMENT ON TABLE invoice_sequences IS 'Nomor faktur terakhir per tahun, dinaikkan dalam transaksi supaya tanpa celah';
+COMMENT ON TABLE invoices IS 'Faktur yang sudah diterbitkan, data pelanggan disalin saat terbit';
\ No newline at end of file
@@ -0,0 +1,21 @@
+ALTER TABLE outlets ADD COLUMN code VARCHAR(20);
+UPDATE outlets SET code = 'OUT' || id WHERE code IS NULL;
+ALTER TABLE outlets ALTER COLUMN code SET NOT NULL;
+CREATE UNIQUE INDEX idx_outlets_code ON outlets(code);
+
+CREATE TABLE IF NOT EXISTS receipt_sequences (
+    outlet_id INTEGER NOT NULL,
+    business_date DATE NOT NULL,
+    last_number INTEGER NOT NULL,
+
+    PRIMARY KEY (outlet_id, business_date),
+    FOREIGN KEY (outlet_id) REFERENCES outlets(id)
+);
+
+ALTER TABLE transactions ADD COLUMN receipt_number VARCHAR(60);
+
+-- text_pattern_ops supaya pencarian awalan (LIKE 'INV/OUT1/20261017/%') juga memakai index
+CREATE UNIQUE INDEX idx_transactions_receipt_number ON transactions(receipt_number text_pattern_ops);
+
+COMMENT ON COLUMN outlets.code IS 'Kode singkat outlet untuk nomor struk';
+COMMENT ON TABLE receipt_sequences IS 'Nomor struk terakhir per outlet per hari (zona waktu toko), dinaikkan dalam transaksi checkout supaya tanpa celah'
\ No newline at end of file
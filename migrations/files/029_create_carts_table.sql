@@ -0,0 +1,36 @@
+CREATE TABLE IF NOT EXISTS carts (
+    id SERIAL PRIMARY KEY,
+    outlet_id INTEGER NOT NULL,
+    cashier_id INTEGER NOT NULL,
+    customer_id INTEGER,
+    status VARCHAR(20) NOT NULL DEFAULT 'open' CHECK (status IN ('open', 'parked', 'checked_out', 'cancelled')),
+    label VARCHAR(100),
+    transaction_id INTEGER,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+
+    FOREIGN KEY (outlet_id) REFERENCES outlets(id),
+    FOREIGN KEY (cashier_id) REFERENCES cashiers(id),
+    FOREIGN KEY (customer_id) REFERENCES customers(id),
+    FOREIGN KEY (transaction_id) REFERENCES transactions(id)
+);
+
+CREATE TABLE IF NOT EXISTS cart_items (
+    id SERIAL PRIMARY KEY,
+    cart_id INTEGER NOT NULL,
+    product_id INTEGER NOT NULL,
+    quantity NUMERIC(12, 3) NOT NULL CHECK (quantity >= 0),
+    unit VARCHAR(20) NOT NULL DEFAULT '',
+    embedded_price INTEGER NOT NULL DEFAULT 0,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+
+    FOREIGN KEY (cart_id) REFERENCES carts(id) ON DELETE CASCADE,
+    FOREIGN KEY (product_id) REFERENCES products(id)
+);
+
+CREATE INDEX idx_carts_outlet_status ON carts(outlet_id, status);
+CREATE INDEX idx_cart_items_cart ON cart_items(cart_id);
+
+COMMENT ON TABLE carts IS 'Keranjang di server yang bisa ditahan (parked) lalu dilanjutkan, checkout memakai alur transaksi biasa';
+COMMENT ON COLUMN carts.cashier_id IS 'Kasir yang terakhir memegang keranjang';
+COMMENT ON COLUMN cart_items.embedded_price IS 'Harga dari stiker timbangan, 0 jika jumlah memakai quantity'
\ No newline at end of file
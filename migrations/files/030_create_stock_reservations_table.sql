@@ -0,0 +1,34 @@
+CREATE TABLE IF NOT EXISTS stock_reservations (
+    id SERIAL PRIMARY KEY,
+    outlet_id INTEGER NOT NULL,
+    cart_id INTEGER UNIQUE,
+    reference VARCHAR(100),
+    status VARCHAR(20) NOT NULL DEFAULT 'active' CHECK (status IN ('active', 'released', 'expired', 'consumed')),
+    created_by VARCHAR(100) NOT NULL,
+    transaction_id INTEGER,
+    expires_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    released_at TIMESTAMP WITH TIME ZONE,
+
+    FOREIGN KEY (outlet_id) REFERENCES outlets(id),
+    FOREIGN KEY (cart_id) REFERENCES carts(id) ON DELETE CASCADE,
+    FOREIGN KEY (transaction_id) REFERENCES transactions(id)
+);
+
+CREATE TABLE IF NOT EXISTS stock_reservation_items (
+    id SERIAL PRIMARY KEY,
+    reservation_id INTEGER NOT NULL,
+    product_id INTEGER NOT NULL,
+    quantity NUMERIC(12, 3) NOT NULL CHECK (quantity > 0),
+
+    FOREIGN KEY (reservation_id) REFERENCES stock_reservations(id) ON DELETE CASCADE,
+    FOREIGN KEY (product_id) REFERENCES products(id),
+    UNIQUE (reservation_id, product_id)
+);
+
+-- hanya reservasi aktif yang mengurangi stok tersedia
+CREATE INDEX idx_stock_reservations_active ON stock_reservations(outlet_id, expires_at) WHERE status = 'active';
+CREATE INDEX idx_stock_reservation_items_product ON stock_reservation_items(product_id);
+
+COMMENT ON TABLE stock_reservations IS 'Stok yang ditahan untuk keranjang tertahan atau pesanan online sampai expires_at';
+COMMENT ON COLUMN stock_reservation_items.quantity IS 'Dalam satuan dasar produk yang punya stok, paket sudah diurai ke komponennya'
\ No newline at end of file
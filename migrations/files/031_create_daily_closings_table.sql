@@ -0,0 +1,37 @@
+CREATE TABLE IF NOT EXISTS daily_closings (
+    id SERIAL PRIMARY KEY,
+    outlet_id INTEGER NOT NULL,
+    business_date DATE NOT NULL,
+    transaction_count INTEGER NOT NULL DEFAULT 0,
+    gross_sales INTEGER NOT NULL DEFAULT 0,
+    tax_amount INTEGER NOT NULL DEFAULT 0,
+    net_sales INTEGER NOT NULL DEFAULT 0,
+    cash_sales INTEGER NOT NULL DEFAULT 0,
+    pay_ins INTEGER NOT NULL DEFAULT 0,
+    pay_outs INTEGER NOT NULL DEFAULT 0,
+    expected_cash INTEGER NOT NULL DEFAULT 0,
+    counted_cash INTEGER NOT NULL DEFAULT 0,
+    cash_variance INTEGER NOT NULL DEFAULT 0,
+    shift_count INTEGER NOT NULL DEFAULT 0,
+    note VARCHAR(255),
+    closed_by VARCHAR(100) NOT NULL,
+    closed_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+
+    FOREIGN KEY (outlet_id) REFERENCES outlets(id),
+    UNIQUE (outlet_id, business_date)
+);
+
+CREATE TABLE IF NOT EXISTS daily_closing_payments (
+    id SERIAL PRIMARY KEY,
+    closing_id INTEGER NOT NULL,
+    payment_method VARCHAR(20) NOT NULL,
+    total_amount INTEGER NOT NULL DEFAULT 0,
+    transaction_count INTEGER NOT NULL DEFAULT 0,
+
+    FOREIGN KEY (closing_id) REFERENCES daily_closings(id) ON DELETE CASCADE,
+    UNIQUE (closing_id, payment_method)
+);
+
+COMMENT ON TABLE daily_closings IS 'Laporan Z tutup hari per outlet, angka dibekukan saat penutupan dan tidak pernah dihitung ulang';
+COMMENT ON COLUMN daily_closings.business_date IS 'Tanggal di zona waktu toko, checkout ke tanggal yang sudah ditutup ditolak';
+COMMENT ON COLUMN daily_closings.cash_variance IS 'Jumlah selisih kas semua shift yang dibuka pada tanggal tersebut'
\ No newline at end of file
@@ -0,0 +1,9 @@
+ALTER TABLE transaction_details ADD COLUMN discount_amount INTEGER NOT NULL DEFAULT 0;
+
+ALTER TABLE daily_closings ADD COLUMN discount_total INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE daily_closings ADD COLUMN returns_total INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE daily_closings ADD COLUMN void_total INTEGER NOT NULL DEFAULT 0;
+
+COMMENT ON COLUMN transaction_details.discount_amount IS 'Potongan harga tier atau kelompok pelanggan dibanding harga daftar saat checkout';
+COMMENT ON COLUMN daily_closings.returns_total IS 'Total retur pada tanggal tersebut, selalu 0 selama API belum punya retur';
+COMMENT ON COLUMN daily_closings.void_total IS 'Total transaksi yang dibatalkan pada tanggal tersebut, selalu 0 selama API belum punya void'
\ No newline at end of file
@@ -0,0 +1,45 @@
+package models
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// Actor adalah pemanggil API yang melakukan perubahan
+type Actor struct {
+	Name       string `json:"name"`
+	CashierID  *int   `json:"cashier_id,omitempty"`
+	APIKeyHint string `json:"api_key_hint"`
+	IP         string `json:"ip"`
+}
+
+type AuditEntry struct {
+	ID         int64           `json:"id"`
+	Actor      string          `json:"actor"`
+	CashierID  *int            `json:"cashier_id,omitempty"`
+	APIKeyHint string          `json:"api_key_hint"`
+	Action     string          `json:"action"`
+	EntityType string          `json:"entity_type"`
+	EntityID   *int            `json:"entity_id,omitempty"`
+	Before     json.RawMessage `json:"before,omitempty"`
+	After      json.RawMessage `json:"after,omitempty"`
+	IP         string          `json:"ip"`
+	CreatedAt  time.Time       `json:"created_at"`
+}
+
+type AuditFilter struct {
+	Actor      string
+	CashierID  int
+	Action     string
+	EntityType string
+	EntityID   int
+	StartDate  string
+	EndDate    string
+	Limit      int
+	Offset     int
+
+	// batas start <= created_at < end di zona waktu toko, diisi service dari
+	// StartDate/EndDate
+	Start time.Time
+	End   time.Time
+}
@@ -0,0 +1,55 @@
+package models
+
+import "time"
+
+const (
+	CartStatusOpen       = "open"
+	CartStatusParked     = "parked"
+	CartStatusCheckedOut = "checked_out"
+	CartStatusCancelled  = "cancelled"
+)
+
+// Cart adalah keranjang yang disimpan di server. Keranjang open sedang
+// dilayani kasir, parked ditahan dan bisa dilanjutkan kasir mana pun di
+// outlet yang sama. Harga baru dihitung saat checkout.
+type Cart struct {
+	ID            int        `json:"id"`
+	OutletID      int        `json:"outlet_id"`
+	CashierID     int        `json:"cashier_id"`
+	CashierName   string     `json:"cashier_name,omitempty"`
+	CustomerID    *int       `json:"customer_id,omitempty"`
+	Status        string     `json:"status"`
+	Label         string     `json:"label,omitempty"`
+	TransactionID *int       `json:"transaction_id,omitempty"`
+	CreatedAt     time.Time  `json:"created_at"`
+	UpdatedAt     time.Time  `json:"updated_at"`
+	Items         []CartItem `json:"items,omitempty"`
+}
+
+// CartItem.Quantity dalam Unit seperti CheckoutItem
+type CartItem struct {
+	ID            int     `json:"id"`
+	ProductID     int     `json:"product_id"`
+	ProductName   string  `json:"product_name,omitempty"`
+	Quantity      float64 `json:"quantity"`
+	Unit          string  `json:"unit,omitempty"`
+	EmbeddedPrice int     `json:"embedded_price,omitempty"`
+}
+
+type CartRequest struct {
+	CustomerID *int   `json:"customer_id,omitempty"`
+	Label      string `json:"label"`
+}
+
+// CartCheckoutRequest menyelesaikan keranjang. CustomerID kosong memakai
+// pelanggan keranjang.
+type CartCheckoutRequest struct {
+	PaymentMethod string `json:"payment_method"`
+	AmountPaid    int    `json:"amount_paid,omitempty"`
+	CustomerID    *int   `json:"customer_id,omitempty"`
+}
+
+type CartFilter struct {
+	Status   string
+	OutletID int
+}
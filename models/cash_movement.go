@@ -0,0 +1,37 @@
+package models
+
+import "time"
+
+const (
+	CashMovementPayIn  = "pay_in"
+	CashMovementPayOut = "pay_out"
+)
+
+type CashMovement struct {
+	ID          int       `json:"id"`
+	ShiftID     int       `json:"shift_id"`
+	CashierID   int       `json:"cashier_id"`
+	CashierName string    `json:"cashier_name,omitempty"`
+	Type        string    `json:"type"`
+	Amount      int       `json:"amount"`
+	Reason      string    `json:"reason"`
+	CreatedAt   time.Time `json:"created_at"`
+}
+
+type CashMovementRequest struct {
+	Amount int    `json:"amount"`
+	Reason string `json:"reason"`
+}
+
+// CashFlowReport menggabungkan penjualan harian dengan uang masuk/keluar laci
+type CashFlowReport struct {
+	Date      string         `json:"date"`
+	OutletID  *int           `json:"outlet_id,omitempty"`
+	Sales     *SalesSummary  `json:"sales"`
+	Payments  []PaymentTotal `json:"payments"`
+	CashSales int            `json:"cash_sales"`
+	PayIns    int            `json:"pay_ins"`
+	PayOuts   int            `json:"pay_outs"`
+	NetCash   int            `json:"net_cash"`
+	Movements []CashMovement `json:"movements"`
+}
@@ -0,0 +1,12 @@
+package models
+
+import "time"
+
+type Cashier struct {
+	ID        int       `json:"id"`
+	Name      string    `json:"name"`
+	OutletID  int       `json:"outlet_id"`
+	APIKey    string    `json:"api_key,omitempty"`
+	Active    bool      `json:"active"`
+	CreatedAt time.Time `json:"created_at"`
+}
@@ -0,0 +1,47 @@
+package models
+
+import "time"
+
+// DailyClosing adalah laporan Z tutup hari satu outlet. Semua angka dihitung
+// sekali saat penutupan lalu disimpan, jadi laporan lama tidak berubah
+// walaupun produk, harga atau data lain diubah kemudian. Setelah ditutup,
+// checkout dan pay-in/pay-out ke tanggal tersebut ditolak. API belum punya
+// retur dan void, jadi ReturnsTotal dan VoidTotal selalu 0.
+type DailyClosing struct {
+	ID                int            `json:"id"`
+	OutletID          int            `json:"outlet_id"`
+	OutletName        string         `json:"outlet_name,omitempty"`
+	BusinessDate      string         `json:"business_date"`
+	TotalTransactions int            `json:"total_transaksi"`
+	GrossSales        int            `json:"gross_sales"`
+	TaxAmount         int            `json:"tax_amount"`
+	NetSales          int            `json:"net_sales"` // gross_sales tanpa PPN
+	DiscountTotal     int            `json:"discount_total"`
+	ReturnsTotal      int            `json:"returns_total"`
+	VoidTotal         int            `json:"void_total"`
+	CashSales         int            `json:"cash_sales"`
+	PayIns            int            `json:"pay_ins"`
+	PayOuts           int            `json:"pay_outs"`
+	ExpectedCash      int            `json:"expected_cash"`
+	CountedCash       int            `json:"counted_cash"`
+	CashVariance      int            `json:"cash_variance"`
+	ShiftCount        int            `json:"shift_count"`
+	Note              string         `json:"note,omitempty"`
+	ClosedBy          string         `json:"closed_by"`
+	ClosedAt          time.Time      `json:"closed_at"`
+	Payments          []PaymentTotal `json:"payments"`
+}
+
+// DailyClosingRequest menutup Date (YYYY-MM-DD, zona waktu toko), kosong
+// berarti hari ini
+type DailyClosingRequest struct {
+	OutletID int    `json:"outlet_id"`
+	Date     string `json:"date"`
+	Note     string `json:"note"`
+}
+
+type DailyClosingFilter struct {
+	OutletID  int
+	StartDate string
+	EndDate   string
+}
@@ -0,0 +1,22 @@
+package models
+
+import "time"
+
+type CustomerGroup struct {
+	ID        int       `json:"id"`
+	Code      string    `json:"code"`
+	Name      string    `json:"name"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// TaxID adalah NPWP, dicetak di faktur
+type Customer struct {
+	ID              int       `json:"id"`
+	Name            string    `json:"name"`
+	Phone           string    `json:"phone,omitempty"`
+	Address         string    `json:"address,omitempty"`
+	TaxID           string    `json:"tax_id,omitempty"`
+	CustomerGroupID *int      `json:"customer_group_id,omitempty"`
+	CustomerGroup   string    `json:"customer_group,omitempty"`
+	CreatedAt       time.Time `json:"created_at"`
+}
@@ -0,0 +1,42 @@
+package models
+
+import "time"
+
+// DefaultOutletID adalah outlet bawaan dari migrasi, dipakai jika outlet
+// tidak disebutkan
+const DefaultOutletID = 1
+
+const (
+	OutletTypeStore     = "store"
+	OutletTypeWarehouse = "warehouse"
+)
+
+// Code adalah kode singkat outlet di nomor struk, default OUT{id}
+type Outlet struct {
+	ID        int       `json:"id"`
+	Code      string    `json:"code"`
+	Name      string    `json:"name"`
+	Type      string    `json:"type"`
+	Address   string    `json:"address,omitempty"`
+	Active    bool      `json:"active"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// InTransit adalah jumlah yang sedang dikirim ke outlet ini lewat mutasi stok
+// dan belum diterima, belum termasuk di Stock
+type OutletStock struct {
+	OutletID   int     `json:"outlet_id"`
+	OutletName string  `json:"outlet_name"`
+	Stock      float64 `json:"stock"`
+	Reserved   float64 `json:"reserved,omitempty"`
+	Available  float64 `json:"available"`
+	InTransit  float64 `json:"in_transit,omitempty"`
+}
+
+// OutletSales adalah rincian penjualan per outlet pada laporan gabungan
+type OutletSales struct {
+	OutletID          int    `json:"outlet_id"`
+	OutletName        string `json:"outlet_name"`
+	TotalRevenue      int    `json:"total_revenue"`
+	TotalTransactions int    `json:"total_transaksi"`
+}
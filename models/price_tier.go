@@ -0,0 +1,23 @@
+package models
+
+import "time"
+
+// Sumber harga satuan yang dipakai pada baris transaksi
+const (
+	PricingBase  = "base"
+	PricingTier  = "tier"
+	PricingGroup = "group"
+	PricingUnit  = "unit"
+)
+
+// PriceTier berlaku mulai MinQuantity. Tanpa CustomerGroupID tier berlaku untuk
+// semua pelanggan, dengan CustomerGroupID menjadi daftar harga kelompok tersebut.
+type PriceTier struct {
+	ID              int       `json:"id"`
+	ProductID       int       `json:"product_id"`
+	CustomerGroupID *int      `json:"customer_group_id,omitempty"`
+	CustomerGroup   string    `json:"customer_group,omitempty"`
+	MinQuantity     int       `json:"min_quantity"`
+	Price           int       `json:"price"`
+	CreatedAt       time.Time `json:"created_at"`
+}
@@ -1,8 +1,66 @@
-package models
-
-type Product struct {
-	ID    int    `json:"id"`
-	Name  string `json:"name"`
-	Price int    `json:"price"`
-	Stock int    `json:"stock"`
-}
+package models
+
+import "time"
+
+type Category struct {
+	ID        int       `json:"id"`
+	Name      string    `json:"name"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+type Product struct {
+	ID          int               `json:"id"`
+	Name        string            `json:"name"`
+	Price       int               `json:"price"`
+	Stock       float64           `json:"stock"`
+	Unit        string            `json:"unit"`
+	ParentID    *int              `json:"parent_id,omitempty"`
+	VariantName string            `json:"variant_name,omitempty"`
+	SKU         string            `json:"sku,omitempty"`
+	Barcode     string            `json:"barcode,omitempty"`
+	IsWeighed   bool              `json:"is_weighed"`
+	PLU         string            `json:"plu,omitempty"`
+	IsBundle    bool              `json:"is_bundle"`
+	Variants    []Product         `json:"variants,omitempty"`
+	Units       []ProductUnit     `json:"units,omitempty"`
+	Components  []BundleComponent `json:"components,omitempty"`
+
+	CategoryID   *int   `json:"category_id,omitempty"`
+	CategoryName string `json:"category_name,omitempty"`
+
+	// Cost adalah harga pokok per satuan dasar, diperbarui dari penerimaan
+	// barang terakhir dan dipakai untuk stok awal
+	Cost float64 `json:"cost"`
+
+	// MinStock berlaku untuk stok setiap outlet, 0 berarti tanpa peringatan
+	MinStock   float64 `json:"min_stock"`
+	ReorderQty float64 `json:"reorder_qty"`
+
+	// Stock adalah total semua outlet, rinciannya di OutletStocks. OutletID
+	// menentukan outlet yang stoknya berubah saat create/update.
+	// AvailableStock = Stock - ReservedStock (reservasi aktif).
+	ReservedStock  float64       `json:"reserved_stock"`
+	AvailableStock float64       `json:"available_stock"`
+	OutletID       int           `json:"outlet_id,omitempty"`
+	OutletStocks   []OutletStock `json:"outlet_stocks,omitempty"`
+}
+
+// BundleComponent adalah isi satu paket. Quantity dalam satuan dasar
+// komponen untuk satu paket.
+type BundleComponent struct {
+	ID          int     `json:"id"`
+	BundleID    int     `json:"bundle_id"`
+	ProductID   int     `json:"product_id"`
+	ProductName string  `json:"product_name,omitempty"`
+	Quantity    float64 `json:"quantity"`
+}
+
+// BarcodeItem adalah hasil pemindaian barcode: produk beserta jumlah atau
+// harga yang tercetak di stiker timbangan
+type BarcodeItem struct {
+	Barcode       string   `json:"barcode"`
+	Product       *Product `json:"product"`
+	Quantity      float64  `json:"quantity"`
+	EmbeddedPrice int      `json:"embedded_price,omitempty"`
+	Scale         bool     `json:"scale"`
+}
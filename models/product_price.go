@@ -0,0 +1,23 @@
+package models
+
+import "time"
+
+const (
+	PriceStatusScheduled = "scheduled"
+	PriceStatusActive    = "active"
+	PriceStatusPast      = "past"
+)
+
+type ProductPrice struct {
+	ID            int       `json:"id"`
+	ProductID     int       `json:"product_id"`
+	Price         int       `json:"price"`
+	EffectiveFrom time.Time `json:"effective_from"`
+	CreatedAt     time.Time `json:"created_at"`
+	Status        string    `json:"status"`
+}
+
+type SchedulePriceRequest struct {
+	Price         int       `json:"price"`
+	EffectiveFrom time.Time `json:"effective_from"`
+}
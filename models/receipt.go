@@ -0,0 +1,33 @@
+package models
+
+import "time"
+
+const (
+	ReceiptFormatESCPOS = "escpos"
+	ReceiptFormatText   = "text"
+	ReceiptFormatHTML   = "html"
+)
+
+// StoreProfile adalah identitas toko yang dicetak di struk dan faktur.
+// LegalName adalah nama badan usaha di faktur (default Name), TaxID adalah NPWP.
+type StoreProfile struct {
+	Name      string
+	LegalName string
+	Address   string
+	Phone     string
+	TaxID     string
+}
+
+// Invoice adalah faktur untuk pelanggan bisnis. Number berurutan tanpa celah
+// per tahun; data pelanggan disalin saat faktur terbit supaya cetak ulang
+// tetap sama walaupun data pelanggan berubah.
+type Invoice struct {
+	ID              int       `json:"id"`
+	TransactionID   int       `json:"transaction_id"`
+	Number          string    `json:"number"`
+	CustomerID      int       `json:"customer_id"`
+	CustomerName    string    `json:"customer_name"`
+	CustomerAddress string    `json:"customer_address,omitempty"`
+	CustomerTaxID   string    `json:"customer_tax_id,omitempty"`
+	IssuedAt        time.Time `json:"issued_at"`
+}
@@ -0,0 +1,80 @@
+package models
+
+import "time"
+
+const (
+	StockAlertStatusOpen     = "open"
+	StockAlertStatusOrdered  = "ordered"
+	StockAlertStatusResolved = "resolved"
+
+	PurchaseOrderStatusDraft = "draft"
+)
+
+// StockAlert dibuat saat checkout membuat stok outlet turun di bawah
+// min_stock. Status menjadi ordered saat masuk draft purchase order dan
+// resolved otomatis saat stok kembali mencapai min_stock.
+type StockAlert struct {
+	ID              int        `json:"id"`
+	OutletID        int        `json:"outlet_id"`
+	OutletName      string     `json:"outlet_name"`
+	ProductID       int        `json:"product_id"`
+	ProductName     string     `json:"product_name"`
+	Stock           float64    `json:"stock"`
+	MinStock        float64    `json:"min_stock"`
+	TransactionID   *int       `json:"transaction_id,omitempty"`
+	Status          string     `json:"status"`
+	PurchaseOrderID *int       `json:"purchase_order_id,omitempty"`
+	CreatedAt       time.Time  `json:"created_at"`
+	ResolvedAt      *time.Time `json:"resolved_at,omitempty"`
+}
+
+type StockAlertFilter struct {
+	Status   string
+	OutletID int
+}
+
+// LowStockItem adalah produk yang stoknya di bawah min_stock di satu outlet.
+// SuggestedQty adalah reorder_qty, atau kekurangan sampai min_stock jika
+// reorder_qty belum diisi.
+type LowStockItem struct {
+	OutletID     int     `json:"outlet_id"`
+	OutletName   string  `json:"outlet_name"`
+	ProductID    int     `json:"product_id"`
+	ProductName  string  `json:"product_name"`
+	Unit         string  `json:"unit"`
+	Stock        float64 `json:"stock"`
+	MinStock     float64 `json:"min_stock"`
+	ReorderQty   float64 `json:"reorder_qty"`
+	SuggestedQty float64 `json:"suggested_qty"`
+}
+
+type PurchaseOrder struct {
+	ID         int                 `json:"id"`
+	OutletID   int                 `json:"outlet_id"`
+	OutletName string              `json:"outlet_name"`
+	Supplier   string              `json:"supplier,omitempty"`
+	Status     string              `json:"status"`
+	Note       string              `json:"note,omitempty"`
+	CreatedBy  string              `json:"created_by"`
+	CreatedAt  time.Time           `json:"created_at"`
+	Items      []PurchaseOrderItem `json:"items,omitempty"`
+}
+
+// PurchaseOrderItem menyimpan stok dan min_stock saat draft dibuat
+type PurchaseOrderItem struct {
+	ID          int     `json:"id"`
+	ProductID   int     `json:"product_id"`
+	ProductName string  `json:"product_name"`
+	Unit        string  `json:"unit"`
+	Quantity    float64 `json:"quantity"`
+	Stock       float64 `json:"stock"`
+	MinStock    float64 `json:"min_stock"`
+}
+
+// DraftPurchaseOrderRequest membuat draft dari semua produk yang stoknya
+// rendah di outlet dan belum ada di draft lain
+type DraftPurchaseOrderRequest struct {
+	OutletID int    `json:"outlet_id"`
+	Supplier string `json:"supplier"`
+	Note     string `json:"note"`
+}
@@ -0,0 +1,49 @@
+package models
+
+import "time"
+
+const (
+	ReservationStatusActive   = "active"
+	ReservationStatusReleased = "released"
+	ReservationStatusExpired  = "expired"
+	ReservationStatusConsumed = "consumed"
+)
+
+// StockReservation menahan stok outlet untuk keranjang tertahan (CartID) atau
+// pesanan online (Reference) sampai ExpiresAt. Selama active dan belum lewat
+// ExpiresAt, jumlahnya tidak bisa dijual transaksi lain.
+type StockReservation struct {
+	ID            int                    `json:"id"`
+	OutletID      int                    `json:"outlet_id"`
+	CartID        *int                   `json:"cart_id,omitempty"`
+	Reference     string                 `json:"reference,omitempty"`
+	Status        string                 `json:"status"`
+	CreatedBy     string                 `json:"created_by"`
+	TransactionID *int                   `json:"transaction_id,omitempty"`
+	ExpiresAt     time.Time              `json:"expires_at"`
+	CreatedAt     time.Time              `json:"created_at"`
+	ReleasedAt    *time.Time             `json:"released_at,omitempty"`
+	Items         []StockReservationItem `json:"items,omitempty"`
+}
+
+// Quantity dalam satuan dasar. Paket disimpan sebagai komponennya karena
+// paket tidak punya stok sendiri.
+type StockReservationItem struct {
+	ProductID   int     `json:"product_id"`
+	ProductName string  `json:"product_name,omitempty"`
+	Quantity    float64 `json:"quantity"`
+}
+
+// StockReservationRequest untuk pesanan online. Items memakai format item
+// checkout, TTLMinutes kosong memakai STOCK_RESERVATION_TTL.
+type StockReservationRequest struct {
+	OutletID   int            `json:"outlet_id"`
+	Reference  string         `json:"reference"`
+	TTLMinutes int            `json:"ttl_minutes,omitempty"`
+	Items      []CheckoutItem `json:"items"`
+}
+
+type StockReservationFilter struct {
+	Status   string
+	OutletID int
+}
@@ -0,0 +1,54 @@
+package models
+
+import "time"
+
+const (
+	ShiftStatusOpen   = "open"
+	ShiftStatusClosed = "closed"
+)
+
+type Shift struct {
+	ID           int        `json:"id"`
+	CashierID    int        `json:"cashier_id"`
+	CashierName  string     `json:"cashier_name,omitempty"`
+	OutletID     int        `json:"outlet_id"`
+	Status       string     `json:"status"`
+	OpeningFloat int        `json:"opening_float"`
+	ExpectedCash *int       `json:"expected_cash,omitempty"`
+	CountedCash  *int       `json:"counted_cash,omitempty"`
+	Difference   *int       `json:"difference,omitempty"`
+	Note         string     `json:"note,omitempty"`
+	OpenedAt     time.Time  `json:"opened_at"`
+	ClosedAt     *time.Time `json:"closed_at,omitempty"`
+}
+
+type OpenShiftRequest struct {
+	OpeningFloat int `json:"opening_float"`
+}
+
+type CloseShiftRequest struct {
+	CountedCash int    `json:"counted_cash"`
+	Note        string `json:"note"`
+}
+
+// ShiftReport merangkum penjualan dan rekonsiliasi laci kas dalam satu shift
+type ShiftReport struct {
+	Shift             Shift          `json:"shift"`
+	TotalTransactions int            `json:"total_transaksi"`
+	TotalRevenue      int            `json:"total_revenue"`
+	CashSales         int            `json:"cash_sales"`
+	PayIns            int            `json:"pay_ins"`
+	PayOuts           int            `json:"pay_outs"`
+	Payments          []PaymentTotal `json:"payments"`
+	CashMovements     []CashMovement `json:"cash_movements"`
+	ExpectedCash      int            `json:"expected_cash"`
+	CountedCash       *int           `json:"counted_cash,omitempty"`
+	Difference        *int           `json:"difference,omitempty"`
+	CashStatus        string         `json:"cash_status,omitempty"`
+}
+
+type PaymentTotal struct {
+	Method            string `json:"method"`
+	TotalAmount       int    `json:"total_amount"`
+	TotalTransactions int    `json:"total_transaksi"`
+}
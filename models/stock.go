@@ -0,0 +1,120 @@
+package models
+
+import "time"
+
+// Alasan pergerakan stok di stock ledger
+const (
+	StockReasonOpening     = "opening"
+	StockReasonSale        = "sale"
+	StockReasonReceipt     = "receipt"
+	StockReasonAdjustment  = "adjustment"
+	StockReasonTransferOut = "transfer_out"
+	StockReasonTransferIn  = "transfer_in"
+	StockReasonOpname      = "opname"
+)
+
+// ProductUnit adalah satuan tambahan, mis. pack (6) atau karton (40).
+// Price opsional, tanpa price harga satuan = harga dasar x factor.
+type ProductUnit struct {
+	ID        int       `json:"id"`
+	ProductID int       `json:"product_id"`
+	Name      string    `json:"name"`
+	Factor    int       `json:"factor"`
+	Price     *int      `json:"price,omitempty"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// StockMovement adalah satu baris stock ledger. Quantity dalam satuan dasar,
+// UnitQuantity dan Factor menyimpan konversi dari satuan yang dipakai.
+type StockMovement struct {
+	ID            int64     `json:"id"`
+	ProductID     int       `json:"product_id"`
+	ProductName   string    `json:"product_name,omitempty"`
+	OutletID      int       `json:"outlet_id"`
+	Quantity      float64   `json:"quantity"`
+	Unit          string    `json:"unit"`
+	UnitQuantity  float64   `json:"unit_quantity"`
+	Factor        int       `json:"factor"`
+	Reason        string    `json:"reason"`
+	ReferenceType string    `json:"reference_type,omitempty"`
+	ReferenceID   *int      `json:"reference_id,omitempty"`
+	Note          string    `json:"note,omitempty"`
+	CreatedAt     time.Time `json:"created_at"`
+
+	// harga pokok per satuan dasar untuk stok masuk yang harganya diketahui
+	UnitCost *float64 `json:"unit_cost,omitempty"`
+}
+
+type StockMovementFilter struct {
+	OutletID  int
+	ProductID int
+	Reason    string
+	Limit     int
+	Offset    int
+}
+
+type StockReceipt struct {
+	ID        int                `json:"id"`
+	OutletID  int                `json:"outlet_id"`
+	Supplier  string             `json:"supplier,omitempty"`
+	Note      string             `json:"note,omitempty"`
+	CreatedAt time.Time          `json:"created_at"`
+	Items     []StockReceiptItem `json:"items"`
+}
+
+type StockReceiptItem struct {
+	ID           int     `json:"id"`
+	ReceiptID    int     `json:"receipt_id"`
+	ProductID    int     `json:"product_id"`
+	ProductName  string  `json:"product_name,omitempty"`
+	Unit         string  `json:"unit"`
+	UnitQuantity float64 `json:"unit_quantity"`
+	Factor       int     `json:"factor"`
+	Quantity     float64 `json:"quantity"`
+	UnitCost     *int    `json:"unit_cost,omitempty"`
+	LotID        *int    `json:"lot_id,omitempty"`
+	LotNumber    string  `json:"lot_number,omitempty"`
+	ExpiryDate   *string `json:"expiry_date,omitempty"`
+}
+
+type StockReceiptRequest struct {
+	OutletID int                       `json:"outlet_id"`
+	Supplier string                    `json:"supplier"`
+	Note     string                    `json:"note"`
+	Items    []StockReceiptItemRequest `json:"items"`
+}
+
+// ExpiryDate opsional dengan format YYYY-MM-DD. UnitCost adalah harga beli per
+// satuan yang diterima (mis. per karton), 0 berarti tidak diketahui.
+type StockReceiptItemRequest struct {
+	ProductID  int     `json:"product_id"`
+	Unit       string  `json:"unit"`
+	Quantity   float64 `json:"quantity"`
+	UnitCost   int     `json:"unit_cost"`
+	LotNumber  string  `json:"lot_number"`
+	ExpiryDate string  `json:"expiry_date"`
+}
+
+// StockLot dibuat dari setiap item penerimaan barang. Quantity dan Remaining
+// dalam satuan dasar.
+type StockLot struct {
+	ID           int       `json:"id"`
+	OutletID     int       `json:"outlet_id"`
+	ProductID    int       `json:"product_id"`
+	ProductName  string    `json:"product_name,omitempty"`
+	LotNumber    string    `json:"lot_number,omitempty"`
+	ExpiryDate   *string   `json:"expiry_date,omitempty"`
+	Quantity     float64   `json:"quantity"`
+	Remaining    float64   `json:"remaining"`
+	DaysToExpiry *int      `json:"days_to_expiry,omitempty"`
+	CreatedAt    time.Time `json:"created_at"`
+}
+
+// LotAllocation adalah jumlah yang diambil dari satu lot saat stok keluar
+type LotAllocation struct {
+	LotID      int     `json:"lot_id"`
+	ProductID  int     `json:"product_id"`
+	LotNumber  string  `json:"lot_number,omitempty"`
+	ExpiryDate *string `json:"expiry_date,omitempty"`
+	Quantity   float64 `json:"quantity"`
+}
@@ -0,0 +1,72 @@
+package models
+
+import "time"
+
+const (
+	StockTakeStatusOpen      = "open"
+	StockTakeStatusPosted    = "posted"
+	StockTakeStatusCancelled = "cancelled"
+)
+
+// StockTake adalah satu sesi stock opname di satu outlet
+type StockTake struct {
+	ID         int             `json:"id"`
+	OutletID   int             `json:"outlet_id"`
+	OutletName string          `json:"outlet_name,omitempty"`
+	Status     string          `json:"status"`
+	Note       string          `json:"note,omitempty"`
+	StartedBy  string          `json:"started_by"`
+	PostedBy   string          `json:"posted_by,omitempty"`
+	StartedAt  time.Time       `json:"started_at"`
+	PostedAt   *time.Time      `json:"posted_at,omitempty"`
+	Items      []StockTakeItem `json:"items,omitempty"`
+}
+
+// StockTakeItem membandingkan stok sistem saat snapshot dengan hasil hitung.
+// CountedQuantity kosong berarti produk belum dihitung dan tidak disesuaikan.
+// Variance = counted - system, VarianceValue = Variance x UnitValue.
+type StockTakeItem struct {
+	ProductID       int      `json:"product_id"`
+	ProductName     string   `json:"product_name"`
+	SystemQuantity  float64  `json:"system_quantity"`
+	CountedQuantity *float64 `json:"counted_quantity,omitempty"`
+	Variance        *float64 `json:"variance,omitempty"`
+	UnitValue       int      `json:"unit_value"`
+	VarianceValue   *int     `json:"variance_value,omitempty"`
+}
+
+type StockTakeRequest struct {
+	OutletID int    `json:"outlet_id"`
+	Note     string `json:"note"`
+}
+
+// StockTakeCountRequest dikirim per perangkat. Mengirim ulang produk yang sama
+// dari perangkat yang sama mengganti hitungan sebelumnya.
+type StockTakeCountRequest struct {
+	Device string           `json:"device"`
+	Items  []StockTakeCount `json:"items"`
+}
+
+type StockTakeCount struct {
+	ProductID int     `json:"product_id"`
+	Quantity  float64 `json:"quantity"`
+}
+
+type StockTakeFilter struct {
+	Status   string
+	OutletID int
+}
+
+// ShrinkageReport adalah valuasi selisih stock opname. TotalShrinkage adalah
+// nilai barang yang hilang (positif), TotalSurplus nilai barang lebih.
+type ShrinkageReport struct {
+	StockTakeID    int             `json:"stock_take_id"`
+	OutletID       int             `json:"outlet_id"`
+	Status         string          `json:"status"`
+	CountedItems   int             `json:"counted_items"`
+	UncountedItems int             `json:"uncounted_items"`
+	TotalShrinkage int             `json:"total_shrinkage"`
+	TotalSurplus   int             `json:"total_surplus"`
+	NetValue       int             `json:"net_value"`
+	Items          []StockTakeItem `json:"items"`
+}
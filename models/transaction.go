@@ -1,40 +1,208 @@
-package models
-
-import "time"
-
-type Transaction struct {
-	ID          int                 `json:"id"`
-	TotalAmount int                 `json:"total_amount"`
-	CreatedAt   time.Time           `json:"created_at"`
-	Details     []TransactionDetail `json:"details"`
-}
-
-type TransactionDetail struct {
-	ID            int    `json:"id"`
-	TransactionID int    `json:"transaction_id"`
-	ProductID     int    `json:"product_id"`
-	ProductName   string `json:"product_name,omitempty"`
-	Quantity      int    `json:"quantity"`
-	Subtotal      int    `json:"subtotal"`
-}
-
-type CheckoutItem struct {
-	ProductID int `json:"product_id"`
-	Quantity  int `json:"quantity"`
-}
-
-type CheckoutRequest struct {
-	Items []CheckoutItem `json:"items"`
-}
-
-// New models for sales report
-type SalesSummary struct {
-	TotalRevenue       int                 `json:"total_revenue"`
-	TotalTransactions  int                 `json:"total_transaksi"`
-	BestSellingProduct *BestSellingProduct `json:"produk_terlaris,omitempty"`
-}
-
-type BestSellingProduct struct {
-	Name     string `json:"nama"`
-	Quantity int    `json:"qty_terjual"`
-}
+package models
+
+import "time"
+
+const (
+	PaymentMethodCash     = "cash"
+	PaymentMethodQRIS     = "qris"
+	PaymentMethodDebit    = "debit"
+	PaymentMethodCredit   = "credit"
+	PaymentMethodTransfer = "transfer"
+)
+
+// TaxAmount adalah PPN yang sudah termasuk di TotalAmount menurut TaxRate
+// (persen) saat transaksi dibuat. ChangeAmount = AmountPaid - TotalAmount.
+type Transaction struct {
+	ID            int                 `json:"id"`
+	ReceiptNumber string              `json:"receipt_number,omitempty"`
+	TotalAmount   int                 `json:"total_amount"`
+	TaxRate       float64             `json:"tax_rate"`
+	TaxAmount     int                 `json:"tax_amount"`
+	PaymentMethod string              `json:"payment_method"`
+	AmountPaid    int                 `json:"amount_paid"`
+	ChangeAmount  int                 `json:"change_amount"`
+	CashierID     int                 `json:"cashier_id,omitempty"`
+	CashierName   string              `json:"cashier_name,omitempty"`
+	ShiftID       int                 `json:"shift_id,omitempty"`
+	OutletID      int                 `json:"outlet_id,omitempty"`
+	OutletName    string              `json:"outlet_name,omitempty"`
+	CustomerID    *int                `json:"customer_id,omitempty"`
+	CreatedAt     time.Time           `json:"created_at"`
+	Details       []TransactionDetail `json:"details"`
+
+	// produk yang stoknya turun di bawah min_stock karena transaksi ini
+	LowStockAlerts []StockAlert `json:"low_stock_alerts,omitempty"`
+}
+
+type TransactionDetail struct {
+	ID            int     `json:"id"`
+	TransactionID int     `json:"transaction_id"`
+	ProductID     int     `json:"product_id"`
+	ProductName   string  `json:"product_name,omitempty"`
+	Quantity      float64 `json:"quantity"`
+	Unit          string  `json:"unit"`
+	UnitQuantity  float64 `json:"unit_quantity"`
+	Factor        int     `json:"factor"`
+	UnitPrice     int     `json:"unit_price"`
+	Pricing       string  `json:"pricing"`
+	PriceTierID   *int    `json:"price_tier_id,omitempty"`
+	Subtotal      int     `json:"subtotal"`
+	Discount      int     `json:"discount_amount"` // potongan tier/kelompok dari harga daftar
+
+	// stok yang keluar per komponen jika produk yang dijual adalah paket
+	Components []TransactionDetailComponent `json:"components,omitempty"`
+
+	// lot yang terpakai (FEFO), termasuk lot komponen paket
+	Lots []LotAllocation `json:"lots,omitempty"`
+}
+
+type TransactionDetailComponent struct {
+	ProductID   int     `json:"product_id"`
+	ProductName string  `json:"product_name,omitempty"`
+	Quantity    float64 `json:"quantity"`
+}
+
+// CheckoutItem.Quantity dihitung dalam Unit. Unit kosong berarti satuan dasar produk.
+// Item juga bisa dikirim sebagai Barcode (barcode produk atau stiker timbangan).
+type CheckoutItem struct {
+	ProductID int     `json:"product_id"`
+	Quantity  float64 `json:"quantity"`
+	Unit      string  `json:"unit,omitempty"`
+	Barcode   string  `json:"barcode,omitempty"`
+
+	// harga total dari stiker timbangan yang memuat harga, diisi server
+	EmbeddedPrice int `json:"-"`
+}
+
+// AmountPaid adalah uang yang diterima, kosong berarti pas. Untuk pembayaran
+// non-tunai selalu sama dengan total.
+type CheckoutRequest struct {
+	Items         []CheckoutItem `json:"items"`
+	PaymentMethod string         `json:"payment_method"`
+	AmountPaid    int            `json:"amount_paid,omitempty"`
+	CustomerID    *int           `json:"customer_id,omitempty"`
+
+	// reservasi stok pesanan online yang diselesaikan checkout ini
+	ReservationID int `json:"reservation_id,omitempty"`
+
+	// diisi server dari API key kasir, bukan dari body request
+	CashierID int `json:"-"`
+	ShiftID   int `json:"-"`
+	OutletID  int `json:"-"`
+
+	// keranjang yang diselesaikan, ditandai checked_out dalam transaksi yang sama
+	CartID int `json:"-"`
+
+	// tarif PPN, format nomor struk dan zona waktu toko dari konfigurasi,
+	// diisi server
+	TaxRate             float64        `json:"-"`
+	ReceiptNumberFormat string         `json:"-"`
+	Location            *time.Location `json:"-"`
+}
+
+// TransactionLineFilter untuk daftar detail transaksi. Start dan End kosong
+// berarti tanpa batas tanggal, ReceiptNumber dicari sebagai awalan.
+type TransactionLineFilter struct {
+	Start         time.Time
+	End           time.Time
+	OutletID      int
+	ReceiptNumber string
+}
+
+// TransactionLine adalah satu baris detail transaksi untuk daftar dan ekspor.
+// Quantity dan UnitPrice dalam Unit yang dijual.
+type TransactionLine struct {
+	TransactionID int       `json:"transaction_id"`
+	ReceiptNumber string    `json:"receipt_number,omitempty"`
+	CreatedAt     time.Time `json:"created_at"`
+	OutletID      int       `json:"outlet_id"`
+	OutletName    string    `json:"outlet_name"`
+	PaymentMethod string    `json:"payment_method"`
+	ProductID     int       `json:"product_id"`
+	ProductName   string    `json:"product_name"`
+	Quantity      float64   `json:"quantity"`
+	Unit          string    `json:"unit"`
+	UnitPrice     int       `json:"unit_price"`
+	Subtotal      int       `json:"subtotal"`
+}
+
+// SalesSummary tanpa OutletID adalah laporan gabungan semua outlet beserta
+// rincian per outlet di Outlets
+type SalesSummary struct {
+	OutletID           *int                `json:"outlet_id,omitempty"`
+	TotalRevenue       int                 `json:"total_revenue"`
+	TotalTransactions  int                 `json:"total_transaksi"`
+	BestSellingProduct *BestSellingProduct `json:"produk_terlaris,omitempty"`
+	Outlets            []OutletSales       `json:"outlets,omitempty"`
+}
+
+type BestSellingProduct struct {
+	Name     string  `json:"nama"`
+	Quantity float64 `json:"qty_terjual"`
+}
+
+const (
+	GranularityHour  = "hour"
+	GranularityDay   = "day"
+	GranularityWeek  = "week"
+	GranularityMonth = "month"
+)
+
+// SalesTrend adalah deret waktu penjualan, bucket tanpa penjualan tetap
+// muncul dengan nilai 0
+type SalesTrend struct {
+	StartDate   string             `json:"start_date"`
+	EndDate     string             `json:"end_date"`
+	Granularity string             `json:"granularity"`
+	OutletID    *int               `json:"outlet_id,omitempty"`
+	Buckets     []SalesTrendBucket `json:"buckets"`
+}
+
+// Start adalah awal bucket; bucket minggu dimulai hari Senin. ItemsSold dalam
+// satuan dasar, AverageBasket = TotalRevenue / TotalTransactions.
+type SalesTrendBucket struct {
+	Start             time.Time `json:"start"`
+	TotalRevenue      int       `json:"total_revenue"`
+	TotalTransactions int       `json:"total_transaksi"`
+	ItemsSold         float64   `json:"items_sold"`
+	AverageBasket     int       `json:"average_basket"`
+}
+
+// ProductPerformanceReport merinci penjualan setiap produk yang bisa dijual,
+// termasuk yang tidak terjual sama sekali, beserta rollup per kategori.
+// RevenueShare dalam persen dari TotalRevenue.
+type ProductPerformanceReport struct {
+	StartDate         string                `json:"start_date"`
+	EndDate           string                `json:"end_date"`
+	OutletID          *int                  `json:"outlet_id,omitempty"`
+	Sort              string                `json:"sort"`
+	Order             string                `json:"order"`
+	TotalRevenue      int                   `json:"total_revenue"`
+	TotalTransactions int                   `json:"total_transaksi"`
+	TotalQuantity     float64               `json:"total_quantity"`
+	Products          []ProductPerformance  `json:"products"`
+	Categories        []CategoryPerformance `json:"categories"`
+}
+
+// Rank adalah peringkat menurut Sort dan Order sebelum dibatasi limit
+type ProductPerformance struct {
+	Rank         int     `json:"rank"`
+	ProductID    int     `json:"product_id"`
+	ProductName  string  `json:"product_name"`
+	CategoryID   *int    `json:"category_id,omitempty"`
+	CategoryName string  `json:"category_name,omitempty"`
+	Quantity     float64 `json:"quantity"`
+	Revenue      int     `json:"revenue"`
+	Transactions int     `json:"total_transaksi"`
+	RevenueShare float64 `json:"revenue_share"`
+}
+
+type CategoryPerformance struct {
+	CategoryID   *int    `json:"category_id,omitempty"`
+	CategoryName string  `json:"category_name"`
+	Products     int     `json:"products"`
+	Quantity     float64 `json:"quantity"`
+	Revenue      int     `json:"revenue"`
+	Transactions int     `json:"total_transaksi"`
+	RevenueShare float64 `json:"revenue_share"`
+}
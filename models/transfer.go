@@ -0,0 +1,67 @@
+package models
+
+import "time"
+
+const (
+	TransferStatusSent     = "sent"
+	TransferStatusReceived = "received"
+)
+
+// StockTransfer memindahkan stok antar outlet. Stok keluar dari outlet asal
+// saat dikirim dan baru masuk ke outlet tujuan saat diterima; selama status
+// masih sent barang dianggap dalam perjalanan.
+type StockTransfer struct {
+	ID             int                 `json:"id"`
+	FromOutletID   int                 `json:"from_outlet_id"`
+	FromOutletName string              `json:"from_outlet_name,omitempty"`
+	ToOutletID     int                 `json:"to_outlet_id"`
+	ToOutletName   string              `json:"to_outlet_name,omitempty"`
+	Status         string              `json:"status"`
+	Note           string              `json:"note,omitempty"`
+	SentBy         string              `json:"sent_by"`
+	ReceivedBy     string              `json:"received_by,omitempty"`
+	SentAt         time.Time           `json:"sent_at"`
+	ReceivedAt     *time.Time          `json:"received_at,omitempty"`
+	Items          []StockTransferItem `json:"items,omitempty"`
+}
+
+// Quantity dalam satuan dasar. Discrepancy = diterima - dikirim.
+type StockTransferItem struct {
+	ID               int      `json:"id"`
+	TransferID       int      `json:"transfer_id"`
+	ProductID        int      `json:"product_id"`
+	ProductName      string   `json:"product_name,omitempty"`
+	QuantitySent     float64  `json:"quantity_sent"`
+	QuantityReceived *float64 `json:"quantity_received,omitempty"`
+	Discrepancy      *float64 `json:"discrepancy,omitempty"`
+	Note             string   `json:"note,omitempty"`
+}
+
+type StockTransferRequest struct {
+	FromOutletID int                        `json:"from_outlet_id"`
+	ToOutletID   int                        `json:"to_outlet_id"`
+	Note         string                     `json:"note"`
+	Items        []StockTransferItemRequest `json:"items"`
+}
+
+type StockTransferItemRequest struct {
+	ProductID int     `json:"product_id"`
+	Quantity  float64 `json:"quantity"`
+}
+
+// ReceiveTransferRequest hanya perlu memuat item yang jumlahnya berbeda,
+// item lain dianggap diterima sesuai jumlah kirim
+type ReceiveTransferRequest struct {
+	Items []ReceiveTransferItem `json:"items"`
+}
+
+type ReceiveTransferItem struct {
+	ProductID int     `json:"product_id"`
+	Quantity  float64 `json:"quantity"`
+	Note      string  `json:"note"`
+}
+
+type StockTransferFilter struct {
+	Status   string
+	OutletID int
+}
@@ -0,0 +1,39 @@
+package models
+
+const (
+	CostingAverage = "average"
+	CostingFIFO    = "fifo"
+)
+
+// InventoryValuation adalah nilai persediaan seluruh outlet pada akhir Date.
+// Stok yang sedang dalam mutasi antar outlet tetap dihitung.
+type InventoryValuation struct {
+	Date       string              `json:"date"`
+	Method     string              `json:"method"`
+	TotalValue int                 `json:"total_value"`
+	Categories []CategoryValuation `json:"categories"`
+	Products   []ProductValuation  `json:"products"`
+}
+
+// CategoryValuation menjumlahkan produk per kategori, produk tanpa kategori
+// dikelompokkan dengan CategoryID kosong. Quantity dijumlahkan dalam satuan
+// dasar masing-masing produk.
+type CategoryValuation struct {
+	CategoryID   *int    `json:"category_id,omitempty"`
+	CategoryName string  `json:"category_name"`
+	Products     int     `json:"products"`
+	Quantity     float64 `json:"quantity"`
+	Value        int     `json:"value"`
+}
+
+// UnitCost adalah Value / Quantity
+type ProductValuation struct {
+	ProductID    int     `json:"product_id"`
+	ProductName  string  `json:"product_name"`
+	CategoryID   *int    `json:"category_id,omitempty"`
+	CategoryName string  `json:"category_name,omitempty"`
+	Unit         string  `json:"unit"`
+	Quantity     float64 `json:"quantity"`
+	UnitCost     float64 `json:"unit_cost"`
+	Value        int     `json:"value"`
+}
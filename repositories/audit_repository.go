@@ -0,0 +1,116 @@
+package repositories
+
+import (
+	"database/sql"
+	"fmt"
+	"strings"
+
+	"github.com/anggakrnwn/kasir-api/models"
+)
+
+type AuditRepository struct {
+	db *sql.DB
+}
+
+func NewAuditRepository(db *sql.DB) *AuditRepository {
+	return &AuditRepository{db: db}
+}
+
+func (repo *AuditRepository) Create(entry *models.AuditEntry) error {
+	query := `
+		INSERT INTO audit_log (actor, cashier_id, api_key_hint, action, entity_type, entity_id, before_data, after_data, ip)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+		RETURNING id, created_at
+	`
+
+	return repo.db.QueryRow(query,
+		entry.Actor, entry.CashierID, entry.APIKeyHint, entry.Action, entry.EntityType,
+		entry.EntityID, nullJSON(entry.Before), nullJSON(entry.After), entry.IP,
+	).Scan(&entry.ID, &entry.CreatedAt)
+}
+
+func (repo *AuditRepository) GetAll(filter models.AuditFilter) ([]models.AuditEntry, error) {
+	conditions := []string{}
+	args := []interface{}{}
+
+	addCondition := func(condition string, value interface{}) {
+		args = append(args, value)
+		conditions = append(conditions, fmt.Sprintf(condition, len(args)))
+	}
+
+	if filter.Actor != "" {
+		addCondition("actor ILIKE $%d", "%"+filter.Actor+"%")
+	}
+	if filter.CashierID != 0 {
+		addCondition("cashier_id = $%d", filter.CashierID)
+	}
+	if filter.Action != "" {
+		addCondition("action = $%d", filter.Action)
+	}
+	if filter.EntityType != "" {
+		addCondition("entity_type = $%d", filter.EntityType)
+	}
+	if filter.EntityID != 0 {
+		addCondition("entity_id = $%d", filter.EntityID)
+	}
+	// dibandingkan langsung dengan created_at supaya index created_at terpakai
+	if !filter.Start.IsZero() {
+		addCondition("created_at >= $%d", filter.Start)
+	}
+	if !filter.End.IsZero() {
+		addCondition("created_at < $%d", filter.End)
+	}
+
+	query := `
+		SELECT id, actor, cashier_id, COALESCE(api_key_hint, ''), action, entity_type, entity_id,
+			before_data, after_data, COALESCE(ip, ''), created_at
+		FROM audit_log
+	`
+	if len(conditions) > 0 {
+		query += " WHERE " + strings.Join(conditions, " AND ")
+	}
+
+	args = append(args, filter.Limit, filter.Offset)
+	query += fmt.Sprintf(" ORDER BY created_at DESC, id DESC LIMIT $%d OFFSET $%d", len(args)-1, len(args))
+
+	rows, err := repo.db.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	entries := make([]models.AuditEntry, 0)
+	for rows.Next() {
+		var e models.AuditEntry
+		var cashierID, entityID sql.NullInt64
+		var before, after []byte
+
+		err := rows.Scan(&e.ID, &e.Actor, &cashierID, &e.APIKeyHint, &e.Action, &e.EntityType,
+			&entityID, &before, &after, &e.IP, &e.CreatedAt)
+		if err != nil {
+			return nil, err
+		}
+
+		if cashierID.Valid {
+			v := int(cashierID.Int64)
+			e.CashierID = &v
+		}
+		if entityID.Valid {
+			v := int(entityID.Int64)
+			e.EntityID = &v
+		}
+		e.Before = before
+		e.After = after
+
+		entries = append(entries, e)
+	}
+
+	return entries, rows.Err()
+}
+
+func nullJSON(data []byte) interface{} {
+	if len(data) == 0 {
+		return nil
+	}
+	return string(data)
+}
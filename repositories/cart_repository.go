@@ -0,0 +1,346 @@
+package repositories
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/anggakrnwn/kasir-api/models"
+)
+
+type CartRepository struct {
+	db *sql.DB
+}
+
+func NewCartRepository(db *sql.DB) *CartRepository {
+	return &CartRepository{db: db}
+}
+
+func (repo *CartRepository) Create(req models.CartRequest, outletID, cashierID int) (*models.Cart, error) {
+	if req.CustomerID != nil {
+		var exists bool
+		err := repo.db.QueryRow("SELECT EXISTS (SELECT 1 FROM customers WHERE id = $1)", *req.CustomerID).Scan(&exists)
+		if err != nil {
+			return nil, err
+		}
+		if !exists {
+			return nil, fmt.Errorf("customer id %d not found", *req.CustomerID)
+		}
+	}
+
+	var id int
+	err := repo.db.QueryRow(
+		"INSERT INTO carts (outlet_id, cashier_id, customer_id, label) VALUES ($1, $2, $3, NULLIF($4, '')) RETURNING id",
+		outletID, cashierID, req.CustomerID, req.Label,
+	).Scan(&id)
+	if err != nil {
+		return nil, err
+	}
+
+	return repo.GetByID(id)
+}
+
+// lockCart mengunci keranjang sampai transaksi selesai
+func lockCart(tx *sql.Tx, id int) (status string, outletID int, err error) {
+	err = tx.QueryRow("SELECT status, outlet_id FROM carts WHERE id = $1 FOR UPDATE", id).Scan(&status, &outletID)
+	if err == sql.ErrNoRows {
+		return "", 0, errors.New("keranjang tidak ditemukan")
+	}
+	return status, outletID, err
+}
+
+// lockOpenCart mengunci keranjang dan memastikan statusnya masih open.
+// outletID 0 berarti outlet tidak diperiksa.
+func lockOpenCart(tx *sql.Tx, id, outletID int) (int, error) {
+	status, cartOutletID, err := lockCart(tx, id)
+	if err != nil {
+		return 0, err
+	}
+	if status != models.CartStatusOpen {
+		return 0, fmt.Errorf("%w: cart %d is %s", ErrCartNotCheckoutable, id, status)
+	}
+	if outletID != 0 && cartOutletID != outletID {
+		return 0, fmt.Errorf("%w: cart %d belongs to another outlet", ErrCartNotCheckoutable, id)
+	}
+	return cartOutletID, nil
+}
+
+// AddItem menambah item setelah produk dan satuannya divalidasi. Produk dan
+// satuan yang sudah ada di keranjang digabung, kecuali item stiker timbangan
+// berharga yang selalu menjadi baris sendiri. Tanpa reservasi (reserveFor 0)
+// stok baru diperiksa saat checkout, dengan reservasi item ditolak jika
+// stoknya tidak bisa ditahan.
+func (repo *CartRepository) AddItem(id int, item models.CheckoutItem, reserveFor time.Duration) (*models.Cart, error) {
+	tx, err := repo.db.Begin()
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	if _, err := lockOpenCart(tx, id, 0); err != nil {
+		return nil, err
+	}
+
+	var productName string
+	var isWeighed, hasVariants bool
+	err = tx.QueryRow(`
+		SELECT p.name, p.is_weighed, EXISTS (SELECT 1 FROM products v WHERE v.parent_id = p.id)
+		FROM products p WHERE p.id = $1
+	`, item.ProductID).Scan(&productName, &isWeighed, &hasVariants)
+	if err == sql.ErrNoRows {
+		return nil, fmt.Errorf("product id %d not found", item.ProductID)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if hasVariants {
+		return nil, fmt.Errorf("product '%s' has variants, cart must use a variant id", productName)
+	}
+
+	unit, err := resolveUnit(tx, item.ProductID, item.Unit)
+	if err != nil {
+		return nil, err
+	}
+	quantity := roundQuantity(item.Quantity)
+	if item.EmbeddedPrice == 0 && !isWeighed && !isWholeQuantity(quantity) {
+		return nil, fmt.Errorf("product '%s' is not weighed, quantity must be a whole number", productName)
+	}
+
+	merged := false
+	if item.EmbeddedPrice == 0 {
+		result, err := tx.Exec(`
+			UPDATE cart_items SET quantity = quantity + $1
+			WHERE cart_id = $2 AND product_id = $3 AND unit = $4 AND embedded_price = 0
+		`, quantity, id, item.ProductID, unit.Name)
+		if err != nil {
+			return nil, err
+		}
+		affected, err := result.RowsAffected()
+		if err != nil {
+			return nil, err
+		}
+		merged = affected > 0
+	}
+	if !merged {
+		_, err = tx.Exec(
+			"INSERT INTO cart_items (cart_id, product_id, quantity, unit, embedded_price) VALUES ($1, $2, $3, $4, $5)",
+			id, item.ProductID, quantity, unit.Name, item.EmbeddedPrice,
+		)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if _, err := tx.Exec("UPDATE carts SET updated_at = NOW() WHERE id = $1", id); err != nil {
+		return nil, err
+	}
+
+	if reserveFor > 0 {
+		added, err := reservationQuantities(tx, []models.CheckoutItem{item})
+		if err != nil {
+			return nil, err
+		}
+		if err := syncCartReservation(tx, id, reserveFor, added); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+
+	return repo.GetByID(id)
+}
+
+func (repo *CartRepository) RemoveItem(id, itemID int, reserveFor time.Duration) (*models.Cart, error) {
+	tx, err := repo.db.Begin()
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	if _, err := lockOpenCart(tx, id, 0); err != nil {
+		return nil, err
+	}
+
+	result, err := tx.Exec("DELETE FROM cart_items WHERE id = $1 AND cart_id = $2", itemID, id)
+	if err != nil {
+		return nil, err
+	}
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return nil, err
+	}
+	if affected == 0 {
+		return nil, errors.New("item keranjang tidak ditemukan")
+	}
+
+	if _, err := tx.Exec("UPDATE carts SET updated_at = NOW() WHERE id = $1", id); err != nil {
+		return nil, err
+	}
+
+	if reserveFor > 0 {
+		if err := syncCartReservation(tx, id, reserveFor, nil); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+
+	return repo.GetByID(id)
+}
+
+// UpdateStatus memindahkan keranjang dari salah satu status from ke to.
+// cashierID menjadi pemegang keranjang yang baru, 0 berarti tidak berubah.
+// Reservasi keranjang diperpanjang saat ditahan atau dilanjutkan (sebanyak
+// stok yang masih tersedia) dan dilepas saat dibatalkan.
+func (repo *CartRepository) UpdateStatus(id int, from []string, to string, cashierID int, reserveFor time.Duration) (*models.Cart, error) {
+	tx, err := repo.db.Begin()
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	status, _, err := lockCart(tx, id)
+	if err != nil {
+		return nil, err
+	}
+	allowed := false
+	for _, s := range from {
+		allowed = allowed || s == status
+	}
+	if !allowed {
+		return nil, fmt.Errorf("cart %d is %s", id, status)
+	}
+
+	_, err = tx.Exec(
+		"UPDATE carts SET status = $1, cashier_id = COALESCE(NULLIF($2, 0), cashier_id), updated_at = NOW() WHERE id = $3",
+		to, cashierID, id,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	switch {
+	case to == models.CartStatusCancelled:
+		err = releaseCartReservation(tx, id)
+	case reserveFor > 0:
+		err = syncCartReservation(tx, id, reserveFor, nil)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+
+	return repo.GetByID(id)
+}
+
+const cartColumns = `
+	c.id, c.outlet_id, c.cashier_id, k.name, c.customer_id, c.status, COALESCE(c.label, ''),
+	c.transaction_id, c.created_at, c.updated_at
+`
+
+const cartFrom = `
+	FROM carts c
+	JOIN cashiers k ON k.id = c.cashier_id
+`
+
+func scanCart(row interface{ Scan(...interface{}) error }) (*models.Cart, error) {
+	var c models.Cart
+	var customerID, transactionID sql.NullInt64
+
+	err := row.Scan(&c.ID, &c.OutletID, &c.CashierID, &c.CashierName, &customerID, &c.Status, &c.Label,
+		&transactionID, &c.CreatedAt, &c.UpdatedAt)
+	if err != nil {
+		return nil, err
+	}
+	if customerID.Valid {
+		v := int(customerID.Int64)
+		c.CustomerID = &v
+	}
+	if transactionID.Valid {
+		v := int(transactionID.Int64)
+		c.TransactionID = &v
+	}
+
+	return &c, nil
+}
+
+// GetByID beserta item sesuai urutan ditambahkan
+func (repo *CartRepository) GetByID(id int) (*models.Cart, error) {
+	cart, err := scanCart(repo.db.QueryRow("SELECT"+cartColumns+cartFrom+"WHERE c.id = $1", id))
+	if err == sql.ErrNoRows {
+		return nil, errors.New("keranjang tidak ditemukan")
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := repo.db.Query(`
+		SELECT i.id, i.product_id, p.name, i.quantity, i.unit, i.embedded_price
+		FROM cart_items i
+		JOIN products p ON p.id = i.product_id
+		WHERE i.cart_id = $1
+		ORDER BY i.id
+	`, id)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	cart.Items = make([]models.CartItem, 0)
+	for rows.Next() {
+		var i models.CartItem
+		if err := rows.Scan(&i.ID, &i.ProductID, &i.ProductName, &i.Quantity, &i.Unit, &i.EmbeddedPrice); err != nil {
+			return nil, err
+		}
+		cart.Items = append(cart.Items, i)
+	}
+
+	return cart, rows.Err()
+}
+
+// GetAll tanpa item, yang terakhir diubah lebih dulu
+func (repo *CartRepository) GetAll(filter models.CartFilter) ([]models.Cart, error) {
+	conditions := []string{}
+	args := []interface{}{}
+
+	if filter.Status != "" {
+		args = append(args, filter.Status)
+		conditions = append(conditions, fmt.Sprintf("c.status = $%d", len(args)))
+	}
+	if filter.OutletID != 0 {
+		args = append(args, filter.OutletID)
+		conditions = append(conditions, fmt.Sprintf("c.outlet_id = $%d", len(args)))
+	}
+
+	query := "SELECT" + cartColumns + cartFrom
+	if len(conditions) > 0 {
+		query += " WHERE " + strings.Join(conditions, " AND ")
+	}
+	query += " ORDER BY c.updated_at DESC, c.id DESC"
+
+	rows, err := repo.db.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	carts := make([]models.Cart, 0)
+	for rows.Next() {
+		c, err := scanCart(rows)
+		if err != nil {
+			return nil, err
+		}
+		carts = append(carts, *c)
+	}
+
+	return carts, rows.Err()
+}
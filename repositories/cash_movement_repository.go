@@ -0,0 +1,96 @@
+package repositories
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/anggakrnwn/kasir-api/models"
+)
+
+type CashMovementRepository struct {
+	db *sql.DB
+}
+
+func NewCashMovementRepository(db *sql.DB) *CashMovementRepository {
+	return &CashMovementRepository{db: db}
+}
+
+// Create mencatat pay-in/pay-out selama shift masih terbuka. Baris shift dikunci
+// FOR SHARE seperti checkout supaya tidak bisa ditutup di tengah pencatatan,
+// dan tanggal hari ini (zona waktu loc) dikunci supaya kas laporan Z yang
+// sudah dibekukan tidak berubah.
+func (repo *CashMovementRepository) Create(movement *models.CashMovement, loc *time.Location) error {
+	tx, err := repo.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	var status string
+	var outletID int
+	err = tx.QueryRow("SELECT status, outlet_id FROM shifts WHERE id = $1 AND cashier_id = $2 FOR SHARE", movement.ShiftID, movement.CashierID).Scan(&status, &outletID)
+	if err == sql.ErrNoRows {
+		return fmt.Errorf("shift %d not found for cashier %d", movement.ShiftID, movement.CashierID)
+	}
+	if err != nil {
+		return err
+	}
+	if status != models.ShiftStatusOpen {
+		return ErrShiftClosed
+	}
+
+	date := time.Now().In(loc).Format("2006-01-02")
+	if err := lockBusinessDay(tx, outletID, date); err != nil {
+		return err
+	}
+	if err := requireDayOpen(tx, outletID, date); err != nil {
+		return err
+	}
+
+	err = tx.QueryRow(
+		"INSERT INTO cash_movements (shift_id, cashier_id, type, amount, reason) VALUES ($1, $2, $3, $4, $5) RETURNING id, created_at",
+		movement.ShiftID, movement.CashierID, movement.Type, movement.Amount, movement.Reason,
+	).Scan(&movement.ID, &movement.CreatedAt)
+	if err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+func (repo *CashMovementRepository) GetByShift(shiftID int) ([]models.CashMovement, error) {
+	return repo.list("WHERE m.shift_id = $1", shiftID)
+}
+
+// GetByPeriod untuk start <= created_at < end, outletID 0 berarti semua outlet
+func (repo *CashMovementRepository) GetByPeriod(start, end time.Time, outletID int) ([]models.CashMovement, error) {
+	return repo.list(`WHERE m.created_at >= $1 AND m.created_at < $2
+		AND ($3 = 0 OR m.shift_id IN (SELECT id FROM shifts WHERE outlet_id = $3))`, start, end, outletID)
+}
+
+func (repo *CashMovementRepository) list(where string, args ...interface{}) ([]models.CashMovement, error) {
+	query := `
+		SELECT m.id, m.shift_id, m.cashier_id, c.name, m.type, m.amount, m.reason, m.created_at
+		FROM cash_movements m
+		JOIN cashiers c ON c.id = m.cashier_id
+	` + where + " ORDER BY m.created_at"
+
+	rows, err := repo.db.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	movements := make([]models.CashMovement, 0)
+	for rows.Next() {
+		var m models.CashMovement
+		err := rows.Scan(&m.ID, &m.ShiftID, &m.CashierID, &m.CashierName, &m.Type, &m.Amount, &m.Reason, &m.CreatedAt)
+		if err != nil {
+			return nil, err
+		}
+		movements = append(movements, m)
+	}
+
+	return movements, rows.Err()
+}
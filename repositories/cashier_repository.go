@@ -0,0 +1,74 @@
+package repositories
+
+import (
+	"database/sql"
+	"errors"
+
+	"github.com/anggakrnwn/kasir-api/models"
+)
+
+type CashierRepository struct {
+	db *sql.DB
+}
+
+func NewCashierRepository(db *sql.DB) *CashierRepository {
+	return &CashierRepository{db: db}
+}
+
+func (repo *CashierRepository) GetAll() ([]models.Cashier, error) {
+	rows, err := repo.db.Query("SELECT id, name, outlet_id, active, created_at FROM cashiers ORDER BY id")
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	cashiers := make([]models.Cashier, 0)
+	for rows.Next() {
+		var c models.Cashier
+		if err := rows.Scan(&c.ID, &c.Name, &c.OutletID, &c.Active, &c.CreatedAt); err != nil {
+			return nil, err
+		}
+		cashiers = append(cashiers, c)
+	}
+
+	return cashiers, rows.Err()
+}
+
+func (repo *CashierRepository) Create(cashier *models.Cashier, apiKeyHash string) error {
+	query := "INSERT INTO cashiers (name, api_key_hash, outlet_id, active) VALUES ($1, $2, $3, TRUE) RETURNING id, active, created_at"
+	return repo.db.QueryRow(query, cashier.Name, apiKeyHash, cashier.OutletID).Scan(&cashier.ID, &cashier.Active, &cashier.CreatedAt)
+}
+
+// GetByAPIKeyHash hanya mengembalikan kasir yang masih aktif
+func (repo *CashierRepository) GetByAPIKeyHash(apiKeyHash string) (*models.Cashier, error) {
+	query := "SELECT id, name, outlet_id, active, created_at FROM cashiers WHERE api_key_hash = $1 AND active"
+
+	var c models.Cashier
+	err := repo.db.QueryRow(query, apiKeyHash).Scan(&c.ID, &c.Name, &c.OutletID, &c.Active, &c.CreatedAt)
+	if err == sql.ErrNoRows {
+		return nil, errors.New("kasir tidak ditemukan")
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return &c, nil
+}
+
+func (repo *CashierRepository) SetActive(id int, active bool) error {
+	result, err := repo.db.Exec("UPDATE cashiers SET active = $1 WHERE id = $2", active, id)
+	if err != nil {
+		return err
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if rows == 0 {
+		return errors.New("kasir tidak ditemukan")
+	}
+
+	return nil
+}
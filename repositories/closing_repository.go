@@ -0,0 +1,290 @@
+package repositories
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/anggakrnwn/kasir-api/models"
+)
+
+// Dipakai ulang oleh services supaya handler cukup membandingkan satu error
+var (
+	ErrDayClosed       = errors.New("business day is already closed for this outlet")
+	ErrShiftsStillOpen = errors.New("all shifts of the day must be closed before closing the day, the owner can force-close them via POST /api/shifts/{id}/close")
+)
+
+type ClosingRepository struct {
+	db *sql.DB
+}
+
+func NewClosingRepository(db *sql.DB) *ClosingRepository {
+	return &ClosingRepository{db: db}
+}
+
+const closingColumns = `
+	d.id, d.outlet_id, o.name, to_char(d.business_date, 'YYYY-MM-DD'), d.transaction_count, d.gross_sales,
+	d.tax_amount, d.net_sales, d.discount_total, d.returns_total, d.void_total,
+	d.cash_sales, d.pay_ins, d.pay_outs, d.expected_cash, d.counted_cash, d.cash_variance, d.shift_count,
+	COALESCE(d.note, ''), d.closed_by, d.closed_at
+`
+
+const closingFrom = `
+	FROM daily_closings d
+	JOIN outlets o ON o.id = d.outlet_id
+`
+
+func scanClosing(row interface{ Scan(...interface{}) error }) (*models.DailyClosing, error) {
+	var d models.DailyClosing
+	err := row.Scan(&d.ID, &d.OutletID, &d.OutletName, &d.BusinessDate, &d.TotalTransactions, &d.GrossSales,
+		&d.TaxAmount, &d.NetSales, &d.DiscountTotal, &d.ReturnsTotal, &d.VoidTotal,
+		&d.CashSales, &d.PayIns, &d.PayOuts, &d.ExpectedCash, &d.CountedCash, &d.CashVariance, &d.ShiftCount,
+		&d.Note, &d.ClosedBy, &d.ClosedAt)
+	if err != nil {
+		return nil, err
+	}
+
+	return &d, nil
+}
+
+// lockBusinessDay mengunci baris receipt_sequences outlet pada date. Checkout
+// mengunci baris yang sama saat mengambil nomor struk, jadi tutup hari dan
+// checkout ke tanggal yang sama selalu berjalan bergantian.
+func lockBusinessDay(q queryer, outletID int, date string) error {
+	var lastNumber int
+	return q.QueryRow(`
+		INSERT INTO receipt_sequences (outlet_id, business_date, last_number) VALUES ($1, $2, 0)
+		ON CONFLICT (outlet_id, business_date) DO UPDATE SET last_number = receipt_sequences.last_number
+		RETURNING last_number
+	`, outletID, date).Scan(&lastNumber)
+}
+
+// requireDayOpen menolak perubahan transaksi pada tanggal yang sudah ditutup.
+// Pemanggil harus sudah memegang lockBusinessDay untuk tanggal yang sama.
+// Retur atau void yang ditambahkan nanti wajib memanggil ini untuk tanggal
+// transaksi aslinya.
+func requireDayOpen(q queryer, outletID int, date string) error {
+	var closed bool
+	err := q.QueryRow(
+		"SELECT EXISTS (SELECT 1 FROM daily_closings WHERE outlet_id = $1 AND business_date = $2)",
+		outletID, date,
+	).Scan(&closed)
+	if err != nil {
+		return err
+	}
+	if closed {
+		return ErrDayClosed
+	}
+
+	return nil
+}
+
+// Close membuat laporan Z outlet untuk date (start <= created_at < end di
+// zona waktu toko). Penjualan dan metode pembayaran diambil dari transaksi
+// pada rentang itu, kas laci dari shift yang dibuka pada tanggal itu, lalu
+// semuanya disimpan apa adanya.
+func (repo *ClosingRepository) Close(req models.DailyClosingRequest, start, end time.Time, closedBy string) (*models.DailyClosing, error) {
+	tx, err := repo.db.Begin()
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	if err := lockBusinessDay(tx, req.OutletID, req.Date); err != nil {
+		return nil, err
+	}
+	if err := requireDayOpen(tx, req.OutletID, req.Date); err != nil {
+		return nil, err
+	}
+
+	// shift yang dibuka setelah end tidak masuk laporan ini, jadi tidak menahan
+	// penutupan
+	var openShifts int
+	err = tx.QueryRow(
+		"SELECT COUNT(id) FROM shifts WHERE outlet_id = $1 AND status = 'open' AND opened_at < $2",
+		req.OutletID, end,
+	).Scan(&openShifts)
+	if err != nil {
+		return nil, err
+	}
+	if openShifts > 0 {
+		return nil, ErrShiftsStillOpen
+	}
+
+	closing := models.DailyClosing{
+		OutletID:     req.OutletID,
+		BusinessDate: req.Date,
+		Note:         req.Note,
+		ClosedBy:     closedBy,
+		Payments:     make([]models.PaymentTotal, 0),
+	}
+
+	rows, err := tx.Query(`
+		SELECT payment_method, COALESCE(SUM(total_amount), 0), COUNT(id), COALESCE(SUM(tax_amount), 0)
+		FROM transactions
+		WHERE outlet_id = $1 AND created_at >= $2 AND created_at < $3
+		GROUP BY payment_method
+		ORDER BY payment_method
+	`, req.OutletID, start, end)
+	if err != nil {
+		return nil, err
+	}
+	for rows.Next() {
+		var p models.PaymentTotal
+		var tax int
+		if err := rows.Scan(&p.Method, &p.TotalAmount, &p.TotalTransactions, &tax); err != nil {
+			rows.Close()
+			return nil, err
+		}
+		closing.Payments = append(closing.Payments, p)
+		closing.GrossSales += p.TotalAmount
+		closing.TotalTransactions += p.TotalTransactions
+		closing.TaxAmount += tax
+		if p.Method == models.PaymentMethodCash {
+			closing.CashSales = p.TotalAmount
+		}
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	closing.NetSales = closing.GrossSales - closing.TaxAmount
+
+	err = tx.QueryRow(`
+		SELECT COALESCE(SUM(td.discount_amount), 0)
+		FROM transaction_details td
+		JOIN transactions t ON t.id = td.transaction_id
+		WHERE t.outlet_id = $1 AND t.created_at >= $2 AND t.created_at < $3
+	`, req.OutletID, start, end).Scan(&closing.DiscountTotal)
+	if err != nil {
+		return nil, err
+	}
+
+	err = tx.QueryRow(`
+		SELECT
+			COALESCE(SUM(m.amount) FILTER (WHERE m.type = 'pay_in'), 0),
+			COALESCE(SUM(m.amount) FILTER (WHERE m.type = 'pay_out'), 0)
+		FROM cash_movements m
+		JOIN shifts s ON s.id = m.shift_id
+		WHERE s.outlet_id = $1 AND m.created_at >= $2 AND m.created_at < $3
+	`, req.OutletID, start, end).Scan(&closing.PayIns, &closing.PayOuts)
+	if err != nil {
+		return nil, err
+	}
+
+	// shift yang melewati tengah malam dihitung di tanggal shift dibuka
+	err = tx.QueryRow(`
+		SELECT COUNT(id), COALESCE(SUM(expected_cash), 0), COALESCE(SUM(counted_cash), 0), COALESCE(SUM(difference), 0)
+		FROM shifts
+		WHERE outlet_id = $1 AND status = 'closed' AND opened_at >= $2 AND opened_at < $3
+	`, req.OutletID, start, end).Scan(&closing.ShiftCount, &closing.ExpectedCash, &closing.CountedCash, &closing.CashVariance)
+	if err != nil {
+		return nil, err
+	}
+
+	err = tx.QueryRow(`
+		INSERT INTO daily_closings (outlet_id, business_date, transaction_count, gross_sales, tax_amount,
+			net_sales, discount_total, returns_total, void_total, cash_sales, pay_ins, pay_outs, expected_cash,
+			counted_cash, cash_variance, shift_count, note, closed_by)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, NULLIF($17, ''), $18)
+		RETURNING id
+	`, closing.OutletID, closing.BusinessDate, closing.TotalTransactions, closing.GrossSales, closing.TaxAmount,
+		closing.NetSales, closing.DiscountTotal, closing.ReturnsTotal, closing.VoidTotal, closing.CashSales, closing.PayIns, closing.PayOuts, closing.ExpectedCash,
+		closing.CountedCash, closing.CashVariance, closing.ShiftCount, closing.Note, closing.ClosedBy,
+	).Scan(&closing.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, p := range closing.Payments {
+		_, err := tx.Exec(
+			"INSERT INTO daily_closing_payments (closing_id, payment_method, total_amount, transaction_count) VALUES ($1, $2, $3, $4)",
+			closing.ID, p.Method, p.TotalAmount, p.TotalTransactions,
+		)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+
+	return repo.GetByID(closing.ID)
+}
+
+func (repo *ClosingRepository) GetByID(id int) (*models.DailyClosing, error) {
+	closing, err := scanClosing(repo.db.QueryRow("SELECT"+closingColumns+closingFrom+"WHERE d.id = $1", id))
+	if err == sql.ErrNoRows {
+		return nil, errors.New("tutup hari tidak ditemukan")
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := repo.db.Query(`
+		SELECT payment_method, total_amount, transaction_count
+		FROM daily_closing_payments
+		WHERE closing_id = $1
+		ORDER BY payment_method
+	`, id)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	closing.Payments = make([]models.PaymentTotal, 0)
+	for rows.Next() {
+		var p models.PaymentTotal
+		if err := rows.Scan(&p.Method, &p.TotalAmount, &p.TotalTransactions); err != nil {
+			return nil, err
+		}
+		closing.Payments = append(closing.Payments, p)
+	}
+
+	return closing, rows.Err()
+}
+
+// GetAll tanpa rincian pembayaran, diurutkan dari tanggal terbaru
+func (repo *ClosingRepository) GetAll(filter models.DailyClosingFilter) ([]models.DailyClosing, error) {
+	conditions := []string{}
+	args := []interface{}{}
+
+	if filter.OutletID != 0 {
+		args = append(args, filter.OutletID)
+		conditions = append(conditions, fmt.Sprintf("d.outlet_id = $%d", len(args)))
+	}
+	if filter.StartDate != "" {
+		args = append(args, filter.StartDate)
+		conditions = append(conditions, fmt.Sprintf("d.business_date >= $%d", len(args)))
+	}
+	if filter.EndDate != "" {
+		args = append(args, filter.EndDate)
+		conditions = append(conditions, fmt.Sprintf("d.business_date <= $%d", len(args)))
+	}
+
+	query := "SELECT" + closingColumns + closingFrom
+	if len(conditions) > 0 {
+		query += " WHERE " + strings.Join(conditions, " AND ")
+	}
+	query += " ORDER BY d.business_date DESC, d.outlet_id"
+
+	rows, err := repo.db.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	closings := make([]models.DailyClosing, 0)
+	for rows.Next() {
+		d, err := scanClosing(rows)
+		if err != nil {
+			return nil, err
+		}
+		closings = append(closings, *d)
+	}
+
+	return closings, rows.Err()
+}
@@ -0,0 +1,152 @@
+package repositories
+
+import (
+	"database/sql"
+
+	"github.com/anggakrnwn/kasir-api/models"
+)
+
+// costLedger menghitung harga pokok persediaan satu produk dengan memutar
+// ulang stock ledger. Quantity dalam satuan dasar, cost per satuan dasar.
+type costLedger interface {
+	receive(quantity, cost float64)
+	// issue mengembalikan nilai stok yang keluar
+	issue(quantity float64) float64
+	value() float64
+	// currentCost dipakai untuk stok masuk yang harga pokoknya tidak diketahui
+	// (adjustment, opname)
+	currentCost() float64
+}
+
+func newCostLedger(method string) costLedger {
+	if method == models.CostingFIFO {
+		return &fifoCost{}
+	}
+	return &averageCost{}
+}
+
+// averageCost adalah weighted average: setiap stok masuk merata-ratakan ulang
+// harga pokok, stok keluar tidak mengubahnya
+type averageCost struct {
+	quantity float64
+	average  float64
+}
+
+func (c *averageCost) receive(quantity, cost float64) {
+	// stok minus (terjual sebelum penerimaan tercatat) tidak ikut dirata-rata
+	if c.quantity <= 0 {
+		c.quantity += quantity
+		c.average = cost
+		return
+	}
+
+	c.average = (c.quantity*c.average + quantity*cost) / (c.quantity + quantity)
+	c.quantity += quantity
+}
+
+func (c *averageCost) issue(quantity float64) float64 {
+	c.quantity -= quantity
+	return quantity * c.average
+}
+
+func (c *averageCost) value() float64 {
+	return max(c.quantity, 0) * c.average
+}
+
+func (c *averageCost) currentCost() float64 {
+	return c.average
+}
+
+// costReplay memutar ulang mutasi stok satu produk ke costLedger. Stok yang
+// diterima dari mutasi antar outlet tanpa unit_cost dinilai dengan harga pokok
+// saat dikirim, stok masuk lain tanpa harga pokok dengan harga pokok berjalan
+// atau productCost jika belum ada.
+type costReplay struct {
+	ledger      costLedger
+	productCost float64
+	// harga pokok per satuan saat dikirim, per id mutasi antar outlet
+	transferCosts map[int64]float64
+}
+
+func newCostReplay(method string, productCost float64) *costReplay {
+	return &costReplay{
+		ledger:        newCostLedger(method),
+		productCost:   productCost,
+		transferCosts: make(map[int64]float64),
+	}
+}
+
+func (r *costReplay) apply(quantity float64, unitCost sql.NullFloat64, reason string, referenceID sql.NullInt64) {
+	if quantity < 0 {
+		issued := r.ledger.issue(-quantity)
+		if reason == models.StockReasonTransferOut && referenceID.Valid {
+			r.transferCosts[referenceID.Int64] = issued / -quantity
+		}
+		return
+	}
+
+	cost := r.ledger.currentCost()
+	transferCost, transferred := r.transferCosts[referenceID.Int64]
+	switch {
+	case unitCost.Valid:
+		cost = unitCost.Float64
+	case reason == models.StockReasonTransferIn && referenceID.Valid && transferred:
+		cost = transferCost
+	case cost == 0:
+		cost = r.productCost
+	}
+	r.ledger.receive(quantity, cost)
+}
+
+type costLayer struct {
+	quantity float64
+	cost     float64
+}
+
+// fifoCost menyimpan lapisan harga per stok masuk, stok keluar mengambil
+// lapisan tertua lebih dulu. Stok keluar melebihi lapisan yang ada dicatat
+// sebagai deficit dan ditutup oleh stok masuk berikutnya.
+type fifoCost struct {
+	layers  []costLayer
+	deficit float64
+	last    float64
+}
+
+func (c *fifoCost) receive(quantity, cost float64) {
+	c.last = cost
+
+	covered := min(c.deficit, quantity)
+	c.deficit = roundQuantity(c.deficit - covered)
+	quantity = roundQuantity(quantity - covered)
+	if quantity > 0 {
+		c.layers = append(c.layers, costLayer{quantity: quantity, cost: cost})
+	}
+}
+
+// stok keluar yang menjadi deficit dinilai dengan harga pokok terakhir
+func (c *fifoCost) issue(quantity float64) float64 {
+	issued := 0.0
+	for quantity > 0 && len(c.layers) > 0 {
+		take := min(c.layers[0].quantity, quantity)
+		issued += take * c.layers[0].cost
+		c.layers[0].quantity = roundQuantity(c.layers[0].quantity - take)
+		quantity = roundQuantity(quantity - take)
+		if c.layers[0].quantity == 0 {
+			c.layers = c.layers[1:]
+		}
+	}
+	c.deficit = roundQuantity(c.deficit + quantity)
+	return issued + quantity*c.last
+}
+
+func (c *fifoCost) value() float64 {
+	total := 0.0
+	for _, l := range c.layers {
+		total += l.quantity * l.cost
+	}
+	return total
+}
+
+func (c *fifoCost) currentCost() float64 {
+	return c.last
+}
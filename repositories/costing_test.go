@@ -0,0 +1,250 @@
+package repositories
+
+import (
+	"database/sql"
+	"math"
+	"testing"
+
+	"github.com/anggakrnwn/kasir-api/models"
+)
+
+func closeTo(got, want float64) bool {
+	return math.Abs(got-want) < 0.005
+}
+
+// costStep adalah satu mutasi untuk costLedger: quantity positif diterima
+// dengan cost, negatif dikeluarkan dan issued adalah nilai yang diharapkan
+type costStep struct {
+	quantity float64
+	cost     float64
+	issued   float64
+}
+
+func TestCostLedger(t *testing.T) {
+	tests := []struct {
+		name        string
+		method      string
+		steps       []costStep
+		wantValue   float64
+		wantCurrent float64
+	}{
+		{
+			name:   "average re-averages on receive",
+			method: models.CostingAverage,
+			steps: []costStep{
+				{quantity: 10, cost: 1000},
+				{quantity: 10, cost: 2000},
+				{quantity: -5, issued: 7500},
+			},
+			wantValue:   22500,
+			wantCurrent: 1500,
+		},
+		{
+			name:   "average oversold then received",
+			method: models.CostingAverage,
+			steps: []costStep{
+				{quantity: 2, cost: 1000},
+				{quantity: -5, issued: 5000},
+				{quantity: 10, cost: 1200},
+			},
+			wantValue:   8400,
+			wantCurrent: 1200,
+		},
+		{
+			name:   "average issue everything",
+			method: models.CostingAverage,
+			steps: []costStep{
+				{quantity: 4, cost: 2500},
+				{quantity: -4, issued: 10000},
+			},
+			wantValue:   0,
+			wantCurrent: 2500,
+		},
+		{
+			name:   "fifo takes oldest layer first",
+			method: models.CostingFIFO,
+			steps: []costStep{
+				{quantity: 10, cost: 1000},
+				{quantity: 10, cost: 2000},
+				{quantity: -15, issued: 20000},
+			},
+			wantValue:   10000,
+			wantCurrent: 2000,
+		},
+		{
+			name:   "fifo deficit valued at last cost and covered by next receipt",
+			method: models.CostingFIFO,
+			steps: []costStep{
+				{quantity: 2, cost: 1000},
+				{quantity: -5, issued: 5000},
+				{quantity: 10, cost: 1200},
+			},
+			wantValue:   8400,
+			wantCurrent: 1200,
+		},
+		{
+			name:   "fifo receipt smaller than deficit",
+			method: models.CostingFIFO,
+			steps: []costStep{
+				{quantity: 1, cost: 1000},
+				{quantity: -4, issued: 4000},
+				{quantity: 2, cost: 1100},
+				{quantity: 5, cost: 1300},
+			},
+			wantValue:   5200,
+			wantCurrent: 1300,
+		},
+		{
+			name:   "fifo fractional quantities",
+			method: models.CostingFIFO,
+			steps: []costStep{
+				{quantity: 1.25, cost: 14000},
+				{quantity: -0.3, issued: 4200},
+				{quantity: 0.5, cost: 16000},
+				{quantity: -1.0, issued: 14100},
+			},
+			wantValue:   7200,
+			wantCurrent: 16000,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ledger := newCostLedger(tt.method)
+			for i, step := range tt.steps {
+				if step.quantity >= 0 {
+					ledger.receive(step.quantity, step.cost)
+					continue
+				}
+				if got := ledger.issue(-step.quantity); !closeTo(got, step.issued) {
+					t.Errorf("step %d: issue(%v) = %v, want %v", i, -step.quantity, got, step.issued)
+				}
+			}
+
+			if got := ledger.value(); !closeTo(got, tt.wantValue) {
+				t.Errorf("value() = %v, want %v", got, tt.wantValue)
+			}
+			if got := ledger.currentCost(); !closeTo(got, tt.wantCurrent) {
+				t.Errorf("currentCost() = %v, want %v", got, tt.wantCurrent)
+			}
+		})
+	}
+}
+
+// replayStep adalah satu baris stock_movements, unitCost 0 berarti NULL
+type replayStep struct {
+	quantity    float64
+	unitCost    float64
+	reason      string
+	referenceID int64
+}
+
+func TestCostReplay(t *testing.T) {
+	tests := []struct {
+		name        string
+		method      string
+		productCost float64
+		steps       []replayStep
+		wantValue   float64
+	}{
+		{
+			name:   "transfer received in full keeps value",
+			method: models.CostingAverage,
+			steps: []replayStep{
+				{quantity: 10, unitCost: 1000, reason: models.StockReasonReceipt},
+				{quantity: -10, reason: models.StockReasonTransferOut, referenceID: 7},
+				{quantity: 10, reason: models.StockReasonTransferIn, referenceID: 7},
+			},
+			wantValue: 10000,
+		},
+		{
+			name:   "short transfer receipt reduces value",
+			method: models.CostingAverage,
+			steps: []replayStep{
+				{quantity: 10, unitCost: 1000, reason: models.StockReasonReceipt},
+				{quantity: -10, reason: models.StockReasonTransferOut, referenceID: 7},
+				{quantity: 8, reason: models.StockReasonTransferIn, referenceID: 7},
+			},
+			wantValue: 8000,
+		},
+		{
+			name:   "in transit stock is not valued",
+			method: models.CostingAverage,
+			steps: []replayStep{
+				{quantity: 10, unitCost: 1000, reason: models.StockReasonReceipt},
+				{quantity: -4, reason: models.StockReasonTransferOut, referenceID: 7},
+			},
+			wantValue: 6000,
+		},
+		{
+			// 6 dikirim dari lapisan 5@1000 dan 1@2000, diterima 5 dengan
+			// harga pokok kirim 7000/6
+			name:   "fifo transfer at send cost with short receipt",
+			method: models.CostingFIFO,
+			steps: []replayStep{
+				{quantity: 5, unitCost: 1000, reason: models.StockReasonReceipt},
+				{quantity: 5, unitCost: 2000, reason: models.StockReasonReceipt},
+				{quantity: -6, reason: models.StockReasonTransferOut, referenceID: 9},
+				{quantity: 5, reason: models.StockReasonTransferIn, referenceID: 9},
+			},
+			wantValue: 8000 + 5*7000.0/6,
+		},
+		{
+			name:   "transfer in uses the cost of its own transfer",
+			method: models.CostingFIFO,
+			steps: []replayStep{
+				{quantity: 5, unitCost: 1000, reason: models.StockReasonReceipt},
+				{quantity: 5, unitCost: 3000, reason: models.StockReasonReceipt},
+				{quantity: -5, reason: models.StockReasonTransferOut, referenceID: 1},
+				{quantity: -5, reason: models.StockReasonTransferOut, referenceID: 2},
+				{quantity: 5, reason: models.StockReasonTransferIn, referenceID: 2},
+				{quantity: 5, reason: models.StockReasonTransferIn, referenceID: 1},
+			},
+			wantValue: 20000,
+		},
+		{
+			name:   "transfer in with explicit unit cost",
+			method: models.CostingAverage,
+			steps: []replayStep{
+				{quantity: 10, unitCost: 1000, reason: models.StockReasonReceipt},
+				{quantity: -10, reason: models.StockReasonTransferOut, referenceID: 7},
+				{quantity: 10, unitCost: 1500, reason: models.StockReasonTransferIn, referenceID: 7},
+			},
+			wantValue: 15000,
+		},
+		{
+			name:   "adjustment without cost uses running cost",
+			method: models.CostingAverage,
+			steps: []replayStep{
+				{quantity: 10, unitCost: 1000, reason: models.StockReasonReceipt},
+				{quantity: 2, reason: models.StockReasonAdjustment},
+			},
+			wantValue: 12000,
+		},
+		{
+			name:        "opening stock without cost uses product cost",
+			method:      models.CostingFIFO,
+			productCost: 2500,
+			steps: []replayStep{
+				{quantity: 4, reason: models.StockReasonOpening},
+				{quantity: -1, reason: models.StockReasonSale},
+			},
+			wantValue: 7500,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			replay := newCostReplay(tt.method, tt.productCost)
+			for _, step := range tt.steps {
+				unitCost := sql.NullFloat64{Float64: step.unitCost, Valid: step.unitCost != 0}
+				referenceID := sql.NullInt64{Int64: step.referenceID, Valid: step.referenceID != 0}
+				replay.apply(step.quantity, unitCost, step.reason, referenceID)
+			}
+
+			if got := replay.ledger.value(); !closeTo(got, tt.wantValue) {
+				t.Errorf("value() = %v, want %v", got, tt.wantValue)
+			}
+		})
+	}
+}
@@ -0,0 +1,131 @@
+package repositories
+
+import (
+	"database/sql"
+	"errors"
+
+	"github.com/anggakrnwn/kasir-api/models"
+)
+
+type CustomerRepository struct {
+	db *sql.DB
+}
+
+func NewCustomerRepository(db *sql.DB) *CustomerRepository {
+	return &CustomerRepository{db: db}
+}
+
+func (repo *CustomerRepository) GetAllGroups() ([]models.CustomerGroup, error) {
+	rows, err := repo.db.Query("SELECT id, code, name, created_at FROM customer_groups ORDER BY id")
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	groups := make([]models.CustomerGroup, 0)
+	for rows.Next() {
+		var g models.CustomerGroup
+		if err := rows.Scan(&g.ID, &g.Code, &g.Name, &g.CreatedAt); err != nil {
+			return nil, err
+		}
+		groups = append(groups, g)
+	}
+
+	return groups, rows.Err()
+}
+
+func (repo *CustomerRepository) CreateGroup(group *models.CustomerGroup) error {
+	query := "INSERT INTO customer_groups (code, name) VALUES ($1, $2) RETURNING id, created_at"
+	return repo.db.QueryRow(query, group.Code, group.Name).Scan(&group.ID, &group.CreatedAt)
+}
+
+func (repo *CustomerRepository) GroupExists(id int) (bool, error) {
+	var exists bool
+	err := repo.db.QueryRow("SELECT EXISTS (SELECT 1 FROM customer_groups WHERE id = $1)", id).Scan(&exists)
+	return exists, err
+}
+
+const customerColumns = `c.id, c.name, COALESCE(c.phone, ''), COALESCE(c.address, ''), COALESCE(c.tax_id, ''), c.customer_group_id, COALESCE(g.code, ''), c.created_at`
+
+func scanCustomer(row interface{ Scan(...interface{}) error }) (*models.Customer, error) {
+	var c models.Customer
+	var groupID sql.NullInt64
+
+	if err := row.Scan(&c.ID, &c.Name, &c.Phone, &c.Address, &c.TaxID, &groupID, &c.CustomerGroup, &c.CreatedAt); err != nil {
+		return nil, err
+	}
+	if groupID.Valid {
+		v := int(groupID.Int64)
+		c.CustomerGroupID = &v
+	}
+
+	return &c, nil
+}
+
+func (repo *CustomerRepository) GetAll(name string) ([]models.Customer, error) {
+	query := "SELECT " + customerColumns + " FROM customers c LEFT JOIN customer_groups g ON g.id = c.customer_group_id"
+
+	args := []interface{}{}
+	if name != "" {
+		query += " WHERE c.name ILIKE $1"
+		args = append(args, "%"+name+"%")
+	}
+	query += " ORDER BY c.name"
+
+	rows, err := repo.db.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	customers := make([]models.Customer, 0)
+	for rows.Next() {
+		c, err := scanCustomer(rows)
+		if err != nil {
+			return nil, err
+		}
+		customers = append(customers, *c)
+	}
+
+	return customers, rows.Err()
+}
+
+func (repo *CustomerRepository) GetByID(id int) (*models.Customer, error) {
+	query := "SELECT " + customerColumns + " FROM customers c LEFT JOIN customer_groups g ON g.id = c.customer_group_id WHERE c.id = $1"
+
+	c, err := scanCustomer(repo.db.QueryRow(query, id))
+	if err == sql.ErrNoRows {
+		return nil, errors.New("pelanggan tidak ditemukan")
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return c, nil
+}
+
+func (repo *CustomerRepository) Create(customer *models.Customer) error {
+	query := `INSERT INTO customers (name, phone, address, tax_id, customer_group_id)
+		VALUES ($1, NULLIF($2, ''), NULLIF($3, ''), NULLIF($4, ''), $5) RETURNING id, created_at`
+	return repo.db.QueryRow(query, customer.Name, customer.Phone, customer.Address, customer.TaxID, customer.CustomerGroupID).Scan(&customer.ID, &customer.CreatedAt)
+}
+
+func (repo *CustomerRepository) Update(customer *models.Customer) error {
+	query := `UPDATE customers SET name = $1, phone = NULLIF($2, ''), address = NULLIF($3, ''), tax_id = NULLIF($4, ''),
+		customer_group_id = $5 WHERE id = $6`
+	result, err := repo.db.Exec(query, customer.Name, customer.Phone, customer.Address, customer.TaxID, customer.CustomerGroupID, customer.ID)
+	if err != nil {
+		return err
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if rows == 0 {
+		return errors.New("pelanggan tidak ditemukan")
+	}
+
+	return nil
+}
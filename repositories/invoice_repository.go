@@ -0,0 +1,105 @@
+package repositories
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"github.com/anggakrnwn/kasir-api/models"
+)
+
+type InvoiceRepository struct {
+	db *sql.DB
+}
+
+func NewInvoiceRepository(db *sql.DB) *InvoiceRepository {
+	return &InvoiceRepository{db: db}
+}
+
+// Issue menerbitkan faktur untuk transaksi, atau mengembalikan faktur yang
+// sudah ada (created false). Nomor diambil dari invoice_sequences dalam
+// transaksi database yang sama, jadi nomor yang batal ikut di-rollback dan
+// tidak ada celah. Baris transaksi dikunci supaya dua permintaan bersamaan
+// tidak menerbitkan dua faktur.
+func (repo *InvoiceRepository) Issue(transactionID, year int) (*models.Invoice, bool, error) {
+	tx, err := repo.db.Begin()
+	if err != nil {
+		return nil, false, err
+	}
+	defer tx.Rollback()
+
+	var customerID sql.NullInt64
+	err = tx.QueryRow("SELECT customer_id FROM transactions WHERE id = $1 FOR UPDATE", transactionID).Scan(&customerID)
+	if err == sql.ErrNoRows {
+		return nil, false, errors.New("transaksi tidak ditemukan")
+	}
+	if err != nil {
+		return nil, false, err
+	}
+
+	invoice, err := getInvoice(tx, transactionID)
+	if err != nil {
+		return nil, false, err
+	}
+	if invoice != nil {
+		return invoice, false, nil
+	}
+
+	if !customerID.Valid {
+		return nil, false, fmt.Errorf("transaction %d has no customer", transactionID)
+	}
+
+	var sequence int
+	err = tx.QueryRow(`
+		INSERT INTO invoice_sequences (year, last_number) VALUES ($1, 1)
+		ON CONFLICT (year) DO UPDATE SET last_number = invoice_sequences.last_number + 1
+		RETURNING last_number
+	`, year).Scan(&sequence)
+	if err != nil {
+		return nil, false, err
+	}
+
+	number := fmt.Sprintf("INV/%d/%06d", year, sequence)
+	_, err = tx.Exec(`
+		INSERT INTO invoices (transaction_id, number, customer_id, customer_name, customer_address, customer_tax_id)
+		SELECT $1, $2, c.id, c.name, c.address, c.tax_id FROM customers c WHERE c.id = $3
+	`, transactionID, number, customerID.Int64)
+	if err != nil {
+		return nil, false, err
+	}
+
+	invoice, err = getInvoice(tx, transactionID)
+	if err != nil {
+		return nil, false, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, false, err
+	}
+
+	return invoice, true, nil
+}
+
+// GetByTransactionID mengembalikan nil jika faktur belum diterbitkan
+func (repo *InvoiceRepository) GetByTransactionID(transactionID int) (*models.Invoice, error) {
+	return getInvoice(repo.db, transactionID)
+}
+
+// getInvoice mengembalikan nil jika transaksi belum punya faktur
+func getInvoice(q queryer, transactionID int) (*models.Invoice, error) {
+	var invoice models.Invoice
+	err := q.QueryRow(`
+		SELECT id, transaction_id, number, customer_id, customer_name,
+			COALESCE(customer_address, ''), COALESCE(customer_tax_id, ''), issued_at
+		FROM invoices WHERE transaction_id = $1
+	`, transactionID).Scan(&invoice.ID, &invoice.TransactionID, &invoice.Number, &invoice.CustomerID, &invoice.CustomerName,
+		&invoice.CustomerAddress, &invoice.CustomerTaxID, &invoice.IssuedAt)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return &invoice, nil
+}
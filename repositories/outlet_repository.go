@@ -0,0 +1,97 @@
+package repositories
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"github.com/anggakrnwn/kasir-api/models"
+)
+
+type OutletRepository struct {
+	db *sql.DB
+}
+
+func NewOutletRepository(db *sql.DB) *OutletRepository {
+	return &OutletRepository{db: db}
+}
+
+func (repo *OutletRepository) GetAll() ([]models.Outlet, error) {
+	rows, err := repo.db.Query("SELECT id, code, name, type, COALESCE(address, ''), active, created_at FROM outlets ORDER BY id")
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	outlets := make([]models.Outlet, 0)
+	for rows.Next() {
+		var o models.Outlet
+		if err := rows.Scan(&o.ID, &o.Code, &o.Name, &o.Type, &o.Address, &o.Active, &o.CreatedAt); err != nil {
+			return nil, err
+		}
+		outlets = append(outlets, o)
+	}
+
+	return outlets, rows.Err()
+}
+
+func (repo *OutletRepository) GetByID(id int) (*models.Outlet, error) {
+	var o models.Outlet
+	err := repo.db.QueryRow(
+		"SELECT id, code, name, type, COALESCE(address, ''), active, created_at FROM outlets WHERE id = $1", id,
+	).Scan(&o.ID, &o.Code, &o.Name, &o.Type, &o.Address, &o.Active, &o.CreatedAt)
+	if err == sql.ErrNoRows {
+		return nil, errors.New("outlet tidak ditemukan")
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return &o, nil
+}
+
+// Create memberi kode OUT{id} jika outlet.Code kosong. ID diambil dari
+// sequence lebih dulu supaya kode bawaan bisa langsung diisi.
+func (repo *OutletRepository) Create(outlet *models.Outlet) error {
+	err := repo.db.QueryRow("SELECT nextval(pg_get_serial_sequence('outlets', 'id'))").Scan(&outlet.ID)
+	if err != nil {
+		return err
+	}
+	if outlet.Code == "" {
+		outlet.Code = fmt.Sprintf("OUT%d", outlet.ID)
+	}
+
+	query := "INSERT INTO outlets (id, code, name, type, address, active) VALUES ($1, $2, $3, $4, NULLIF($5, ''), TRUE) RETURNING active, created_at"
+	return repo.db.QueryRow(query, outlet.ID, outlet.Code, outlet.Name, outlet.Type, outlet.Address).Scan(&outlet.Active, &outlet.CreatedAt)
+}
+
+func (repo *OutletRepository) Update(outlet *models.Outlet) error {
+	result, err := repo.db.Exec(
+		"UPDATE outlets SET code = $1, name = $2, type = $3, address = NULLIF($4, ''), active = $5 WHERE id = $6",
+		outlet.Code, outlet.Name, outlet.Type, outlet.Address, outlet.Active, outlet.ID,
+	)
+	if err != nil {
+		return err
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if rows == 0 {
+		return errors.New("outlet tidak ditemukan")
+	}
+
+	return nil
+}
+
+// outletStock mengembalikan stok produk di satu outlet, 0 jika belum pernah ada
+func outletStock(q queryer, outletID, productID int) (float64, error) {
+	var stock float64
+	err := q.QueryRow(
+		"SELECT COALESCE((SELECT stock FROM outlet_stocks WHERE outlet_id = $1 AND product_id = $2), 0)",
+		outletID, productID,
+	).Scan(&stock)
+	return stock, err
+}
@@ -1,102 +1,703 @@
-package repositories
-
-import (
-	"database/sql"
-	"errors"
-
-	"github.com/anggakrnwn/kasir-api/models"
-)
-
-type ProductRepository struct {
-	db *sql.DB
-}
-
-func NewProductRepository(db *sql.DB) *ProductRepository {
-	return &ProductRepository{db: db}
-}
-
-func (repo *ProductRepository) GetAll(name string) ([]models.Product, error) {
-	query := "SELECT id, name, price, stock FROM products"
-
-	args := []interface{}{}
-	if name != "" {
-		query += " WHERE name ILIKE $1"
-		args = append(args, "%"+name+"%")
-	}
-
-	rows, err := repo.db.Query(query, args...)
-	if err != nil {
-		return nil, err
-	}
-	defer rows.Close()
-
-	products := make([]models.Product, 0)
-	for rows.Next() {
-		var p models.Product
-		err := rows.Scan(&p.ID, &p.Name, &p.Price, &p.Stock)
-		if err != nil {
-			return nil, err
-		}
-		products = append(products, p)
-	}
-
-	return products, nil
-}
-
-func (repo *ProductRepository) Create(product *models.Product) error {
-	query := "INSERT INTO products (name, price, stock) VALUES ($1, $2, $3) RETURNING id"
-	err := repo.db.QueryRow(query, product.Name, product.Price, product.Stock).Scan(&product.ID)
-	return err
-}
-
-func (repo *ProductRepository) GetByID(id int) (*models.Product, error) {
-	query := "SELECT id, name, price, stock FROM products WHERE id = $1"
-
-	var p models.Product
-	err := repo.db.QueryRow(query, id).Scan(&p.ID, &p.Name, &p.Price, &p.Stock)
-	if err == sql.ErrNoRows {
-		return nil, errors.New("produk tidak ditemukan")
-	}
-	if err != nil {
-		return nil, err
-	}
-
-	return &p, nil
-}
-
-func (repo *ProductRepository) Update(product *models.Product) error {
-	query := "UPDATE products SET name = $1, price = $2, stock = $3 WHERE id = $4"
-	result, err := repo.db.Exec(query, product.Name, product.Price, product.Stock, product.ID)
-	if err != nil {
-		return err
-	}
-
-	rows, err := result.RowsAffected()
-	if err != nil {
-		return err
-	}
-
-	if rows == 0 {
-		return errors.New("produk tidak ditemukan")
-	}
-
-	return nil
-}
-
-func (repo *ProductRepository) Delete(id int) error {
-	query := "DELETE FROM products WHERE id = $1"
-	result, err := repo.db.Exec(query, id)
-	if err != nil {
-		return err
-	}
-	rows, err := result.RowsAffected()
-	if err != nil {
-		return err
-	}
-
-	if rows == 0 {
-		return errors.New("produk tidak ditemukan")
-	}
-
-	return err
-}
+package repositories
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"github.com/anggakrnwn/kasir-api/models"
+)
+
+// ErrProductHasHistory menolak penghapusan produk yang sudah punya mutasi stok
+// atau penjualan, supaya kartu stok, nilai persediaan dan laporan lama tidak
+// ikut berubah
+var ErrProductHasHistory = errors.New("product has stock or sales history and cannot be deleted")
+
+// currentPriceSQL memilih harga yang berlaku saat ini dari riwayat harga,
+// termasuk jadwal perubahan harga yang waktunya sudah tiba
+const currentPriceSQL = `COALESCE((
+	SELECT pp.price FROM product_prices pp
+	WHERE pp.product_id = p.id AND pp.effective_from <= NOW()
+	ORDER BY pp.effective_from DESC, pp.id DESC
+	LIMIT 1
+), p.price)`
+
+const productColumns = `p.id, p.name, ` + currentPriceSQL + `, p.stock, p.unit, p.parent_id,
+	COALESCE(p.variant_name, ''), COALESCE(p.sku, ''), COALESCE(p.barcode, ''), p.is_weighed, COALESCE(p.plu, ''), p.is_bundle,
+	p.min_stock, p.reorder_qty, p.category_id, COALESCE((SELECT c.name FROM categories c WHERE c.id = p.category_id), ''), p.cost,
+	` + reservedStockSQL
+
+func scanProduct(row interface{ Scan(...interface{}) error }) (*models.Product, error) {
+	var p models.Product
+	var parentID, categoryID sql.NullInt64
+
+	err := row.Scan(&p.ID, &p.Name, &p.Price, &p.Stock, &p.Unit, &parentID, &p.VariantName, &p.SKU, &p.Barcode,
+		&p.IsWeighed, &p.PLU, &p.IsBundle, &p.MinStock, &p.ReorderQty, &categoryID, &p.CategoryName, &p.Cost,
+		&p.ReservedStock)
+	if err != nil {
+		return nil, err
+	}
+	p.AvailableStock = roundQuantity(p.Stock - p.ReservedStock)
+	if parentID.Valid {
+		v := int(parentID.Int64)
+		p.ParentID = &v
+	}
+	if categoryID.Valid {
+		v := int(categoryID.Int64)
+		p.CategoryID = &v
+	}
+
+	return &p, nil
+}
+
+type ProductRepository struct {
+	db *sql.DB
+}
+
+func NewProductRepository(db *sql.DB) *ProductRepository {
+	return &ProductRepository{db: db}
+}
+
+// GetAll mengembalikan produk yang dikelompokkan per induk: varian ada di
+// dalam field Variants milik induknya. Filter name juga mencocokkan varian,
+// SKU dan barcode, dan selalu mengembalikan satu kelompok utuh.
+func (repo *ProductRepository) GetAll(name string) ([]models.Product, error) {
+	query := "SELECT " + productColumns + " FROM products p"
+
+	args := []interface{}{}
+	if name != "" {
+		query += ` WHERE COALESCE(p.parent_id, p.id) IN (
+			SELECT COALESCE(m.parent_id, m.id) FROM products m
+			WHERE m.name ILIKE $1 OR m.variant_name ILIKE $1 OR m.sku = $2 OR m.barcode = $2
+		)`
+		args = append(args, "%"+name+"%", name)
+	}
+	query += " ORDER BY COALESCE(p.parent_id, p.id), p.parent_id NULLS FIRST, p.id"
+
+	rows, err := repo.db.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	products := make([]models.Product, 0)
+	index := make(map[int]int)
+	for rows.Next() {
+		p, err := scanProduct(rows)
+		if err != nil {
+			return nil, err
+		}
+
+		if p.ParentID != nil {
+			if i, ok := index[*p.ParentID]; ok {
+				products[i].Variants = append(products[i].Variants, *p)
+				continue
+			}
+		}
+
+		index[p.ID] = len(products)
+		products = append(products, *p)
+	}
+
+	return products, rows.Err()
+}
+
+func (repo *ProductRepository) Create(product *models.Product) error {
+	tx, err := repo.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	query := `
+		INSERT INTO products (name, price, stock, unit, parent_id, variant_name, sku, barcode, is_weighed, plu, is_bundle,
+			min_stock, reorder_qty, category_id, cost)
+		VALUES ($1, $2, 0, $3, $4, NULLIF($5, ''), NULLIF($6, ''), NULLIF($7, ''), $8, NULLIF($9, ''), $10, $11, $12, $13, $14)
+		RETURNING id
+	`
+	err = tx.QueryRow(query, product.Name, product.Price, product.Unit, product.ParentID,
+		product.VariantName, product.SKU, product.Barcode, product.IsWeighed, product.PLU, product.IsBundle,
+		product.MinStock, product.ReorderQty, product.CategoryID, product.Cost).Scan(&product.ID)
+	if err != nil {
+		return err
+	}
+
+	// stok awal dinilai dengan harga pokok produk jika diisi
+	if product.Stock > 0 {
+		var unitCost *float64
+		if product.Cost > 0 {
+			unitCost = &product.Cost
+		}
+
+		err = applyStockMovement(tx, &models.StockMovement{
+			OutletID:  product.OutletID,
+			ProductID: product.ID,
+			Quantity:  product.Stock,
+			Unit:      product.Unit,
+			Reason:    models.StockReasonOpening,
+			UnitCost:  unitCost,
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	_, err = tx.Exec("INSERT INTO product_prices (product_id, price, effective_from) VALUES ($1, $2, NOW())", product.ID, product.Price)
+	if err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// GetByID menyertakan varian jika produk adalah produk induk
+func (repo *ProductRepository) GetByID(id int) (*models.Product, error) {
+	query := "SELECT " + productColumns + " FROM products p WHERE p.id = $1"
+
+	p, err := scanProduct(repo.db.QueryRow(query, id))
+	if err == sql.ErrNoRows {
+		return nil, errors.New("produk tidak ditemukan")
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := repo.db.Query("SELECT "+productColumns+" FROM products p WHERE p.parent_id = $1 ORDER BY p.id", id)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		v, err := scanProduct(rows)
+		if err != nil {
+			return nil, err
+		}
+		p.Variants = append(p.Variants, *v)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	units, err := repo.GetUnits(id)
+	if err != nil {
+		return nil, err
+	}
+	if len(units) > 0 {
+		p.Units = units
+	}
+
+	if p.IsBundle {
+		p.Components, err = repo.GetComponents(id)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	p.OutletStocks, err = repo.GetOutletStocks(id)
+	if err != nil {
+		return nil, err
+	}
+
+	return p, nil
+}
+
+// GetByBarcode mengembalikan nil tanpa error jika barcode tidak terdaftar
+func (repo *ProductRepository) GetByBarcode(barcode string) (*models.Product, error) {
+	return repo.getByColumn("barcode", barcode)
+}
+
+// GetByPLU mengembalikan nil tanpa error jika kode PLU tidak terdaftar. Price
+// adalah harga yang berlaku sekarang (currentPriceSQL), sama seperti checkout.
+func (repo *ProductRepository) GetByPLU(plu string) (*models.Product, error) {
+	return repo.getByColumn("plu", plu)
+}
+
+func (repo *ProductRepository) getByColumn(column, value string) (*models.Product, error) {
+	query := "SELECT " + productColumns + " FROM products p WHERE p." + column + " = $1"
+
+	p, err := scanProduct(repo.db.QueryRow(query, value))
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	return p, err
+}
+
+// GetOutletStocks mengembalikan stok produk di setiap outlet yang pernah
+// menyimpannya, beserta jumlah yang ditahan reservasi aktif dan yang sedang
+// dalam perjalanan ke outlet itu
+func (repo *ProductRepository) GetOutletStocks(productID int) ([]models.OutletStock, error) {
+	rows, err := repo.db.Query(`
+		SELECT o.id, o.name, COALESCE(os.stock, 0), COALESCE(rs.quantity, 0), COALESCE(tr.quantity, 0)
+		FROM outlets o
+		LEFT JOIN outlet_stocks os ON os.outlet_id = o.id AND os.product_id = $1
+		LEFT JOIN (
+			SELECT r.outlet_id, SUM(i.quantity) AS quantity
+			FROM stock_reservation_items i
+			JOIN stock_reservations r ON r.id = i.reservation_id
+			WHERE r.status = 'active' AND r.expires_at > NOW() AND i.product_id = $1
+			GROUP BY r.outlet_id
+		) rs ON rs.outlet_id = o.id
+		LEFT JOIN (
+			SELECT t.to_outlet_id, SUM(i.quantity_sent) AS quantity
+			FROM stock_transfer_items i
+			JOIN stock_transfers t ON t.id = i.transfer_id
+			WHERE t.status = 'sent' AND i.product_id = $1
+			GROUP BY t.to_outlet_id
+		) tr ON tr.to_outlet_id = o.id
+		WHERE os.outlet_id IS NOT NULL OR tr.quantity IS NOT NULL
+		ORDER BY o.id
+	`, productID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	stocks := make([]models.OutletStock, 0)
+	for rows.Next() {
+		var s models.OutletStock
+		if err := rows.Scan(&s.OutletID, &s.OutletName, &s.Stock, &s.Reserved, &s.InTransit); err != nil {
+			return nil, err
+		}
+		s.Available = roundQuantity(s.Stock - s.Reserved)
+		stocks = append(stocks, s)
+	}
+
+	return stocks, rows.Err()
+}
+
+func (repo *ProductRepository) GetAllCategories() ([]models.Category, error) {
+	rows, err := repo.db.Query("SELECT id, name, created_at FROM categories ORDER BY name")
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	categories := make([]models.Category, 0)
+	for rows.Next() {
+		var c models.Category
+		if err := rows.Scan(&c.ID, &c.Name, &c.CreatedAt); err != nil {
+			return nil, err
+		}
+		categories = append(categories, c)
+	}
+
+	return categories, rows.Err()
+}
+
+func (repo *ProductRepository) CreateCategory(category *models.Category) error {
+	query := "INSERT INTO categories (name) VALUES ($1) RETURNING id, created_at"
+	return repo.db.QueryRow(query, category.Name).Scan(&category.ID, &category.CreatedAt)
+}
+
+func (repo *ProductRepository) CategoryExists(id int) (bool, error) {
+	var exists bool
+	err := repo.db.QueryRow("SELECT EXISTS (SELECT 1 FROM categories WHERE id = $1)", id).Scan(&exists)
+	return exists, err
+}
+
+func (repo *ProductRepository) HasVariants(id int) (bool, error) {
+	var exists bool
+	err := repo.db.QueryRow("SELECT EXISTS (SELECT 1 FROM products WHERE parent_id = $1)", id).Scan(&exists)
+	return exists, err
+}
+
+// Update mencatat baris riwayat harga baru jika harga berbeda dari harga yang
+// berlaku, dan perubahan stok manual sebagai adjustment di stock ledger
+func (repo *ProductRepository) Update(product *models.Product) error {
+	tx, err := repo.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	var currentPrice int
+	var currentStock float64
+	err = tx.QueryRow("SELECT "+currentPriceSQL+", p.stock FROM products p WHERE p.id = $1 FOR UPDATE OF p", product.ID).Scan(&currentPrice, &currentStock)
+	if err == sql.ErrNoRows {
+		return errors.New("produk tidak ditemukan")
+	}
+	if err != nil {
+		return err
+	}
+
+	query := `
+		UPDATE products
+		SET name = $1, price = $2, unit = $3, parent_id = $4, variant_name = NULLIF($5, ''),
+			sku = NULLIF($6, ''), barcode = NULLIF($7, ''), is_weighed = $8, plu = NULLIF($9, ''), is_bundle = $10,
+			min_stock = $11, reorder_qty = $12, category_id = $13, cost = $14, updated_at = NOW()
+		WHERE id = $15
+	`
+	_, err = tx.Exec(query, product.Name, product.Price, product.Unit, product.ParentID,
+		product.VariantName, product.SKU, product.Barcode, product.IsWeighed, product.PLU, product.IsBundle,
+		product.MinStock, product.ReorderQty, product.CategoryID, product.Cost, product.ID)
+	if err != nil {
+		return err
+	}
+
+	// Stock adalah total semua outlet, selisihnya diterapkan ke outlet product.OutletID
+	if delta := roundQuantity(product.Stock - currentStock); delta != 0 {
+		available, err := outletStock(tx, product.OutletID, product.ID)
+		if err != nil {
+			return err
+		}
+		if available+delta < 0 {
+			return fmt.Errorf("stock at outlet %d would become negative. Available: %g, Change: %g",
+				product.OutletID, available, delta)
+		}
+
+		// stok yang ditahan reservasi aktif tidak boleh dikurangi
+		if delta < 0 {
+			reserved, err := reservedStock(tx, product.OutletID, product.ID, 0)
+			if err != nil {
+				return err
+			}
+			if free := roundQuantity(available - reserved); free+delta < 0 {
+				return fmt.Errorf("stock at outlet %d is reserved. Unreserved: %g, Change: %g",
+					product.OutletID, free, delta)
+			}
+		}
+
+		err = applyStockMovement(tx, &models.StockMovement{
+			OutletID:  product.OutletID,
+			ProductID: product.ID,
+			Quantity:  delta,
+			Unit:      product.Unit,
+			Reason:    models.StockReasonAdjustment,
+			Note:      "manual update",
+		})
+		if err != nil {
+			return err
+		}
+
+		// stok yang dikurangi manual (rusak, kedaluwarsa) diambil dari lot FEFO
+		if delta < 0 {
+			if _, err := consumeLots(tx, product.OutletID, product.ID, -delta); err != nil {
+				return err
+			}
+		}
+	}
+
+	if product.Price != currentPrice {
+		_, err = tx.Exec("INSERT INTO product_prices (product_id, price, effective_from) VALUES ($1, $2, NOW())", product.ID, product.Price)
+		if err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
+
+func (repo *ProductRepository) GetPriceHistory(productID int) ([]models.ProductPrice, error) {
+	query := `
+		SELECT id, product_id, price, effective_from, created_at,
+			CASE
+				WHEN effective_from > NOW() THEN 'scheduled'
+				WHEN id = (
+					SELECT pp.id FROM product_prices pp
+					WHERE pp.product_id = $1 AND pp.effective_from <= NOW()
+					ORDER BY pp.effective_from DESC, pp.id DESC
+					LIMIT 1
+				) THEN 'active'
+				ELSE 'past'
+			END
+		FROM product_prices
+		WHERE product_id = $1
+		ORDER BY effective_from DESC, id DESC
+	`
+
+	rows, err := repo.db.Query(query, productID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	prices := make([]models.ProductPrice, 0)
+	for rows.Next() {
+		var p models.ProductPrice
+		if err := rows.Scan(&p.ID, &p.ProductID, &p.Price, &p.EffectiveFrom, &p.CreatedAt, &p.Status); err != nil {
+			return nil, err
+		}
+		prices = append(prices, p)
+	}
+
+	return prices, rows.Err()
+}
+
+func (repo *ProductRepository) SchedulePrice(price *models.ProductPrice) error {
+	query := "INSERT INTO product_prices (product_id, price, effective_from) VALUES ($1, $2, $3) RETURNING id, created_at"
+	err := repo.db.QueryRow(query, price.ProductID, price.Price, price.EffectiveFrom).Scan(&price.ID, &price.CreatedAt)
+	if err != nil {
+		return err
+	}
+
+	price.Status = models.PriceStatusScheduled
+	return nil
+}
+
+// CancelScheduledPrice hanya bisa menghapus harga yang belum berlaku
+func (repo *ProductRepository) CancelScheduledPrice(productID, priceID int) error {
+	result, err := repo.db.Exec(
+		"DELETE FROM product_prices WHERE id = $1 AND product_id = $2 AND effective_from > NOW()",
+		priceID, productID,
+	)
+	if err != nil {
+		return err
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if rows == 0 {
+		return errors.New("jadwal harga tidak ditemukan")
+	}
+
+	return nil
+}
+
+// Delete hanya untuk produk yang belum pernah punya mutasi stok atau
+// penjualan. stock_movements dan product_prices ikut terhapus (cascade), jadi
+// produk dengan riwayat ditolak dengan ErrProductHasHistory.
+func (repo *ProductRepository) Delete(id int) error {
+	tx, err := repo.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	var hasHistory bool
+	err = tx.QueryRow(`
+		SELECT EXISTS (SELECT 1 FROM stock_movements WHERE product_id = p.id)
+			OR EXISTS (SELECT 1 FROM transaction_details WHERE product_id = p.id)
+		FROM products p WHERE p.id = $1 FOR UPDATE
+	`, id).Scan(&hasHistory)
+	if err == sql.ErrNoRows {
+		return errors.New("produk tidak ditemukan")
+	}
+	if err != nil {
+		return err
+	}
+	if hasHistory {
+		return ErrProductHasHistory
+	}
+
+	if _, err := tx.Exec("DELETE FROM products WHERE id = $1", id); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+func (repo *ProductRepository) GetPriceTiers(productID int) ([]models.PriceTier, error) {
+	query := `
+		SELECT t.id, t.product_id, t.customer_group_id, COALESCE(g.code, ''), t.min_quantity, t.price, t.created_at
+		FROM product_price_tiers t
+		LEFT JOIN customer_groups g ON g.id = t.customer_group_id
+		WHERE t.product_id = $1
+		ORDER BY g.code NULLS FIRST, t.min_quantity
+	`
+
+	rows, err := repo.db.Query(query, productID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	tiers := make([]models.PriceTier, 0)
+	for rows.Next() {
+		var t models.PriceTier
+		var groupID sql.NullInt64
+		if err := rows.Scan(&t.ID, &t.ProductID, &groupID, &t.CustomerGroup, &t.MinQuantity, &t.Price, &t.CreatedAt); err != nil {
+			return nil, err
+		}
+		if groupID.Valid {
+			v := int(groupID.Int64)
+			t.CustomerGroupID = &v
+		}
+		tiers = append(tiers, t)
+	}
+
+	return tiers, rows.Err()
+}
+
+func (repo *ProductRepository) CreatePriceTier(tier *models.PriceTier) error {
+	if tier.CustomerGroupID != nil {
+		err := repo.db.QueryRow("SELECT code FROM customer_groups WHERE id = $1", *tier.CustomerGroupID).Scan(&tier.CustomerGroup)
+		if err == sql.ErrNoRows {
+			return errors.New("kelompok pelanggan tidak ditemukan")
+		}
+		if err != nil {
+			return err
+		}
+	}
+
+	query := `
+		INSERT INTO product_price_tiers (product_id, customer_group_id, min_quantity, price)
+		VALUES ($1, $2, $3, $4)
+		ON CONFLICT (product_id, customer_group_id, min_quantity) DO UPDATE SET price = EXCLUDED.price
+		RETURNING id, created_at
+	`
+	return repo.db.QueryRow(query, tier.ProductID, tier.CustomerGroupID, tier.MinQuantity, tier.Price).Scan(&tier.ID, &tier.CreatedAt)
+}
+
+func (repo *ProductRepository) DeletePriceTier(productID, tierID int) error {
+	result, err := repo.db.Exec("DELETE FROM product_price_tiers WHERE id = $1 AND product_id = $2", tierID, productID)
+	if err != nil {
+		return err
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if rows == 0 {
+		return errors.New("tier harga tidak ditemukan")
+	}
+
+	return nil
+}
+
+func (repo *ProductRepository) GetUnits(productID int) ([]models.ProductUnit, error) {
+	rows, err := repo.db.Query(
+		"SELECT id, product_id, name, factor, price, created_at FROM product_units WHERE product_id = $1 ORDER BY factor",
+		productID,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	units := make([]models.ProductUnit, 0)
+	for rows.Next() {
+		var u models.ProductUnit
+		var price sql.NullInt64
+		if err := rows.Scan(&u.ID, &u.ProductID, &u.Name, &u.Factor, &price, &u.CreatedAt); err != nil {
+			return nil, err
+		}
+		if price.Valid {
+			v := int(price.Int64)
+			u.Price = &v
+		}
+		units = append(units, u)
+	}
+
+	return units, rows.Err()
+}
+
+func (repo *ProductRepository) SetUnit(unit *models.ProductUnit) error {
+	query := `
+		INSERT INTO product_units (product_id, name, factor, price)
+		VALUES ($1, $2, $3, $4)
+		ON CONFLICT (product_id, name) DO UPDATE SET factor = EXCLUDED.factor, price = EXCLUDED.price
+		RETURNING id, created_at
+	`
+	return repo.db.QueryRow(query, unit.ProductID, unit.Name, unit.Factor, unit.Price).Scan(&unit.ID, &unit.CreatedAt)
+}
+
+func (repo *ProductRepository) DeleteUnit(productID, unitID int) error {
+	result, err := repo.db.Exec("DELETE FROM product_units WHERE id = $1 AND product_id = $2", unitID, productID)
+	if err != nil {
+		return err
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if rows == 0 {
+		return errors.New("satuan tidak ditemukan")
+	}
+
+	return nil
+}
+
+func (repo *ProductRepository) GetComponents(bundleID int) ([]models.BundleComponent, error) {
+	rows, err := repo.db.Query(`
+		SELECT bc.id, bc.bundle_id, bc.component_id, p.name, bc.quantity
+		FROM bundle_components bc
+		JOIN products p ON p.id = bc.component_id
+		WHERE bc.bundle_id = $1
+		ORDER BY bc.component_id
+	`, bundleID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	components := make([]models.BundleComponent, 0)
+	for rows.Next() {
+		var c models.BundleComponent
+		if err := rows.Scan(&c.ID, &c.BundleID, &c.ProductID, &c.ProductName, &c.Quantity); err != nil {
+			return nil, err
+		}
+		components = append(components, c)
+	}
+
+	return components, rows.Err()
+}
+
+// SetComponents mengganti seluruh isi paket. Komponen harus produk biasa:
+// bukan paket, bukan produk induk varian.
+func (repo *ProductRepository) SetComponents(bundleID int, components []models.BundleComponent) error {
+	tx, err := repo.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	var isBundle bool
+	err = tx.QueryRow("SELECT is_bundle FROM products WHERE id = $1 FOR UPDATE", bundleID).Scan(&isBundle)
+	if err == sql.ErrNoRows {
+		return errors.New("produk tidak ditemukan")
+	}
+	if err != nil {
+		return err
+	}
+	if !isBundle {
+		return fmt.Errorf("product id %d is not a bundle", bundleID)
+	}
+
+	if _, err := tx.Exec("DELETE FROM bundle_components WHERE bundle_id = $1", bundleID); err != nil {
+		return err
+	}
+
+	for i := range components {
+		c := &components[i]
+
+		var name string
+		var isComponentBundle, isWeighed, hasVariants bool
+		err := tx.QueryRow(`
+			SELECT p.name, p.is_bundle, p.is_weighed, EXISTS (SELECT 1 FROM products v WHERE v.parent_id = p.id)
+			FROM products p WHERE p.id = $1
+		`, c.ProductID).Scan(&name, &isComponentBundle, &isWeighed, &hasVariants)
+		if err == sql.ErrNoRows {
+			return fmt.Errorf("component product id %d not found", c.ProductID)
+		}
+		if err != nil {
+			return err
+		}
+		if isComponentBundle || hasVariants {
+			return fmt.Errorf("product '%s' cannot be a bundle component", name)
+		}
+		if !isWeighed && !isWholeQuantity(c.Quantity) {
+			return fmt.Errorf("product '%s' is not weighed, quantity must be a whole number", name)
+		}
+
+		c.BundleID = bundleID
+		c.ProductName = name
+		c.Quantity = roundQuantity(c.Quantity)
+		err = tx.QueryRow(
+			"INSERT INTO bundle_components (bundle_id, component_id, quantity) VALUES ($1, $2, $3) RETURNING id",
+			bundleID, c.ProductID, c.Quantity,
+		).Scan(&c.ID)
+		if err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
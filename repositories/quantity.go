@@ -0,0 +1,20 @@
+package repositories
+
+import "math"
+
+// roundQuantity membulatkan jumlah ke 3 desimal sesuai kolom NUMERIC(12, 3)
+func roundQuantity(quantity float64) float64 {
+	return math.Round(quantity*1000) / 1000
+}
+
+func isWholeQuantity(quantity float64) bool {
+	return quantity == math.Trunc(quantity)
+}
+
+// lineSubtotal menghitung harga x jumlah dalam rupiah bulat dengan pembulatan
+// half-up. Jumlah diubah ke per-seribu (gram untuk kg) dulu supaya perkalian
+// dilakukan dengan integer dan tidak terpengaruh error pembulatan float.
+func lineSubtotal(unitPrice int, quantity float64) int {
+	milli := int64(math.Round(quantity * 1000))
+	return int((int64(unitPrice)*milli + 500) / 1000)
+}
@@ -0,0 +1,66 @@
+package repositories
+
+import "testing"
+
+func TestLineSubtotal(t *testing.T) {
+	tests := []struct {
+		name      string
+		unitPrice int
+		quantity  float64
+		want      int
+	}{
+		{"whole quantity", 3500, 3, 10500},
+		{"weighed kg", 14000, 1.25, 17500},
+		{"rounds half up", 1001, 0.5, 501},
+		{"rounds up above half", 999, 0.333, 333},
+		{"rounds down below half", 1001, 0.333, 333},
+		{"float error does not drop a rupiah", 12900, 0.3, 3870},
+		{"float error in quantity", 10000, 0.1 + 0.2, 3000},
+		{"gram precision", 7, 0.001, 0},
+		{"zero quantity", 5000, 0, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := lineSubtotal(tt.unitPrice, tt.quantity); got != tt.want {
+				t.Errorf("lineSubtotal(%d, %v) = %d, want %d", tt.unitPrice, tt.quantity, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRoundQuantity(t *testing.T) {
+	tests := []struct {
+		quantity float64
+		want     float64
+	}{
+		{1.2345, 1.235},
+		{0.1 + 0.2, 0.3},
+		{2, 2},
+		{-1.0004, -1},
+	}
+
+	for _, tt := range tests {
+		if got := roundQuantity(tt.quantity); got != tt.want {
+			t.Errorf("roundQuantity(%v) = %v, want %v", tt.quantity, got, tt.want)
+		}
+	}
+}
+
+func TestIsWholeQuantity(t *testing.T) {
+	tests := []struct {
+		quantity float64
+		want     bool
+	}{
+		{3, true},
+		{0, true},
+		{1.5, false},
+		{0.001, false},
+	}
+
+	for _, tt := range tests {
+		if got := isWholeQuantity(tt.quantity); got != tt.want {
+			t.Errorf("isWholeQuantity(%v) = %v, want %v", tt.quantity, got, tt.want)
+		}
+	}
+}
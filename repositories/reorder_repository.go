@@ -0,0 +1,325 @@
+package repositories
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/anggakrnwn/kasir-api/models"
+)
+
+type ReorderRepository struct {
+	db *sql.DB
+}
+
+func NewReorderRepository(db *sql.DB) *ReorderRepository {
+	return &ReorderRepository{db: db}
+}
+
+// lowStockQuery memilih produk dengan stok sendiri yang stoknya di bawah
+// min_stock di outlet aktif, $1 = 0 berarti semua outlet
+const lowStockQuery = `
+	SELECT o.id, o.name, p.id, p.name, p.unit, COALESCE(os.stock, 0), p.min_stock, p.reorder_qty
+	FROM products p
+	CROSS JOIN outlets o
+	LEFT JOIN outlet_stocks os ON os.outlet_id = o.id AND os.product_id = p.id
+	WHERE o.active AND p.min_stock > 0 AND NOT p.is_bundle
+		AND NOT EXISTS (SELECT 1 FROM products v WHERE v.parent_id = p.id)
+		AND COALESCE(os.stock, 0) < p.min_stock
+		AND ($1 = 0 OR o.id = $1)
+`
+
+func scanLowStock(rows *sql.Rows) (*models.LowStockItem, error) {
+	var i models.LowStockItem
+	err := rows.Scan(&i.OutletID, &i.OutletName, &i.ProductID, &i.ProductName, &i.Unit, &i.Stock, &i.MinStock, &i.ReorderQty)
+	if err != nil {
+		return nil, err
+	}
+
+	i.SuggestedQty = i.ReorderQty
+	if i.SuggestedQty == 0 {
+		i.SuggestedQty = roundQuantity(i.MinStock - i.Stock)
+	}
+
+	return &i, nil
+}
+
+// GetLowStock diurutkan dari yang paling kritis (stok terkecil dibanding min_stock)
+func (repo *ReorderRepository) GetLowStock(outletID int) ([]models.LowStockItem, error) {
+	rows, err := repo.db.Query(lowStockQuery+" ORDER BY o.id, COALESCE(os.stock, 0) / p.min_stock, p.name", outletID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	items := make([]models.LowStockItem, 0)
+	for rows.Next() {
+		i, err := scanLowStock(rows)
+		if err != nil {
+			return nil, err
+		}
+		items = append(items, *i)
+	}
+
+	return items, rows.Err()
+}
+
+// raiseLowStockAlerts mencatat peringatan untuk produk yang stoknya turun
+// melewati min_stock karena transaksi ini, termasuk komponen paket. Produk
+// yang sudah di bawah min_stock sebelum transaksi tidak diperingatkan lagi.
+func raiseLowStockAlerts(tx execer, outletID, transactionID int) ([]models.StockAlert, error) {
+	_, err := tx.Exec(`
+		INSERT INTO stock_alerts (outlet_id, product_id, stock, min_stock, transaction_id)
+		SELECT $1, p.id, os.stock, p.min_stock, $2
+		FROM (
+			SELECT product_id, SUM(quantity) AS quantity FROM stock_movements
+			WHERE reference_type = 'transaction' AND reference_id = $2
+			GROUP BY product_id
+		) m
+		JOIN products p ON p.id = m.product_id
+		JOIN outlet_stocks os ON os.outlet_id = $1 AND os.product_id = p.id
+		WHERE p.min_stock > 0 AND os.stock < p.min_stock AND os.stock - m.quantity >= p.min_stock
+	`, outletID, transactionID)
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := tx.Query("SELECT"+stockAlertColumns+stockAlertFrom+"WHERE a.transaction_id = $1 ORDER BY a.id", transactionID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var alerts []models.StockAlert
+	for rows.Next() {
+		a, err := scanStockAlert(rows)
+		if err != nil {
+			return nil, err
+		}
+		alerts = append(alerts, *a)
+	}
+
+	return alerts, rows.Err()
+}
+
+const stockAlertColumns = `
+	a.id, a.outlet_id, o.name, a.product_id, p.name, a.stock, a.min_stock, a.transaction_id, a.status,
+	a.purchase_order_id, a.created_at, a.resolved_at
+`
+
+const stockAlertFrom = `
+	FROM stock_alerts a
+	JOIN outlets o ON o.id = a.outlet_id
+	JOIN products p ON p.id = a.product_id
+`
+
+func scanStockAlert(row interface{ Scan(...interface{}) error }) (*models.StockAlert, error) {
+	var a models.StockAlert
+	var transactionID, purchaseOrderID sql.NullInt64
+	var resolvedAt sql.NullTime
+
+	err := row.Scan(&a.ID, &a.OutletID, &a.OutletName, &a.ProductID, &a.ProductName, &a.Stock, &a.MinStock,
+		&transactionID, &a.Status, &purchaseOrderID, &a.CreatedAt, &resolvedAt)
+	if err != nil {
+		return nil, err
+	}
+	if transactionID.Valid {
+		v := int(transactionID.Int64)
+		a.TransactionID = &v
+	}
+	if purchaseOrderID.Valid {
+		v := int(purchaseOrderID.Int64)
+		a.PurchaseOrderID = &v
+	}
+	if resolvedAt.Valid {
+		a.ResolvedAt = &resolvedAt.Time
+	}
+
+	return &a, nil
+}
+
+func (repo *ReorderRepository) GetAlerts(filter models.StockAlertFilter) ([]models.StockAlert, error) {
+	conditions := []string{}
+	args := []interface{}{}
+
+	if filter.Status != "" {
+		args = append(args, filter.Status)
+		conditions = append(conditions, fmt.Sprintf("a.status = $%d", len(args)))
+	}
+	if filter.OutletID != 0 {
+		args = append(args, filter.OutletID)
+		conditions = append(conditions, fmt.Sprintf("a.outlet_id = $%d", len(args)))
+	}
+
+	query := "SELECT" + stockAlertColumns + stockAlertFrom
+	if len(conditions) > 0 {
+		query += " WHERE " + strings.Join(conditions, " AND ")
+	}
+	query += " ORDER BY a.created_at DESC, a.id DESC"
+
+	rows, err := repo.db.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	alerts := make([]models.StockAlert, 0)
+	for rows.Next() {
+		a, err := scanStockAlert(rows)
+		if err != nil {
+			return nil, err
+		}
+		alerts = append(alerts, *a)
+	}
+
+	return alerts, rows.Err()
+}
+
+// CreateDraftPurchaseOrder mengisi draft dengan semua produk yang stoknya
+// rendah di outlet, kecuali yang sudah ada di draft lain, lalu menandai
+// peringatan yang terbuka untuk produk tersebut sebagai ordered. Mengembalikan
+// nil tanpa error jika tidak ada yang perlu dipesan.
+func (repo *ReorderRepository) CreateDraftPurchaseOrder(req models.DraftPurchaseOrderRequest, createdBy string) (*models.PurchaseOrder, error) {
+	tx, err := repo.db.Begin()
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	rows, err := tx.Query(lowStockQuery+`
+		AND NOT EXISTS (
+			SELECT 1 FROM purchase_order_items i
+			JOIN purchase_orders po ON po.id = i.purchase_order_id
+			WHERE po.outlet_id = o.id AND po.status = 'draft' AND i.product_id = p.id
+		)
+		ORDER BY p.name
+	`, req.OutletID)
+	if err != nil {
+		return nil, err
+	}
+	var items []models.LowStockItem
+	for rows.Next() {
+		i, err := scanLowStock(rows)
+		if err != nil {
+			rows.Close()
+			return nil, err
+		}
+		items = append(items, *i)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	if len(items) == 0 {
+		return nil, nil
+	}
+
+	var id int
+	err = tx.QueryRow(`
+		INSERT INTO purchase_orders (outlet_id, supplier, note, created_by)
+		VALUES ($1, NULLIF($2, ''), NULLIF($3, ''), $4) RETURNING id
+	`, req.OutletID, req.Supplier, req.Note, createdBy).Scan(&id)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, i := range items {
+		_, err := tx.Exec(`
+			INSERT INTO purchase_order_items (purchase_order_id, product_id, quantity, stock, min_stock)
+			VALUES ($1, $2, $3, $4, $5)
+		`, id, i.ProductID, i.SuggestedQty, i.Stock, i.MinStock)
+		if err != nil {
+			return nil, err
+		}
+
+		_, err = tx.Exec(`
+			UPDATE stock_alerts SET status = 'ordered', purchase_order_id = $1
+			WHERE outlet_id = $2 AND product_id = $3 AND status = 'open'
+		`, id, req.OutletID, i.ProductID)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+
+	return repo.GetPurchaseOrder(id)
+}
+
+const purchaseOrderColumns = `
+	po.id, po.outlet_id, o.name, COALESCE(po.supplier, ''), po.status, COALESCE(po.note, ''), po.created_by, po.created_at
+`
+
+const purchaseOrderFrom = `
+	FROM purchase_orders po
+	JOIN outlets o ON o.id = po.outlet_id
+`
+
+func scanPurchaseOrder(row interface{ Scan(...interface{}) error }) (*models.PurchaseOrder, error) {
+	var po models.PurchaseOrder
+	err := row.Scan(&po.ID, &po.OutletID, &po.OutletName, &po.Supplier, &po.Status, &po.Note, &po.CreatedBy, &po.CreatedAt)
+	if err != nil {
+		return nil, err
+	}
+	return &po, nil
+}
+
+func (repo *ReorderRepository) GetPurchaseOrder(id int) (*models.PurchaseOrder, error) {
+	po, err := scanPurchaseOrder(repo.db.QueryRow("SELECT"+purchaseOrderColumns+purchaseOrderFrom+"WHERE po.id = $1", id))
+	if err == sql.ErrNoRows {
+		return nil, errors.New("purchase order tidak ditemukan")
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := repo.db.Query(`
+		SELECT i.id, i.product_id, p.name, p.unit, i.quantity, i.stock, i.min_stock
+		FROM purchase_order_items i
+		JOIN products p ON p.id = i.product_id
+		WHERE i.purchase_order_id = $1
+		ORDER BY p.name
+	`, id)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	po.Items = make([]models.PurchaseOrderItem, 0)
+	for rows.Next() {
+		var i models.PurchaseOrderItem
+		if err := rows.Scan(&i.ID, &i.ProductID, &i.ProductName, &i.Unit, &i.Quantity, &i.Stock, &i.MinStock); err != nil {
+			return nil, err
+		}
+		po.Items = append(po.Items, i)
+	}
+
+	return po, rows.Err()
+}
+
+// GetPurchaseOrders tanpa item, outletID 0 berarti semua outlet
+func (repo *ReorderRepository) GetPurchaseOrders(outletID int) ([]models.PurchaseOrder, error) {
+	rows, err := repo.db.Query(
+		"SELECT"+purchaseOrderColumns+purchaseOrderFrom+"WHERE ($1 = 0 OR po.outlet_id = $1) ORDER BY po.created_at DESC, po.id DESC",
+		outletID,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	orders := make([]models.PurchaseOrder, 0)
+	for rows.Next() {
+		po, err := scanPurchaseOrder(rows)
+		if err != nil {
+			return nil, err
+		}
+		orders = append(orders, *po)
+	}
+
+	return orders, rows.Err()
+}
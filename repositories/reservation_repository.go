@@ -0,0 +1,404 @@
+package repositories
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/anggakrnwn/kasir-api/models"
+)
+
+type ReservationRepository struct {
+	db *sql.DB
+}
+
+func NewReservationRepository(db *sql.DB) *ReservationRepository {
+	return &ReservationRepository{db: db}
+}
+
+// reservedStockSQL menjumlahkan reservasi aktif produk p di semua outlet.
+// Reservasi yang sudah lewat expires_at tidak dihitung walaupun sweeper belum
+// mengubah statusnya.
+const reservedStockSQL = `COALESCE((
+	SELECT SUM(ri.quantity) FROM stock_reservation_items ri
+	JOIN stock_reservations r ON r.id = ri.reservation_id
+	WHERE ri.product_id = p.id AND r.status = 'active' AND r.expires_at > NOW()
+), 0)`
+
+// reservedStock adalah stok outlet yang ditahan reservasi aktif selain
+// exceptID (reservasi milik transaksi yang sedang diproses)
+func reservedStock(q queryer, outletID, productID, exceptID int) (float64, error) {
+	var reserved float64
+	err := q.QueryRow(`
+		SELECT COALESCE(SUM(i.quantity), 0) FROM stock_reservation_items i
+		JOIN stock_reservations r ON r.id = i.reservation_id
+		WHERE r.outlet_id = $1 AND i.product_id = $2 AND r.id <> $3
+			AND r.status = 'active' AND r.expires_at > NOW()
+	`, outletID, productID, exceptID).Scan(&reserved)
+	return reserved, err
+}
+
+// reservationQuantities mengubah item checkout menjadi jumlah satuan dasar per
+// produk yang punya stok. Paket diurai ke komponennya, stiker timbangan
+// berharga dihitung balik dari harga saat ini seperti saat checkout.
+func reservationQuantities(q queryer, items []models.CheckoutItem) (map[int]float64, error) {
+	quantities := make(map[int]float64)
+	for _, item := range items {
+		var productName string
+		var price int
+		var isBundle, hasVariants bool
+		err := q.QueryRow(`
+			SELECT p.name, `+currentPriceSQL+`, p.is_bundle, EXISTS (SELECT 1 FROM products v WHERE v.parent_id = p.id)
+			FROM products p WHERE p.id = $1
+		`, item.ProductID).Scan(&productName, &price, &isBundle, &hasVariants)
+		if err == sql.ErrNoRows {
+			return nil, fmt.Errorf("product id %d not found", item.ProductID)
+		}
+		if err != nil {
+			return nil, err
+		}
+		if hasVariants {
+			return nil, fmt.Errorf("product '%s' has variants, reservation must use a variant id", productName)
+		}
+
+		unit, err := resolveUnit(q, item.ProductID, item.Unit)
+		if err != nil {
+			return nil, err
+		}
+		unitQuantity := item.Quantity
+		if item.EmbeddedPrice > 0 && price > 0 {
+			unitQuantity = roundQuantity(float64(item.EmbeddedPrice) / float64(price*unit.Factor))
+		}
+		quantity := roundQuantity(unitQuantity * float64(unit.Factor))
+
+		if !isBundle {
+			quantities[item.ProductID] += quantity
+			continue
+		}
+
+		rows, err := q.Query("SELECT component_id, quantity FROM bundle_components WHERE bundle_id = $1", item.ProductID)
+		if err != nil {
+			return nil, err
+		}
+		for rows.Next() {
+			var componentID int
+			var perBundle float64
+			if err := rows.Scan(&componentID, &perBundle); err != nil {
+				rows.Close()
+				return nil, err
+			}
+			quantities[componentID] += roundQuantity(perBundle * quantity)
+		}
+		rows.Close()
+		if err := rows.Err(); err != nil {
+			return nil, err
+		}
+	}
+
+	return quantities, nil
+}
+
+// reserveStock mengganti isi reservasi dengan quantities. Produk dikunci
+// berurutan seperti saat checkout supaya reservasi dan penjualan tidak saling
+// mendahului. Jika stok tersedia kurang, produk yang strict menghasilkan
+// error, produk lain hanya ditahan sebanyak yang tersedia.
+func reserveStock(tx execer, reservationID, outletID int, quantities map[int]float64, strict map[int]float64) error {
+	if _, err := tx.Exec("DELETE FROM stock_reservation_items WHERE reservation_id = $1", reservationID); err != nil {
+		return err
+	}
+
+	productIDs := make([]int, 0, len(quantities))
+	for productID := range quantities {
+		productIDs = append(productIDs, productID)
+	}
+	sort.Ints(productIDs)
+
+	for _, productID := range productIDs {
+		var productName string
+		if err := tx.QueryRow("SELECT name FROM products WHERE id = $1 FOR UPDATE", productID).Scan(&productName); err != nil {
+			return err
+		}
+		stock, err := outletStock(tx, outletID, productID)
+		if err != nil {
+			return err
+		}
+		reserved, err := reservedStock(tx, outletID, productID, reservationID)
+		if err != nil {
+			return err
+		}
+
+		quantity := roundQuantity(quantities[productID])
+		available := roundQuantity(stock - reserved)
+		if quantity > available {
+			if _, ok := strict[productID]; ok {
+				return fmt.Errorf("insufficient stock for product '%s'. Available: %g, Requested: %g",
+					productName, available, quantity)
+			}
+			quantity = available
+		}
+		if quantity <= 0 {
+			continue
+		}
+
+		_, err = tx.Exec(
+			"INSERT INTO stock_reservation_items (reservation_id, product_id, quantity) VALUES ($1, $2, $3)",
+			reservationID, productID, quantity,
+		)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// syncCartReservation menyamakan reservasi keranjang dengan isi keranjang dan
+// memperpanjang masa berlakunya. strict berisi produk dari item yang baru
+// ditambahkan, yang harus bisa ditahan penuh.
+func syncCartReservation(tx *sql.Tx, cartID int, ttl time.Duration, strict map[int]float64) error {
+	var reservationID, outletID int
+	err := tx.QueryRow(`
+		INSERT INTO stock_reservations (outlet_id, cart_id, created_by, expires_at)
+		SELECT c.outlet_id, c.id, k.name, NOW() + make_interval(secs => $2)
+		FROM carts c JOIN cashiers k ON k.id = c.cashier_id WHERE c.id = $1
+		ON CONFLICT (cart_id) DO UPDATE SET status = 'active', expires_at = EXCLUDED.expires_at, released_at = NULL
+		RETURNING id, outlet_id
+	`, cartID, ttl.Seconds()).Scan(&reservationID, &outletID)
+	if err != nil {
+		return err
+	}
+
+	rows, err := tx.Query("SELECT product_id, quantity, unit, embedded_price FROM cart_items WHERE cart_id = $1", cartID)
+	if err != nil {
+		return err
+	}
+	items := make([]models.CheckoutItem, 0)
+	for rows.Next() {
+		var item models.CheckoutItem
+		if err := rows.Scan(&item.ProductID, &item.Quantity, &item.Unit, &item.EmbeddedPrice); err != nil {
+			rows.Close()
+			return err
+		}
+		items = append(items, item)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	quantities, err := reservationQuantities(tx, items)
+	if err != nil {
+		return err
+	}
+
+	return reserveStock(tx, reservationID, outletID, quantities, strict)
+}
+
+// releaseCartReservation melepas reservasi keranjang yang dibatalkan
+func releaseCartReservation(tx execer, cartID int) error {
+	_, err := tx.Exec(
+		"UPDATE stock_reservations SET status = 'released', released_at = NOW() WHERE cart_id = $1 AND status = 'active'",
+		cartID,
+	)
+	return err
+}
+
+// lockCheckoutReservation mengunci reservasi yang akan diselesaikan checkout.
+// Reservasi yang sudah kedaluwarsa tetap boleh dipakai, hanya stoknya tidak
+// lagi ditahan.
+func lockCheckoutReservation(tx *sql.Tx, id, outletID int) error {
+	var status string
+	var reservationOutletID int
+	var cartID sql.NullInt64
+	err := tx.QueryRow("SELECT status, outlet_id, cart_id FROM stock_reservations WHERE id = $1 FOR UPDATE", id).
+		Scan(&status, &reservationOutletID, &cartID)
+	if err == sql.ErrNoRows {
+		return fmt.Errorf("%w: id %d", ErrReservationNotFound, id)
+	}
+	if err != nil {
+		return err
+	}
+	if status != models.ReservationStatusActive && status != models.ReservationStatusExpired {
+		return fmt.Errorf("%w: reservation %d is already %s", ErrReservationNotActive, id, status)
+	}
+	if reservationOutletID != outletID {
+		return fmt.Errorf("%w: reservation %d belongs to another outlet", ErrReservationNotActive, id)
+	}
+	if cartID.Valid {
+		return fmt.Errorf("%w: reservation %d belongs to cart %d, use the cart checkout", ErrReservationNotActive, id, cartID.Int64)
+	}
+	return nil
+}
+
+// Create menahan stok untuk pesanan online. Semua item harus bisa ditahan penuh.
+func (repo *ReservationRepository) Create(req models.StockReservationRequest, createdBy string, ttl time.Duration) (*models.StockReservation, error) {
+	tx, err := repo.db.Begin()
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	var id int
+	err = tx.QueryRow(`
+		INSERT INTO stock_reservations (outlet_id, reference, created_by, expires_at)
+		VALUES ($1, NULLIF($2, ''), $3, NOW() + make_interval(secs => $4))
+		RETURNING id
+	`, req.OutletID, req.Reference, createdBy, ttl.Seconds()).Scan(&id)
+	if err != nil {
+		return nil, err
+	}
+
+	quantities, err := reservationQuantities(tx, req.Items)
+	if err != nil {
+		return nil, err
+	}
+	if err := reserveStock(tx, id, req.OutletID, quantities, quantities); err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+
+	return repo.GetByID(id)
+}
+
+func (repo *ReservationRepository) Release(id int) (*models.StockReservation, error) {
+	result, err := repo.db.Exec(
+		"UPDATE stock_reservations SET status = 'released', released_at = NOW() WHERE id = $1 AND status = 'active'",
+		id,
+	)
+	if err != nil {
+		return nil, err
+	}
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return nil, err
+	}
+
+	reservation, err := repo.GetByID(id)
+	if err != nil {
+		return nil, err
+	}
+	if affected == 0 {
+		return nil, fmt.Errorf("reservation %d is already %s", id, reservation.Status)
+	}
+
+	return reservation, nil
+}
+
+// ExpireDue menandai reservasi aktif yang sudah lewat expires_at sebagai
+// expired dan mengembalikan jumlahnya
+func (repo *ReservationRepository) ExpireDue() (int64, error) {
+	result, err := repo.db.Exec(
+		"UPDATE stock_reservations SET status = 'expired', released_at = expires_at WHERE status = 'active' AND expires_at <= NOW()",
+	)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+const reservationColumns = `
+	r.id, r.outlet_id, r.cart_id, COALESCE(r.reference, ''), r.status, r.created_by, r.transaction_id,
+	r.expires_at, r.created_at, r.released_at
+`
+
+func scanReservation(row interface{ Scan(...interface{}) error }) (*models.StockReservation, error) {
+	var r models.StockReservation
+	var cartID, transactionID sql.NullInt64
+	var releasedAt sql.NullTime
+
+	err := row.Scan(&r.ID, &r.OutletID, &cartID, &r.Reference, &r.Status, &r.CreatedBy, &transactionID,
+		&r.ExpiresAt, &r.CreatedAt, &releasedAt)
+	if err != nil {
+		return nil, err
+	}
+	if cartID.Valid {
+		v := int(cartID.Int64)
+		r.CartID = &v
+	}
+	if transactionID.Valid {
+		v := int(transactionID.Int64)
+		r.TransactionID = &v
+	}
+	if releasedAt.Valid {
+		r.ReleasedAt = &releasedAt.Time
+	}
+
+	return &r, nil
+}
+
+func (repo *ReservationRepository) GetByID(id int) (*models.StockReservation, error) {
+	reservation, err := scanReservation(repo.db.QueryRow("SELECT"+reservationColumns+"FROM stock_reservations r WHERE r.id = $1", id))
+	if err == sql.ErrNoRows {
+		return nil, errors.New("reservasi stok tidak ditemukan")
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := repo.db.Query(`
+		SELECT i.product_id, p.name, i.quantity
+		FROM stock_reservation_items i
+		JOIN products p ON p.id = i.product_id
+		WHERE i.reservation_id = $1
+		ORDER BY p.name, i.product_id
+	`, id)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	reservation.Items = make([]models.StockReservationItem, 0)
+	for rows.Next() {
+		var i models.StockReservationItem
+		if err := rows.Scan(&i.ProductID, &i.ProductName, &i.Quantity); err != nil {
+			return nil, err
+		}
+		reservation.Items = append(reservation.Items, i)
+	}
+
+	return reservation, rows.Err()
+}
+
+// GetAll tanpa item, terbaru lebih dulu
+func (repo *ReservationRepository) GetAll(filter models.StockReservationFilter) ([]models.StockReservation, error) {
+	conditions := []string{}
+	args := []interface{}{}
+
+	if filter.Status != "" {
+		args = append(args, filter.Status)
+		conditions = append(conditions, fmt.Sprintf("r.status = $%d", len(args)))
+	}
+	if filter.OutletID != 0 {
+		args = append(args, filter.OutletID)
+		conditions = append(conditions, fmt.Sprintf("r.outlet_id = $%d", len(args)))
+	}
+
+	query := "SELECT" + reservationColumns + "FROM stock_reservations r"
+	if len(conditions) > 0 {
+		query += " WHERE " + strings.Join(conditions, " AND ")
+	}
+	query += " ORDER BY r.created_at DESC, r.id DESC"
+
+	rows, err := repo.db.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	reservations := make([]models.StockReservation, 0)
+	for rows.Next() {
+		r, err := scanReservation(rows)
+		if err != nil {
+			return nil, err
+		}
+		reservations = append(reservations, *r)
+	}
+
+	return reservations, rows.Err()
+}
@@ -0,0 +1,252 @@
+package repositories
+
+import (
+	"database/sql"
+	"errors"
+
+	"github.com/anggakrnwn/kasir-api/models"
+)
+
+// queryer dipenuhi oleh *sql.DB maupun *sql.Tx, supaya perhitungan yang sama
+// bisa dipakai di dalam dan di luar transaksi
+type queryer interface {
+	Query(query string, args ...interface{}) (*sql.Rows, error)
+	QueryRow(query string, args ...interface{}) *sql.Row
+}
+
+// ErrShiftClosed dikembalikan saat shift sudah ditutup lebih dulu, mis. kasir
+// menutup shift bersamaan dengan owner
+var ErrShiftClosed = errors.New("shift is already closed")
+
+type ShiftRepository struct {
+	db *sql.DB
+}
+
+func NewShiftRepository(db *sql.DB) *ShiftRepository {
+	return &ShiftRepository{db: db}
+}
+
+const shiftColumns = `
+	s.id, s.cashier_id, c.name, s.outlet_id, s.status, s.opening_float, s.expected_cash,
+	s.counted_cash, s.difference, COALESCE(s.note, ''), s.opened_at, s.closed_at
+`
+
+func scanShift(row interface{ Scan(...interface{}) error }) (*models.Shift, error) {
+	var s models.Shift
+	var expected, counted, difference sql.NullInt64
+	var closedAt sql.NullTime
+
+	err := row.Scan(&s.ID, &s.CashierID, &s.CashierName, &s.OutletID, &s.Status, &s.OpeningFloat,
+		&expected, &counted, &difference, &s.Note, &s.OpenedAt, &closedAt)
+	if err != nil {
+		return nil, err
+	}
+
+	if expected.Valid {
+		v := int(expected.Int64)
+		s.ExpectedCash = &v
+	}
+	if counted.Valid {
+		v := int(counted.Int64)
+		s.CountedCash = &v
+	}
+	if difference.Valid {
+		v := int(difference.Int64)
+		s.Difference = &v
+	}
+	if closedAt.Valid {
+		s.ClosedAt = &closedAt.Time
+	}
+
+	return &s, nil
+}
+
+// GetOpenByCashier mengembalikan nil tanpa error jika kasir belum membuka shift
+func (repo *ShiftRepository) GetOpenByCashier(cashierID int) (*models.Shift, error) {
+	query := "SELECT" + shiftColumns + "FROM shifts s JOIN cashiers c ON c.id = s.cashier_id WHERE s.cashier_id = $1 AND s.status = 'open'"
+
+	shift, err := scanShift(repo.db.QueryRow(query, cashierID))
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return shift, nil
+}
+
+func (repo *ShiftRepository) GetByID(id int) (*models.Shift, error) {
+	query := "SELECT" + shiftColumns + "FROM shifts s JOIN cashiers c ON c.id = s.cashier_id WHERE s.id = $1"
+
+	shift, err := scanShift(repo.db.QueryRow(query, id))
+	if err == sql.ErrNoRows {
+		return nil, errors.New("shift tidak ditemukan")
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return shift, nil
+}
+
+// Open membuka shift di outlet tempat kasir bertugas. false tanpa error
+// berarti kasir sudah punya shift terbuka (unique index idx_shifts_open_cashier),
+// termasuk saat dua request open datang bersamaan.
+func (repo *ShiftRepository) Open(shift *models.Shift) (bool, error) {
+	query := `
+		INSERT INTO shifts (cashier_id, outlet_id, status, opening_float)
+		SELECT id, outlet_id, 'open', $2 FROM cashiers WHERE id = $1
+		ON CONFLICT (cashier_id) WHERE status = 'open' DO NOTHING
+		RETURNING id, outlet_id, status, opened_at
+	`
+	err := repo.db.QueryRow(query, shift.CashierID, shift.OpeningFloat).Scan(&shift.ID, &shift.OutletID, &shift.Status, &shift.OpenedAt)
+	if err == sql.ErrNoRows {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+
+	return true, nil
+}
+
+// Close mengunci baris shift supaya tidak ada checkout yang masuk selama kas dihitung
+func (repo *ShiftRepository) Close(id int, countedCash int, note string) error {
+	tx, err := repo.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	var status string
+	var openingFloat int
+	err = tx.QueryRow("SELECT status, opening_float FROM shifts WHERE id = $1 FOR UPDATE", id).Scan(&status, &openingFloat)
+	if err == sql.ErrNoRows {
+		return errors.New("shift tidak ditemukan")
+	}
+	if err != nil {
+		return err
+	}
+
+	if status != models.ShiftStatusOpen {
+		return ErrShiftClosed
+	}
+
+	expectedCash, err := shiftExpectedCash(tx, id, openingFloat)
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.Exec(`
+		UPDATE shifts
+		SET status = 'closed', expected_cash = $1, counted_cash = $2, difference = $3, note = $4, closed_at = NOW()
+		WHERE id = $5
+	`, expectedCash, countedCash, countedCash-expectedCash, note, id)
+	if err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+func (repo *ShiftRepository) GetReport(id int) (*models.ShiftReport, error) {
+	shift, err := repo.GetByID(id)
+	if err != nil {
+		return nil, err
+	}
+
+	report := models.ShiftReport{
+		Shift:         *shift,
+		Payments:      make([]models.PaymentTotal, 0),
+		CashMovements: make([]models.CashMovement, 0),
+		CountedCash:   shift.CountedCash,
+		Difference:    shift.Difference,
+	}
+
+	rows, err := repo.db.Query(`
+		SELECT payment_method, COALESCE(SUM(total_amount), 0), COUNT(id)
+		FROM transactions
+		WHERE shift_id = $1
+		GROUP BY payment_method
+		ORDER BY payment_method
+	`, id)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var p models.PaymentTotal
+		if err := rows.Scan(&p.Method, &p.TotalAmount, &p.TotalTransactions); err != nil {
+			return nil, err
+		}
+		report.Payments = append(report.Payments, p)
+		report.TotalRevenue += p.TotalAmount
+		report.TotalTransactions += p.TotalTransactions
+		if p.Method == models.PaymentMethodCash {
+			report.CashSales = p.TotalAmount
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	report.PayIns, report.PayOuts, err = shiftCashMovementTotals(repo.db, id)
+	if err != nil {
+		return nil, err
+	}
+
+	// shift yang sudah ditutup memakai angka yang dibekukan saat penutupan
+	if shift.ExpectedCash != nil {
+		report.ExpectedCash = *shift.ExpectedCash
+	} else {
+		report.ExpectedCash, err = shiftExpectedCash(repo.db, id, shift.OpeningFloat)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if report.Difference != nil {
+		switch {
+		case *report.Difference > 0:
+			report.CashStatus = "over"
+		case *report.Difference < 0:
+			report.CashStatus = "short"
+		default:
+			report.CashStatus = "balanced"
+		}
+	}
+
+	return &report, nil
+}
+
+// kas yang seharusnya ada di laci: modal awal + penjualan tunai + pay-in - pay-out
+func shiftExpectedCash(q queryer, shiftID, openingFloat int) (int, error) {
+	var cashSales int
+	err := q.QueryRow(
+		"SELECT COALESCE(SUM(total_amount), 0) FROM transactions WHERE shift_id = $1 AND payment_method = 'cash'",
+		shiftID,
+	).Scan(&cashSales)
+	if err != nil {
+		return 0, err
+	}
+
+	payIns, payOuts, err := shiftCashMovementTotals(q, shiftID)
+	if err != nil {
+		return 0, err
+	}
+
+	return openingFloat + cashSales + payIns - payOuts, nil
+}
+
+func shiftCashMovementTotals(q queryer, shiftID int) (payIns, payOuts int, err error) {
+	err = q.QueryRow(`
+		SELECT
+			COALESCE(SUM(amount) FILTER (WHERE type = 'pay_in'), 0),
+			COALESCE(SUM(amount) FILTER (WHERE type = 'pay_out'), 0)
+		FROM cash_movements
+		WHERE shift_id = $1
+	`, shiftID).Scan(&payIns, &payOuts)
+	return payIns, payOuts, err
+}
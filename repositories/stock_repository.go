@@ -0,0 +1,444 @@
+package repositories
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/anggakrnwn/kasir-api/models"
+)
+
+// execer dipenuhi oleh *sql.DB maupun *sql.Tx
+type execer interface {
+	queryer
+	Exec(query string, args ...interface{}) (sql.Result, error)
+}
+
+type StockRepository struct {
+	db *sql.DB
+}
+
+func NewStockRepository(db *sql.DB) *StockRepository {
+	return &StockRepository{db: db}
+}
+
+// CreateReceipt menambah stok dari penerimaan barang. Setiap item dikonversi
+// ke satuan dasar dan dicatat di stock ledger dalam satu transaksi.
+func (repo *StockRepository) CreateReceipt(req models.StockReceiptRequest) (*models.StockReceipt, error) {
+	tx, err := repo.db.Begin()
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	receipt := models.StockReceipt{
+		OutletID: req.OutletID,
+		Supplier: req.Supplier,
+		Note:     req.Note,
+		Items:    make([]models.StockReceiptItem, 0, len(req.Items)),
+	}
+
+	err = tx.QueryRow(
+		"INSERT INTO stock_receipts (outlet_id, supplier, note) VALUES ($1, NULLIF($2, ''), NULLIF($3, '')) RETURNING id, created_at",
+		req.OutletID, req.Supplier, req.Note,
+	).Scan(&receipt.ID, &receipt.CreatedAt)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, item := range req.Items {
+		var productName string
+		var isWeighed, isBundle bool
+		err := tx.QueryRow("SELECT name, is_weighed, is_bundle FROM products WHERE id = $1 FOR UPDATE", item.ProductID).Scan(&productName, &isWeighed, &isBundle)
+		if err == sql.ErrNoRows {
+			return nil, fmt.Errorf("product id %d not found", item.ProductID)
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		// paket tidak punya stok sendiri, yang diterima adalah komponennya
+		if isBundle {
+			return nil, fmt.Errorf("product '%s' is a bundle, receive its components instead", productName)
+		}
+
+		if !isWeighed && !isWholeQuantity(item.Quantity) {
+			return nil, fmt.Errorf("product '%s' is not weighed, quantity must be a whole number", productName)
+		}
+
+		unit, err := resolveUnit(tx, item.ProductID, item.Unit)
+		if err != nil {
+			return nil, err
+		}
+
+		line := models.StockReceiptItem{
+			ReceiptID:    receipt.ID,
+			ProductID:    item.ProductID,
+			ProductName:  productName,
+			Unit:         unit.Name,
+			UnitQuantity: item.Quantity,
+			Factor:       unit.Factor,
+			Quantity:     roundQuantity(item.Quantity * float64(unit.Factor)),
+		}
+
+		// harga beli menjadi harga pokok terbaru produk per satuan dasar
+		var costPerBase *float64
+		if item.UnitCost > 0 {
+			cost := float64(item.UnitCost) / float64(unit.Factor)
+			line.UnitCost = &item.UnitCost
+			costPerBase = &cost
+
+			if _, err := tx.Exec("UPDATE products SET cost = $1 WHERE id = $2", cost, item.ProductID); err != nil {
+				return nil, err
+			}
+		}
+
+		err = tx.QueryRow(`
+			INSERT INTO stock_receipt_items (receipt_id, product_id, unit, unit_quantity, factor, quantity, unit_cost)
+			VALUES ($1, $2, $3, $4, $5, $6, $7) RETURNING id
+		`, line.ReceiptID, line.ProductID, line.Unit, line.UnitQuantity, line.Factor, line.Quantity, line.UnitCost).Scan(&line.ID)
+		if err != nil {
+			return nil, err
+		}
+
+		// setiap item penerimaan menjadi satu lot, walaupun tanpa nomor lot
+		// atau tanggal kedaluwarsa
+		var lotID int
+		var expiryDate sql.NullTime
+		err = tx.QueryRow(`
+			INSERT INTO stock_lots (outlet_id, product_id, receipt_item_id, lot_number, expiry_date, quantity, remaining)
+			VALUES ($1, $2, $3, NULLIF($4, ''), NULLIF($5, '')::date, $6, $6) RETURNING id, expiry_date
+		`, req.OutletID, line.ProductID, line.ID, item.LotNumber, item.ExpiryDate, line.Quantity).Scan(&lotID, &expiryDate)
+		if err != nil {
+			return nil, err
+		}
+		line.LotID = &lotID
+		line.LotNumber = item.LotNumber
+		line.ExpiryDate = formatDate(expiryDate)
+
+		err = applyStockMovement(tx, &models.StockMovement{
+			OutletID:      req.OutletID,
+			ProductID:     line.ProductID,
+			Quantity:      line.Quantity,
+			Unit:          line.Unit,
+			UnitQuantity:  line.UnitQuantity,
+			Factor:        line.Factor,
+			Reason:        models.StockReasonReceipt,
+			ReferenceType: "stock_receipt",
+			ReferenceID:   &receipt.ID,
+			UnitCost:      costPerBase,
+		})
+		if err != nil {
+			return nil, err
+		}
+
+		receipt.Items = append(receipt.Items, line)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+
+	return &receipt, nil
+}
+
+func (repo *StockRepository) GetReceipt(id int) (*models.StockReceipt, error) {
+	var receipt models.StockReceipt
+	err := repo.db.QueryRow(
+		"SELECT id, outlet_id, COALESCE(supplier, ''), COALESCE(note, ''), created_at FROM stock_receipts WHERE id = $1", id,
+	).Scan(&receipt.ID, &receipt.OutletID, &receipt.Supplier, &receipt.Note, &receipt.CreatedAt)
+	if err == sql.ErrNoRows {
+		return nil, errors.New("penerimaan barang tidak ditemukan")
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := repo.db.Query(`
+		SELECT i.id, i.receipt_id, i.product_id, p.name, i.unit, i.unit_quantity, i.factor, i.quantity, i.unit_cost,
+			l.id, COALESCE(l.lot_number, ''), l.expiry_date
+		FROM stock_receipt_items i
+		JOIN products p ON p.id = i.product_id
+		LEFT JOIN stock_lots l ON l.receipt_item_id = i.id
+		WHERE i.receipt_id = $1
+		ORDER BY i.id
+	`, id)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	receipt.Items = make([]models.StockReceiptItem, 0)
+	for rows.Next() {
+		var i models.StockReceiptItem
+		var unitCost, lotID sql.NullInt64
+		var expiryDate sql.NullTime
+		err := rows.Scan(&i.ID, &i.ReceiptID, &i.ProductID, &i.ProductName, &i.Unit, &i.UnitQuantity, &i.Factor, &i.Quantity,
+			&unitCost, &lotID, &i.LotNumber, &expiryDate)
+		if err != nil {
+			return nil, err
+		}
+		if unitCost.Valid {
+			v := int(unitCost.Int64)
+			i.UnitCost = &v
+		}
+		if lotID.Valid {
+			v := int(lotID.Int64)
+			i.LotID = &v
+		}
+		i.ExpiryDate = formatDate(expiryDate)
+		receipt.Items = append(receipt.Items, i)
+	}
+
+	return &receipt, rows.Err()
+}
+
+func (repo *StockRepository) GetMovements(filter models.StockMovementFilter) ([]models.StockMovement, error) {
+	conditions := []string{}
+	args := []interface{}{}
+
+	if filter.OutletID != 0 {
+		args = append(args, filter.OutletID)
+		conditions = append(conditions, fmt.Sprintf("m.outlet_id = $%d", len(args)))
+	}
+	if filter.ProductID != 0 {
+		args = append(args, filter.ProductID)
+		conditions = append(conditions, fmt.Sprintf("m.product_id = $%d", len(args)))
+	}
+	if filter.Reason != "" {
+		args = append(args, filter.Reason)
+		conditions = append(conditions, fmt.Sprintf("m.reason = $%d", len(args)))
+	}
+
+	query := `
+		SELECT m.id, m.outlet_id, m.product_id, p.name, m.quantity, m.unit, m.unit_quantity, m.factor, m.reason,
+			COALESCE(m.reference_type, ''), m.reference_id, COALESCE(m.note, ''), m.created_at, m.unit_cost
+		FROM stock_movements m
+		JOIN products p ON p.id = m.product_id
+	`
+	if len(conditions) > 0 {
+		query += " WHERE " + strings.Join(conditions, " AND ")
+	}
+
+	args = append(args, filter.Limit, filter.Offset)
+	query += fmt.Sprintf(" ORDER BY m.created_at DESC, m.id DESC LIMIT $%d OFFSET $%d", len(args)-1, len(args))
+
+	rows, err := repo.db.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	movements := make([]models.StockMovement, 0)
+	for rows.Next() {
+		var m models.StockMovement
+		var referenceID sql.NullInt64
+		var unitCost sql.NullFloat64
+		err := rows.Scan(&m.ID, &m.OutletID, &m.ProductID, &m.ProductName, &m.Quantity, &m.Unit, &m.UnitQuantity, &m.Factor,
+			&m.Reason, &m.ReferenceType, &referenceID, &m.Note, &m.CreatedAt, &unitCost)
+		if err != nil {
+			return nil, err
+		}
+		if unitCost.Valid {
+			m.UnitCost = &unitCost.Float64
+		}
+		if referenceID.Valid {
+			v := int(referenceID.Int64)
+			m.ReferenceID = &v
+		}
+		movements = append(movements, m)
+	}
+
+	return movements, rows.Err()
+}
+
+// GetExpiringLots mengembalikan lot yang masih bersisa dan kedaluwarsa dalam
+// days hari ke depan dari today (YYYY-MM-DD di zona waktu toko), termasuk yang
+// sudah lewat tanggalnya. outletID 0 berarti semua outlet.
+func (repo *StockRepository) GetExpiringLots(today string, days, outletID int) ([]models.StockLot, error) {
+	rows, err := repo.db.Query(`
+		SELECT l.id, l.outlet_id, l.product_id, p.name, COALESCE(l.lot_number, ''), l.expiry_date, l.quantity, l.remaining,
+			l.expiry_date - $3::date, l.created_at
+		FROM stock_lots l
+		JOIN products p ON p.id = l.product_id
+		WHERE l.remaining > 0 AND l.expiry_date IS NOT NULL AND l.expiry_date <= $3::date + $1::int
+			AND ($2 = 0 OR l.outlet_id = $2)
+		ORDER BY l.expiry_date, p.name, l.id
+	`, days, outletID, today)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	lots := make([]models.StockLot, 0)
+	for rows.Next() {
+		var l models.StockLot
+		var expiryDate sql.NullTime
+		var daysToExpiry int
+		err := rows.Scan(&l.ID, &l.OutletID, &l.ProductID, &l.ProductName, &l.LotNumber, &expiryDate, &l.Quantity, &l.Remaining,
+			&daysToExpiry, &l.CreatedAt)
+		if err != nil {
+			return nil, err
+		}
+		l.ExpiryDate = formatDate(expiryDate)
+		l.DaysToExpiry = &daysToExpiry
+		lots = append(lots, l)
+	}
+
+	return lots, rows.Err()
+}
+
+// consumeLots mengurangi sisa lot di satu outlet first-expired-first-out
+// sebanyak quantity (satuan dasar). Lot tanpa tanggal kedaluwarsa dipakai
+// paling akhir. Stok di luar lot (stok awal, adjustment) tidak tercatat di
+// lot mana pun, jadi hasilnya bisa kurang dari quantity.
+func consumeLots(tx execer, outletID, productID int, quantity float64) ([]models.LotAllocation, error) {
+	rows, err := tx.Query(`
+		SELECT id, COALESCE(lot_number, ''), expiry_date, remaining
+		FROM stock_lots
+		WHERE outlet_id = $1 AND product_id = $2 AND remaining > 0
+		ORDER BY expiry_date NULLS LAST, id
+		FOR UPDATE
+	`, outletID, productID)
+	if err != nil {
+		return nil, err
+	}
+
+	type lot struct {
+		allocation models.LotAllocation
+		remaining  float64
+	}
+	var lots []lot
+	for rows.Next() {
+		var l lot
+		var expiryDate sql.NullTime
+		if err := rows.Scan(&l.allocation.LotID, &l.allocation.LotNumber, &expiryDate, &l.remaining); err != nil {
+			rows.Close()
+			return nil, err
+		}
+		l.allocation.ProductID = productID
+		l.allocation.ExpiryDate = formatDate(expiryDate)
+		lots = append(lots, l)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	allocations := make([]models.LotAllocation, 0)
+	left := roundQuantity(quantity)
+	for _, l := range lots {
+		if left <= 0 {
+			break
+		}
+
+		take := min(l.remaining, left)
+		if _, err := tx.Exec("UPDATE stock_lots SET remaining = remaining - $1 WHERE id = $2", take, l.allocation.LotID); err != nil {
+			return nil, err
+		}
+
+		l.allocation.Quantity = take
+		allocations = append(allocations, l.allocation)
+		left = roundQuantity(left - take)
+	}
+
+	return allocations, nil
+}
+
+// formatDate mengubah kolom DATE menjadi YYYY-MM-DD, nil jika kosong
+func formatDate(t sql.NullTime) *string {
+	if !t.Valid {
+		return nil
+	}
+	v := t.Time.Format("2006-01-02")
+	return &v
+}
+
+// applyStockMovement mengubah stok produk di outlet m.OutletID sebesar
+// m.Quantity (satuan dasar) beserta total products.stock, lalu mencatatnya di
+// stock ledger. Pemanggil sudah mengunci baris produk FOR UPDATE.
+func applyStockMovement(tx execer, m *models.StockMovement) error {
+	if m.OutletID == 0 {
+		m.OutletID = models.DefaultOutletID
+	}
+
+	_, err := tx.Exec(`
+		INSERT INTO outlet_stocks (outlet_id, product_id, stock) VALUES ($1, $2, $3)
+		ON CONFLICT (outlet_id, product_id) DO UPDATE SET stock = outlet_stocks.stock + EXCLUDED.stock, updated_at = NOW()
+	`, m.OutletID, m.ProductID, m.Quantity)
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.Exec("UPDATE products SET stock = stock + $1, updated_at = NOW() WHERE id = $2", m.Quantity, m.ProductID)
+	if err != nil {
+		return err
+	}
+
+	// peringatan stok rendah selesai begitu stok outlet kembali mencapai min_stock
+	if m.Quantity > 0 {
+		_, err = tx.Exec(`
+			UPDATE stock_alerts a SET status = 'resolved', resolved_at = NOW()
+			FROM outlet_stocks os, products p
+			WHERE a.outlet_id = $1 AND a.product_id = $2 AND a.status <> 'resolved'
+				AND os.outlet_id = a.outlet_id AND os.product_id = a.product_id
+				AND p.id = a.product_id AND os.stock >= p.min_stock
+		`, m.OutletID, m.ProductID)
+		if err != nil {
+			return err
+		}
+	}
+
+	if m.Factor == 0 {
+		m.Factor = 1
+	}
+	if m.UnitQuantity == 0 {
+		m.UnitQuantity = roundQuantity(m.Quantity / float64(m.Factor))
+	}
+	if m.Unit == "" {
+		if err := tx.QueryRow("SELECT unit FROM products WHERE id = $1", m.ProductID).Scan(&m.Unit); err != nil {
+			return err
+		}
+	}
+
+	return tx.QueryRow(`
+		INSERT INTO stock_movements (outlet_id, product_id, quantity, unit, unit_quantity, factor, reason, reference_type, reference_id, note, unit_cost)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, NULLIF($8, ''), $9, NULLIF($10, ''), $11)
+		RETURNING id, created_at
+	`, m.OutletID, m.ProductID, m.Quantity, m.Unit, m.UnitQuantity, m.Factor, m.Reason, m.ReferenceType, m.ReferenceID, m.Note, m.UnitCost,
+	).Scan(&m.ID, &m.CreatedAt)
+}
+
+// resolveUnit mencari satuan yang dipakai pada checkout/penerimaan. Nama kosong
+// atau sama dengan satuan dasar produk menghasilkan factor 1.
+func resolveUnit(q queryer, productID int, name string) (*models.ProductUnit, error) {
+	var baseUnit string
+	if err := q.QueryRow("SELECT unit FROM products WHERE id = $1", productID).Scan(&baseUnit); err != nil {
+		return nil, err
+	}
+
+	name = strings.TrimSpace(name)
+	if name == "" || strings.EqualFold(name, baseUnit) {
+		return &models.ProductUnit{ProductID: productID, Name: baseUnit, Factor: 1}, nil
+	}
+
+	var unit models.ProductUnit
+	var price sql.NullInt64
+	err := q.QueryRow(
+		"SELECT id, product_id, name, factor, price, created_at FROM product_units WHERE product_id = $1 AND LOWER(name) = LOWER($2)",
+		productID, name,
+	).Scan(&unit.ID, &unit.ProductID, &unit.Name, &unit.Factor, &price, &unit.CreatedAt)
+	if err == sql.ErrNoRows {
+		return nil, fmt.Errorf("%w: '%s' for product id %d", ErrUnknownUnit, name, productID)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if price.Valid {
+		v := int(price.Int64)
+		unit.Price = &v
+	}
+
+	return &unit, nil
+}
@@ -0,0 +1,382 @@
+package repositories
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"math"
+	"strings"
+
+	"github.com/anggakrnwn/kasir-api/models"
+)
+
+type StockTakeRepository struct {
+	db *sql.DB
+}
+
+func NewStockTakeRepository(db *sql.DB) *StockTakeRepository {
+	return &StockTakeRepository{db: db}
+}
+
+// Start membuka sesi opname dan men-snapshot stok sistem semua produk yang
+// punya stok sendiri (bukan paket, bukan induk varian) di outlet tersebut
+func (repo *StockTakeRepository) Start(req models.StockTakeRequest, startedBy string) (*models.StockTake, error) {
+	tx, err := repo.db.Begin()
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	var id int
+	err = tx.QueryRow(`
+		INSERT INTO stock_takes (outlet_id, note, started_by) VALUES ($1, NULLIF($2, ''), $3) RETURNING id
+	`, req.OutletID, req.Note, startedBy).Scan(&id)
+	if err != nil {
+		if strings.Contains(err.Error(), "idx_stock_takes_one_open_per_outlet") {
+			return nil, fmt.Errorf("outlet %d already has an open stock take", req.OutletID)
+		}
+		return nil, err
+	}
+
+	_, err = tx.Exec(`
+		INSERT INTO stock_take_items (stock_take_id, product_id, system_quantity)
+		SELECT $1, p.id, COALESCE(os.stock, 0)
+		FROM products p
+		LEFT JOIN outlet_stocks os ON os.product_id = p.id AND os.outlet_id = $2
+		WHERE NOT p.is_bundle AND NOT EXISTS (SELECT 1 FROM products v WHERE v.parent_id = p.id)
+	`, id, req.OutletID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+
+	return repo.GetByID(id)
+}
+
+// lockOpenStockTake mengunci sesi dan memastikan statusnya masih open
+func lockOpenStockTake(tx *sql.Tx, id int, lock string) (int, error) {
+	var outletID int
+	var status string
+	err := tx.QueryRow("SELECT outlet_id, status FROM stock_takes WHERE id = $1 "+lock, id).Scan(&outletID, &status)
+	if err == sql.ErrNoRows {
+		return 0, errors.New("stock opname tidak ditemukan")
+	}
+	if err != nil {
+		return 0, err
+	}
+	if status != models.StockTakeStatusOpen {
+		return 0, fmt.Errorf("stock take %d is already %s", id, status)
+	}
+	return outletID, nil
+}
+
+// SubmitCounts menyimpan hitungan satu perangkat. Produk yang belum ada di
+// snapshot (misalnya produk baru) ditambahkan dengan stok sistem saat ini.
+func (repo *StockTakeRepository) SubmitCounts(id int, req models.StockTakeCountRequest, countedBy string) (*models.StockTake, error) {
+	tx, err := repo.db.Begin()
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	outletID, err := lockOpenStockTake(tx, id, "FOR SHARE")
+	if err != nil {
+		return nil, err
+	}
+
+	for _, c := range req.Items {
+		var productName string
+		var isWeighed, isBundle, hasVariants bool
+		err := tx.QueryRow(`
+			SELECT p.name, p.is_weighed, p.is_bundle, EXISTS (SELECT 1 FROM products v WHERE v.parent_id = p.id)
+			FROM products p WHERE p.id = $1
+		`, c.ProductID).Scan(&productName, &isWeighed, &isBundle, &hasVariants)
+		if err == sql.ErrNoRows {
+			return nil, fmt.Errorf("product id %d not found", c.ProductID)
+		}
+		if err != nil {
+			return nil, err
+		}
+		if isBundle || hasVariants {
+			return nil, fmt.Errorf("product '%s' has no stock of its own and cannot be counted", productName)
+		}
+		if !isWeighed && !isWholeQuantity(c.Quantity) {
+			return nil, fmt.Errorf("product '%s' is not weighed, quantity must be a whole number", productName)
+		}
+
+		_, err = tx.Exec(`
+			INSERT INTO stock_take_items (stock_take_id, product_id, system_quantity)
+			SELECT $1, $2, COALESCE((SELECT stock FROM outlet_stocks WHERE outlet_id = $3 AND product_id = $2), 0)
+			ON CONFLICT (stock_take_id, product_id) DO NOTHING
+		`, id, c.ProductID, outletID)
+		if err != nil {
+			return nil, err
+		}
+
+		_, err = tx.Exec(`
+			INSERT INTO stock_take_counts (stock_take_id, product_id, device, quantity, counted_by)
+			VALUES ($1, $2, $3, $4, $5)
+			ON CONFLICT (stock_take_id, product_id, device)
+			DO UPDATE SET quantity = EXCLUDED.quantity, counted_by = EXCLUDED.counted_by, updated_at = NOW()
+		`, id, c.ProductID, req.Device, roundQuantity(c.Quantity), countedBy)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+
+	return repo.GetByID(id)
+}
+
+// Post menyesuaikan stok sebesar selisih hitung terhadap snapshot dalam satu
+// transaksi database. Selisih diterapkan ke stok saat ini supaya penjualan
+// selama opname tetap terhitung; produk yang belum dihitung tidak diubah.
+func (repo *StockTakeRepository) Post(id int, postedBy string) (*models.StockTake, error) {
+	tx, err := repo.db.Begin()
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	outletID, err := lockOpenStockTake(tx, id, "FOR UPDATE")
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := tx.Query(`
+		SELECT i.id, i.product_id, i.system_quantity, c.quantity
+		FROM stock_take_items i
+		JOIN (
+			SELECT product_id, SUM(quantity) AS quantity FROM stock_take_counts
+			WHERE stock_take_id = $1 GROUP BY product_id
+		) c ON c.product_id = i.product_id
+		WHERE i.stock_take_id = $1
+		ORDER BY i.product_id
+	`, id)
+	if err != nil {
+		return nil, err
+	}
+
+	type counted struct {
+		itemID, productID int
+		system, quantity  float64
+	}
+	var items []counted
+	for rows.Next() {
+		var c counted
+		if err := rows.Scan(&c.itemID, &c.productID, &c.system, &c.quantity); err != nil {
+			rows.Close()
+			return nil, err
+		}
+		items = append(items, c)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	for _, item := range items {
+		var unitValue int
+		var stock float64
+		err := tx.QueryRow(`
+			SELECT `+currentPriceSQL+`, COALESCE((SELECT stock FROM outlet_stocks WHERE outlet_id = $2 AND product_id = p.id), 0)
+			FROM products p WHERE p.id = $1 FOR UPDATE OF p
+		`, item.productID, outletID).Scan(&unitValue, &stock)
+		if err != nil {
+			return nil, err
+		}
+
+		variance := roundQuantity(item.quantity - item.system)
+		_, err = tx.Exec(
+			"UPDATE stock_take_items SET counted_quantity = $1, variance = $2, unit_value = $3 WHERE id = $4",
+			item.quantity, variance, unitValue, item.itemID,
+		)
+		if err != nil {
+			return nil, err
+		}
+
+		// penjualan setelah snapshot bisa membuat selisih minus melebihi stok
+		// saat ini, stok tidak diturunkan di bawah nol
+		adjustment := max(variance, -stock)
+		if adjustment == 0 {
+			continue
+		}
+
+		err = applyStockMovement(tx, &models.StockMovement{
+			OutletID:      outletID,
+			ProductID:     item.productID,
+			Quantity:      adjustment,
+			Reason:        models.StockReasonOpname,
+			ReferenceType: "stock_take",
+			ReferenceID:   &id,
+		})
+		if err != nil {
+			return nil, err
+		}
+
+		if adjustment < 0 {
+			if _, err := consumeLots(tx, outletID, item.productID, -adjustment); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	_, err = tx.Exec(
+		"UPDATE stock_takes SET status = 'posted', posted_by = $1, posted_at = NOW() WHERE id = $2",
+		postedBy, id,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+
+	return repo.GetByID(id)
+}
+
+func (repo *StockTakeRepository) Cancel(id int) (*models.StockTake, error) {
+	tx, err := repo.db.Begin()
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	if _, err := lockOpenStockTake(tx, id, "FOR UPDATE"); err != nil {
+		return nil, err
+	}
+	if _, err := tx.Exec("UPDATE stock_takes SET status = 'cancelled' WHERE id = $1", id); err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+
+	return repo.GetByID(id)
+}
+
+const stockTakeColumns = `
+	s.id, s.outlet_id, o.name, s.status, COALESCE(s.note, ''), s.started_by, COALESCE(s.posted_by, ''),
+	s.started_at, s.posted_at
+`
+
+const stockTakeFrom = `
+	FROM stock_takes s
+	JOIN outlets o ON o.id = s.outlet_id
+`
+
+func scanStockTake(row interface{ Scan(...interface{}) error }) (*models.StockTake, error) {
+	var s models.StockTake
+	var postedAt sql.NullTime
+
+	err := row.Scan(&s.ID, &s.OutletID, &s.OutletName, &s.Status, &s.Note, &s.StartedBy, &s.PostedBy,
+		&s.StartedAt, &postedAt)
+	if err != nil {
+		return nil, err
+	}
+	if postedAt.Valid {
+		s.PostedAt = &postedAt.Time
+	}
+
+	return &s, nil
+}
+
+// GetByID beserta item. Untuk sesi yang belum diposting hasil hitung dan nilai
+// diambil dari hitungan terkini dan harga saat ini, setelah diposting dari
+// nilai yang disimpan saat posting.
+func (repo *StockTakeRepository) GetByID(id int) (*models.StockTake, error) {
+	take, err := scanStockTake(repo.db.QueryRow("SELECT"+stockTakeColumns+stockTakeFrom+"WHERE s.id = $1", id))
+	if err == sql.ErrNoRows {
+		return nil, errors.New("stock opname tidak ditemukan")
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := repo.db.Query(`
+		SELECT i.product_id, p.name, i.system_quantity,
+			CASE WHEN s.status = 'posted' THEN i.counted_quantity ELSE c.quantity END,
+			COALESCE(i.unit_value, `+currentPriceSQL+`)
+		FROM stock_take_items i
+		JOIN stock_takes s ON s.id = i.stock_take_id
+		JOIN products p ON p.id = i.product_id
+		LEFT JOIN (
+			SELECT product_id, SUM(quantity) AS quantity FROM stock_take_counts
+			WHERE stock_take_id = $1 GROUP BY product_id
+		) c ON c.product_id = i.product_id
+		WHERE i.stock_take_id = $1
+		ORDER BY p.name, i.product_id
+	`, id)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	take.Items = make([]models.StockTakeItem, 0)
+	for rows.Next() {
+		var i models.StockTakeItem
+		var counted sql.NullFloat64
+		if err := rows.Scan(&i.ProductID, &i.ProductName, &i.SystemQuantity, &counted, &i.UnitValue); err != nil {
+			return nil, err
+		}
+		if counted.Valid {
+			variance := roundQuantity(counted.Float64 - i.SystemQuantity)
+			value := lineSubtotal(i.UnitValue, math.Abs(variance))
+			if variance < 0 {
+				value = -value
+			}
+			i.CountedQuantity = &counted.Float64
+			i.Variance = &variance
+			i.VarianceValue = &value
+		}
+		take.Items = append(take.Items, i)
+	}
+
+	return take, rows.Err()
+}
+
+// GetAll tanpa item
+func (repo *StockTakeRepository) GetAll(filter models.StockTakeFilter) ([]models.StockTake, error) {
+	conditions := []string{}
+	args := []interface{}{}
+
+	if filter.Status != "" {
+		args = append(args, filter.Status)
+		conditions = append(conditions, fmt.Sprintf("s.status = $%d", len(args)))
+	}
+	if filter.OutletID != 0 {
+		args = append(args, filter.OutletID)
+		conditions = append(conditions, fmt.Sprintf("s.outlet_id = $%d", len(args)))
+	}
+
+	query := "SELECT" + stockTakeColumns + stockTakeFrom
+	if len(conditions) > 0 {
+		query += " WHERE " + strings.Join(conditions, " AND ")
+	}
+	query += " ORDER BY s.started_at DESC, s.id DESC"
+
+	rows, err := repo.db.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	takes := make([]models.StockTake, 0)
+	for rows.Next() {
+		s, err := scanStockTake(rows)
+		if err != nil {
+			return nil, err
+		}
+		takes = append(takes, *s)
+	}
+
+	return takes, rows.Err()
+}
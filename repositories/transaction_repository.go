@@ -1,195 +1,877 @@
-package repositories
-
-import (
-	"database/sql"
-	"fmt"
-	"time"
-
-	"github.com/anggakrnwn/kasir-api/models"
-)
-
-type TransactionRepository struct {
-	db *sql.DB
-}
-
-func NewTransactionRepository(db *sql.DB) *TransactionRepository {
-	return &TransactionRepository{db: db}
-}
-
-func (repo *TransactionRepository) CreateTransaction(items []models.CheckoutItem) (*models.Transaction, error) {
-	tx, err := repo.db.Begin()
-	if err != nil {
-		return nil, err
-	}
-	defer tx.Rollback()
-
-	totalAmount := 0
-	details := make([]models.TransactionDetail, 0)
-
-	for _, item := range items {
-		var productName string
-		var productID, price, stock int
-
-		err := tx.QueryRow("SELECT id, name, price, stock FROM products WHERE id=$1 FOR UPDATE", item.ProductID).Scan(&productID, &productName, &price, &stock)
-		if err == sql.ErrNoRows {
-			return nil, fmt.Errorf("product id %d not found", item.ProductID)
-		}
-		if err != nil {
-			return nil, err
-		}
-
-		if stock < item.Quantity {
-			return nil, fmt.Errorf("insufficient stock for product '%s'. Available: %d, Requested: %d",
-				productName, stock, item.Quantity)
-		}
-
-		subtotal := item.Quantity * price
-		totalAmount += subtotal
-
-		_, err = tx.Exec("UPDATE products SET stock = stock - $1 WHERE id = $2", item.Quantity, productID)
-		if err != nil {
-			return nil, err
-		}
-
-		details = append(details, models.TransactionDetail{
-			ProductID:   productID,
-			ProductName: productName,
-			Quantity:    item.Quantity,
-			Subtotal:    subtotal,
-		})
-	}
-
-	var transactionID int
-	err = tx.QueryRow("INSERT INTO transactions (total_amount, created_at) VALUES ($1, NOW()) RETURNING id",
-		totalAmount).Scan(&transactionID)
-	if err != nil {
-		return nil, err
-	}
-
-	// PERBAIKAN: Insert transaction details dengan loop yang benar
-	for i := range details {
-		details[i].TransactionID = transactionID
-
-		var detailID int
-		err = tx.QueryRow(
-			"INSERT INTO transaction_details (transaction_id, product_id, quantity, subtotal) VALUES ($1, $2, $3, $4) RETURNING id",
-			transactionID, details[i].ProductID, details[i].Quantity, details[i].Subtotal,
-		).Scan(&detailID)
-		if err != nil {
-			return nil, err
-		}
-		details[i].ID = detailID
-	}
-
-	if err := tx.Commit(); err != nil {
-		return nil, err
-	}
-
-	// Get the complete transaction with timestamp
-	var createdAt time.Time
-	err = repo.db.QueryRow("SELECT created_at FROM transactions WHERE id = $1", transactionID).Scan(&createdAt)
-	if err != nil {
-		return nil, err
-	}
-
-	return &models.Transaction{
-		ID:          transactionID,
-		TotalAmount: totalAmount,
-		CreatedAt:   createdAt,
-		Details:     details,
-	}, nil
-}
-
-// New method for sales summary
-func (repo *TransactionRepository) GetTodaySalesSummary() (*models.SalesSummary, error) {
-	query := `
-		SELECT 
-			COALESCE(SUM(t.total_amount), 0) as total_revenue,
-			COALESCE(COUNT(t.id), 0) as total_transaksi
-		FROM transactions t
-		WHERE DATE(t.created_at) = CURRENT_DATE
-	`
-
-	var summary models.SalesSummary
-	err := repo.db.QueryRow(query).Scan(&summary.TotalRevenue, &summary.TotalTransactions)
-	if err != nil {
-		return nil, err
-	}
-
-	// Get best selling product today
-	bestSellingQuery := `
-		SELECT 
-			p.name as product_name,
-			SUM(td.quantity) as total_quantity
-		FROM transaction_details td
-		JOIN products p ON td.product_id = p.id
-		JOIN transactions t ON td.transaction_id = t.id
-		WHERE DATE(t.created_at) = CURRENT_DATE
-		GROUP BY p.name
-		ORDER BY total_quantity DESC
-		LIMIT 1
-	`
-
-	var bestProductName sql.NullString
-	var bestProductQty sql.NullInt64
-	err = repo.db.QueryRow(bestSellingQuery).Scan(&bestProductName, &bestProductQty)
-	if err != nil && err != sql.ErrNoRows {
-		return nil, err
-	}
-
-	if bestProductName.Valid && bestProductQty.Valid {
-		summary.BestSellingProduct = &models.BestSellingProduct{
-			Name:     bestProductName.String,
-			Quantity: int(bestProductQty.Int64),
-		}
-	}
-
-	return &summary, nil
-}
-
-// Method for date range report
-func (repo *TransactionRepository) GetSalesReport(startDate, endDate string) (*models.SalesSummary, error) {
-	query := `
-		SELECT 
-			COALESCE(SUM(t.total_amount), 0) as total_revenue,
-			COALESCE(COUNT(t.id), 0) as total_transaksi
-		FROM transactions t
-		WHERE DATE(t.created_at) BETWEEN $1 AND $2
-	`
-
-	var summary models.SalesSummary
-	err := repo.db.QueryRow(query, startDate, endDate).Scan(&summary.TotalRevenue, &summary.TotalTransactions)
-	if err != nil {
-		return nil, err
-	}
-
-	// Get best selling product for date range
-	bestSellingQuery := `
-		SELECT 
-			p.name as product_name,
-			SUM(td.quantity) as total_quantity
-		FROM transaction_details td
-		JOIN products p ON td.product_id = p.id
-		JOIN transactions t ON td.transaction_id = t.id
-		WHERE DATE(t.created_at) BETWEEN $1 AND $2
-		GROUP BY p.name
-		ORDER BY total_quantity DESC
-		LIMIT 1
-	`
-
-	var bestProductName sql.NullString
-	var bestProductQty sql.NullInt64
-	err = repo.db.QueryRow(bestSellingQuery, startDate, endDate).Scan(&bestProductName, &bestProductQty)
-	if err != nil && err != sql.ErrNoRows {
-		return nil, err
-	}
-
-	if bestProductName.Valid && bestProductQty.Valid {
-		summary.BestSellingProduct = &models.BestSellingProduct{
-			Name:     bestProductName.String,
-			Quantity: int(bestProductQty.Int64),
-		}
-	}
-
-	return &summary, nil
-}
+package repositories
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"math"
+	"strings"
+	"time"
+
+	"github.com/anggakrnwn/kasir-api/models"
+)
+
+// Pelanggaran aturan checkout yang disebabkan isi request atau keadaan stok,
+// bukan kegagalan server. Pesan rinci membungkus error ini dengan %w, jadi
+// pemanggil memeriksanya dengan errors.Is.
+var (
+	ErrProductNotFound      = errors.New("product not found")
+	ErrProductHasVariants   = errors.New("product has variants, checkout must use a variant id")
+	ErrNotWeighed           = errors.New("product is not weighed, quantity must be a whole number")
+	ErrUnknownUnit          = errors.New("unit is not defined for product")
+	ErrInsufficientStock    = errors.New("insufficient stock")
+	ErrEmptyBundle          = errors.New("bundle has no components")
+	ErrAmountPaidTooLow     = errors.New("amount_paid is less than total")
+	ErrCustomerNotFound     = errors.New("customer not found")
+	ErrReservationNotFound  = errors.New("reservation not found")
+	ErrReservationNotActive = errors.New("reservation is no longer active")
+	ErrCartNotCheckoutable  = errors.New("cart cannot be checked out")
+)
+
+type TransactionRepository struct {
+	db *sql.DB
+}
+
+func NewTransactionRepository(db *sql.DB) *TransactionRepository {
+	return &TransactionRepository{db: db}
+}
+
+func (repo *TransactionRepository) CreateTransaction(req models.CheckoutRequest) (*models.Transaction, error) {
+	tx, err := repo.db.Begin()
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	// FOR SHARE menahan penutupan shift sampai transaksi ini selesai
+	var shiftStatus string
+	err = tx.QueryRow("SELECT status FROM shifts WHERE id = $1 AND cashier_id = $2 FOR SHARE", req.ShiftID, req.CashierID).Scan(&shiftStatus)
+	if err == sql.ErrNoRows {
+		return nil, fmt.Errorf("shift %d not found for cashier %d", req.ShiftID, req.CashierID)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if shiftStatus != models.ShiftStatusOpen {
+		return nil, ErrShiftClosed
+	}
+
+	// keranjang dikunci sampai commit supaya tidak bisa di-checkout dua kali
+	if req.CartID != 0 {
+		if _, err := lockOpenCart(tx, req.CartID, req.OutletID); err != nil {
+			return nil, err
+		}
+	}
+
+	// stok yang ditahan reservasi milik transaksi ini (keranjang atau pesanan
+	// online) boleh dijual, reservasi lain mengurangi stok tersedia
+	reservationID := req.ReservationID
+	if req.CartID != 0 {
+		err = tx.QueryRow("SELECT id FROM stock_reservations WHERE cart_id = $1", req.CartID).Scan(&reservationID)
+		if err != nil && err != sql.ErrNoRows {
+			return nil, err
+		}
+	} else if reservationID != 0 {
+		if err := lockCheckoutReservation(tx, reservationID, req.OutletID); err != nil {
+			return nil, err
+		}
+	}
+
+	// kelompok pelanggan menentukan daftar harga yang dipakai
+	var customerGroupID sql.NullInt64
+	if req.CustomerID != nil {
+		err = tx.QueryRow("SELECT customer_group_id FROM customers WHERE id = $1", *req.CustomerID).Scan(&customerGroupID)
+		if err == sql.ErrNoRows {
+			return nil, fmt.Errorf("%w: id %d", ErrCustomerNotFound, *req.CustomerID)
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	// header transaksi dibuat dulu supaya stock ledger bisa merujuk ID-nya,
+	// total diisi setelah semua item dihitung
+	var transactionID int
+	var createdAt time.Time
+	err = tx.QueryRow(
+		"INSERT INTO transactions (total_amount, payment_method, cashier_id, shift_id, outlet_id, customer_id, created_at) VALUES (0, $1, $2, $3, $4, $5, NOW()) RETURNING id, created_at",
+		req.PaymentMethod, req.CashierID, req.ShiftID, req.OutletID, req.CustomerID,
+	).Scan(&transactionID, &createdAt)
+	if err != nil {
+		return nil, err
+	}
+
+	totalAmount := 0
+	details := make([]models.TransactionDetail, 0)
+
+	for _, item := range req.Items {
+		var productName string
+		var productID, price int
+		var stock float64
+		var hasVariants, isWeighed, isBundle bool
+
+		// stok yang dicek adalah stok outlet kasir, bukan total semua outlet
+		err := tx.QueryRow(`
+			SELECT p.id, p.name, `+currentPriceSQL+`,
+				COALESCE((SELECT os.stock FROM outlet_stocks os WHERE os.outlet_id = $2 AND os.product_id = p.id), 0),
+				p.is_weighed, p.is_bundle,
+				EXISTS (SELECT 1 FROM products v WHERE v.parent_id = p.id)
+			FROM products p WHERE p.id=$1 FOR UPDATE OF p
+		`, item.ProductID, req.OutletID).Scan(&productID, &productName, &price, &stock, &isWeighed, &isBundle, &hasVariants)
+		if err == sql.ErrNoRows {
+			return nil, fmt.Errorf("%w: id %d", ErrProductNotFound, item.ProductID)
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		// produk induk hanya pengelompokan, yang dijual selalu variannya
+		if hasVariants {
+			return nil, fmt.Errorf("%w: '%s'", ErrProductHasVariants, productName)
+		}
+
+		unit, err := resolveUnit(tx, productID, item.Unit)
+		if err != nil {
+			return nil, err
+		}
+
+		// stiker timbangan yang memuat harga: jumlah dihitung balik dari harga
+		// per satuan, subtotal tetap persis sama dengan harga di stiker
+		unitQuantity := item.Quantity
+		if item.EmbeddedPrice > 0 {
+			unitQuantity = roundQuantity(float64(item.EmbeddedPrice) / float64(price*unit.Factor))
+		}
+
+		if !isWeighed && !isWholeQuantity(unitQuantity) {
+			return nil, fmt.Errorf("%w: '%s'", ErrNotWeighed, productName)
+		}
+		quantity := roundQuantity(unitQuantity * float64(unit.Factor))
+
+		if !isBundle {
+			reserved, err := reservedStock(tx, req.OutletID, productID, reservationID)
+			if err != nil {
+				return nil, err
+			}
+			stock = roundQuantity(stock - reserved)
+		}
+		if !isBundle && stock < quantity {
+			return nil, fmt.Errorf("%w for product '%s'. Available: %g, Requested: %g",
+				ErrInsufficientStock, productName, stock, quantity)
+		}
+
+		basePrice, pricing, tierID, err := resolveUnitPrice(tx, productID, price, quantity, customerGroupID)
+		if err != nil {
+			return nil, err
+		}
+
+		// harga khusus satuan (mis. harga per pack) dipakai kecuali pelanggan
+		// mendapat harga kelompok
+		unitPrice := basePrice * unit.Factor
+		if unit.Price != nil && pricing != models.PricingGroup {
+			unitPrice, pricing, tierID = *unit.Price, models.PricingUnit, nil
+		}
+
+		subtotal := lineSubtotal(unitPrice, unitQuantity)
+		if item.EmbeddedPrice > 0 {
+			unitPrice, pricing, tierID = price*unit.Factor, models.PricingBase, nil
+			subtotal = item.EmbeddedPrice
+		}
+		totalAmount += subtotal
+
+		// potongan tier/kelompok dihitung dari harga daftar satuan yang sama,
+		// dicatat per baris untuk discount_total di laporan Z
+		discount := 0
+		if pricing == models.PricingTier || pricing == models.PricingGroup {
+			listPrice := price * unit.Factor
+			if unit.Price != nil {
+				listPrice = *unit.Price
+			}
+			discount = lineDiscount(listPrice, unitQuantity, subtotal)
+		}
+
+		detail := models.TransactionDetail{
+			TransactionID: transactionID,
+			ProductID:     productID,
+			ProductName:   productName,
+			Quantity:      quantity,
+			Unit:          unit.Name,
+			UnitQuantity:  unitQuantity,
+			Factor:        unit.Factor,
+			UnitPrice:     unitPrice,
+			Pricing:       pricing,
+			PriceTierID:   tierID,
+			Subtotal:      subtotal,
+			Discount:      discount,
+		}
+
+		err = tx.QueryRow(
+			`INSERT INTO transaction_details (transaction_id, product_id, quantity, unit, unit_quantity, factor, unit_price, pricing, price_tier_id, subtotal, discount_amount)
+			VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11) RETURNING id`,
+			transactionID, detail.ProductID, detail.Quantity, detail.Unit, detail.UnitQuantity, detail.Factor,
+			detail.UnitPrice, detail.Pricing, detail.PriceTierID, detail.Subtotal, detail.Discount,
+		).Scan(&detail.ID)
+		if err != nil {
+			return nil, err
+		}
+
+		// paket tidak punya stok sendiri, stok dikurangi dari setiap komponennya
+		if isBundle {
+			detail.Components, err = consumeBundleComponents(tx, &detail, req.OutletID, reservationID)
+			if err != nil {
+				return nil, err
+			}
+		} else {
+			err = applyStockMovement(tx, &models.StockMovement{
+				OutletID:      req.OutletID,
+				ProductID:     productID,
+				Quantity:      -quantity,
+				Unit:          unit.Name,
+				UnitQuantity:  -unitQuantity,
+				Factor:        unit.Factor,
+				Reason:        models.StockReasonSale,
+				ReferenceType: "transaction",
+				ReferenceID:   &transactionID,
+			})
+			if err != nil {
+				return nil, err
+			}
+
+			if err := consumeDetailLots(tx, &detail, req.OutletID, productID, quantity); err != nil {
+				return nil, err
+			}
+		}
+
+		details = append(details, detail)
+	}
+
+	amountPaid := totalAmount
+	if req.PaymentMethod == models.PaymentMethodCash && req.AmountPaid > 0 {
+		if req.AmountPaid < totalAmount {
+			return nil, fmt.Errorf("%w: amount_paid %d, total %d", ErrAmountPaidTooLow, req.AmountPaid, totalAmount)
+		}
+		amountPaid = req.AmountPaid
+	}
+	taxAmount := includedTax(totalAmount, req.TaxRate)
+
+	// nomor struk diambil paling akhir supaya kunci urutan outlet ditahan
+	// sesingkat mungkin
+	receiptNumber, err := nextReceiptNumber(tx, req.ReceiptNumberFormat, req.OutletID, createdAt.In(req.Location))
+	if err != nil {
+		return nil, err
+	}
+
+	// dicek setelah kunci urutan struk didapat, tutup hari memakai kunci yang
+	// sama sehingga tidak ada transaksi yang lolos ke tanggal yang sudah ditutup
+	if err := requireDayOpen(tx, req.OutletID, createdAt.In(req.Location).Format("2006-01-02")); err != nil {
+		return nil, err
+	}
+
+	if reservationID != 0 {
+		_, err = tx.Exec(
+			"UPDATE stock_reservations SET status = 'consumed', transaction_id = $1, released_at = NOW() WHERE id = $2",
+			transactionID, reservationID,
+		)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if req.CartID != 0 {
+		_, err = tx.Exec(
+			"UPDATE carts SET status = 'checked_out', transaction_id = $1, cashier_id = $2, updated_at = NOW() WHERE id = $3",
+			transactionID, req.CashierID, req.CartID,
+		)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	_, err = tx.Exec(
+		`UPDATE transactions SET receipt_number = $1, total_amount = $2, tax_rate = $3, tax_amount = $4,
+			amount_paid = $5, change_amount = $6 WHERE id = $7`,
+		receiptNumber, totalAmount, req.TaxRate, taxAmount, amountPaid, amountPaid-totalAmount, transactionID,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	alerts, err := raiseLowStockAlerts(tx, req.OutletID, transactionID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+
+	return &models.Transaction{
+		ID:             transactionID,
+		ReceiptNumber:  receiptNumber,
+		TotalAmount:    totalAmount,
+		TaxRate:        req.TaxRate,
+		TaxAmount:      taxAmount,
+		PaymentMethod:  req.PaymentMethod,
+		AmountPaid:     amountPaid,
+		ChangeAmount:   amountPaid - totalAmount,
+		CashierID:      req.CashierID,
+		ShiftID:        req.ShiftID,
+		OutletID:       req.OutletID,
+		CustomerID:     req.CustomerID,
+		CreatedAt:      createdAt,
+		Details:        details,
+		LowStockAlerts: alerts,
+	}, nil
+}
+
+// nextReceiptNumber menaikkan urutan struk outlet pada tanggal createdAt
+// (sudah dalam zona waktu toko) dan menyusun nomornya menurut format. Baris
+// receipt_sequences tetap terkunci sampai transaksi checkout selesai: checkout
+// lain di outlet yang sama menunggu, dan nomor dari checkout yang gagal ikut
+// di-rollback sehingga urutan tanpa celah.
+func nextReceiptNumber(q queryer, format string, outletID int, createdAt time.Time) (string, error) {
+	var sequence int
+	var outletCode string
+	err := q.QueryRow(`
+		INSERT INTO receipt_sequences (outlet_id, business_date, last_number) VALUES ($1, $2, 1)
+		ON CONFLICT (outlet_id, business_date) DO UPDATE SET last_number = receipt_sequences.last_number + 1
+		RETURNING last_number, (SELECT code FROM outlets WHERE id = $1)
+	`, outletID, createdAt.Format("2006-01-02")).Scan(&sequence, &outletCode)
+	if err != nil {
+		return "", err
+	}
+
+	return formatReceiptNumber(format, outletCode, createdAt, sequence), nil
+}
+
+// formatReceiptNumber mengganti {outlet}, {date} (YYYYMMDD) dan {seq} (minimal
+// 4 digit) pada format
+func formatReceiptNumber(format, outletCode string, date time.Time, sequence int) string {
+	return strings.NewReplacer(
+		"{outlet}", outletCode,
+		"{date}", date.Format("20060102"),
+		"{seq}", fmt.Sprintf("%04d", sequence),
+	).Replace(format)
+}
+
+// includedTax menghitung PPN yang sudah termasuk di total untuk tarif rate
+// persen, dibulatkan ke rupiah terdekat
+func includedTax(total int, rate float64) int {
+	if rate <= 0 {
+		return 0
+	}
+	return int(math.Round(float64(total) * rate / (100 + rate)))
+}
+
+// lineDiscount adalah selisih harga daftar dengan subtotal yang dibayar.
+// Harga tier yang lebih mahal dari harga daftar tidak dihitung sebagai
+// potongan negatif.
+func lineDiscount(listPrice int, unitQuantity float64, subtotal int) int {
+	if discount := lineSubtotal(listPrice, unitQuantity) - subtotal; discount > 0 {
+		return discount
+	}
+	return 0
+}
+
+// GetByID mengembalikan transaksi beserta nama kasir, outlet dan produk
+// di setiap detail
+func (repo *TransactionRepository) GetByID(id int) (*models.Transaction, error) {
+	var t models.Transaction
+	var cashierID, shiftID sql.NullInt64
+	var customerID sql.NullInt64
+	err := repo.db.QueryRow(`
+		SELECT t.id, COALESCE(t.receipt_number, ''), t.total_amount, t.tax_rate, t.tax_amount, t.payment_method, COALESCE(t.amount_paid, t.total_amount), t.change_amount,
+			t.cashier_id, COALESCE(c.name, ''), t.shift_id, t.outlet_id, o.name, t.customer_id, t.created_at
+		FROM transactions t
+		JOIN outlets o ON o.id = t.outlet_id
+		LEFT JOIN cashiers c ON c.id = t.cashier_id
+		WHERE t.id = $1
+	`, id).Scan(&t.ID, &t.ReceiptNumber, &t.TotalAmount, &t.TaxRate, &t.TaxAmount, &t.PaymentMethod, &t.AmountPaid, &t.ChangeAmount,
+		&cashierID, &t.CashierName, &shiftID, &t.OutletID, &t.OutletName, &customerID, &t.CreatedAt)
+	if err == sql.ErrNoRows {
+		return nil, errors.New("transaksi tidak ditemukan")
+	}
+	if err != nil {
+		return nil, err
+	}
+	t.CashierID = int(cashierID.Int64)
+	t.ShiftID = int(shiftID.Int64)
+	if customerID.Valid {
+		v := int(customerID.Int64)
+		t.CustomerID = &v
+	}
+
+	rows, err := repo.db.Query(`
+		SELECT td.id, td.transaction_id, td.product_id, p.name, td.quantity, td.unit, td.unit_quantity, td.factor,
+			td.unit_price, td.pricing, td.price_tier_id, td.subtotal, td.discount_amount
+		FROM transaction_details td
+		JOIN products p ON p.id = td.product_id
+		WHERE td.transaction_id = $1
+		ORDER BY td.id
+	`, id)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	t.Details = make([]models.TransactionDetail, 0)
+	for rows.Next() {
+		var d models.TransactionDetail
+		var priceTierID sql.NullInt64
+		err := rows.Scan(&d.ID, &d.TransactionID, &d.ProductID, &d.ProductName, &d.Quantity, &d.Unit, &d.UnitQuantity, &d.Factor,
+			&d.UnitPrice, &d.Pricing, &priceTierID, &d.Subtotal, &d.Discount)
+		if err != nil {
+			return nil, err
+		}
+		if priceTierID.Valid {
+			v := int(priceTierID.Int64)
+			d.PriceTierID = &v
+		}
+		t.Details = append(t.Details, d)
+	}
+
+	return &t, rows.Err()
+}
+
+// consumeBundleComponents mengunci dan mengurangi stok komponen untuk paket
+// yang terjual di detail, lalu mencatat rinciannya. Komponen dikunci urut
+// product id supaya dua checkout paket yang sama tidak saling deadlock.
+func consumeBundleComponents(tx execer, detail *models.TransactionDetail, outletID, reservationID int) ([]models.TransactionDetailComponent, error) {
+	rows, err := tx.Query(
+		"SELECT component_id, quantity FROM bundle_components WHERE bundle_id = $1 ORDER BY component_id",
+		detail.ProductID,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	components := make([]models.TransactionDetailComponent, 0)
+	for rows.Next() {
+		var c models.TransactionDetailComponent
+		if err := rows.Scan(&c.ProductID, &c.Quantity); err != nil {
+			rows.Close()
+			return nil, err
+		}
+		c.Quantity = roundQuantity(c.Quantity * detail.Quantity)
+		components = append(components, c)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	if len(components) == 0 {
+		return nil, fmt.Errorf("%w: '%s'", ErrEmptyBundle, detail.ProductName)
+	}
+
+	for i := range components {
+		c := &components[i]
+
+		err := tx.QueryRow("SELECT name FROM products WHERE id = $1 FOR UPDATE", c.ProductID).Scan(&c.ProductName)
+		if err != nil {
+			return nil, err
+		}
+		stock, err := outletStock(tx, outletID, c.ProductID)
+		if err != nil {
+			return nil, err
+		}
+		reserved, err := reservedStock(tx, outletID, c.ProductID, reservationID)
+		if err != nil {
+			return nil, err
+		}
+		stock = roundQuantity(stock - reserved)
+		if stock < c.Quantity {
+			return nil, fmt.Errorf("%w for product '%s' in bundle '%s'. Available: %g, Requested: %g",
+				ErrInsufficientStock, c.ProductName, detail.ProductName, stock, c.Quantity)
+		}
+
+		_, err = tx.Exec(
+			"INSERT INTO transaction_detail_components (transaction_detail_id, product_id, quantity) VALUES ($1, $2, $3)",
+			detail.ID, c.ProductID, c.Quantity,
+		)
+		if err != nil {
+			return nil, err
+		}
+
+		err = applyStockMovement(tx, &models.StockMovement{
+			OutletID:      outletID,
+			ProductID:     c.ProductID,
+			Quantity:      -c.Quantity,
+			Reason:        models.StockReasonSale,
+			ReferenceType: "transaction",
+			ReferenceID:   &detail.TransactionID,
+			Note:          "bundle " + detail.ProductName,
+		})
+		if err != nil {
+			return nil, err
+		}
+
+		if err := consumeDetailLots(tx, detail, outletID, c.ProductID, c.Quantity); err != nil {
+			return nil, err
+		}
+	}
+
+	return components, nil
+}
+
+// consumeDetailLots mengambil stok dari lot secara FEFO untuk satu baris
+// transaksi dan mencatat lot yang terpakai
+func consumeDetailLots(tx execer, detail *models.TransactionDetail, outletID, productID int, quantity float64) error {
+	lots, err := consumeLots(tx, outletID, productID, quantity)
+	if err != nil {
+		return err
+	}
+
+	for _, l := range lots {
+		_, err := tx.Exec(
+			"INSERT INTO transaction_detail_lots (transaction_detail_id, lot_id, product_id, quantity) VALUES ($1, $2, $3, $4)",
+			detail.ID, l.LotID, l.ProductID, l.Quantity,
+		)
+		if err != nil {
+			return err
+		}
+	}
+	detail.Lots = append(detail.Lots, lots...)
+
+	return nil
+}
+
+// resolveUnitPrice memilih harga satuan untuk satu baris checkout. Tier milik
+// kelompok pelanggan didahulukan, lalu tier umum dengan min_quantity terbesar
+// yang terpenuhi. Tanpa tier yang cocok dipakai harga dasar produk.
+func resolveUnitPrice(q queryer, productID, basePrice int, quantity float64, customerGroupID sql.NullInt64) (int, string, *int, error) {
+	var tierID, tierPrice int
+	var tierGroupID sql.NullInt64
+
+	err := q.QueryRow(`
+		SELECT id, price, customer_group_id
+		FROM product_price_tiers
+		WHERE product_id = $1
+			AND min_quantity <= $2
+			AND (customer_group_id IS NULL OR customer_group_id = $3)
+		ORDER BY (customer_group_id IS NOT NULL) DESC, min_quantity DESC
+		LIMIT 1
+	`, productID, quantity, customerGroupID).Scan(&tierID, &tierPrice, &tierGroupID)
+	if err == sql.ErrNoRows {
+		return basePrice, models.PricingBase, nil, nil
+	}
+	if err != nil {
+		return 0, "", nil, err
+	}
+
+	if tierGroupID.Valid {
+		return tierPrice, models.PricingGroup, &tierID, nil
+	}
+	return tierPrice, models.PricingTier, &tierID, nil
+}
+
+// GetSalesReport untuk transaksi dengan start <= created_at < end, outletID 0
+// berarti semua outlet. Batas dihitung pemanggil di zona waktu toko dan
+// dibandingkan langsung dengan created_at supaya index created_at terpakai.
+func (repo *TransactionRepository) GetSalesReport(start, end time.Time, outletID int) (*models.SalesSummary, error) {
+	query := `
+		SELECT 
+			COALESCE(SUM(t.total_amount), 0) as total_revenue,
+			COALESCE(COUNT(t.id), 0) as total_transaksi
+		FROM transactions t
+		WHERE t.created_at >= $1 AND t.created_at < $2 AND ($3 = 0 OR t.outlet_id = $3)
+	`
+
+	var summary models.SalesSummary
+	err := repo.db.QueryRow(query, start, end, outletID).Scan(&summary.TotalRevenue, &summary.TotalTransactions)
+	if err != nil {
+		return nil, err
+	}
+
+	// Get best selling product for date range
+	bestSellingQuery := `
+		SELECT 
+			COALESCE(parent.name, p.name) as product_name,
+			SUM(td.quantity) as total_quantity
+		FROM transaction_details td
+		JOIN products p ON td.product_id = p.id
+		LEFT JOIN products parent ON parent.id = p.parent_id
+		JOIN transactions t ON td.transaction_id = t.id
+		WHERE t.created_at >= $1 AND t.created_at < $2 AND ($3 = 0 OR t.outlet_id = $3)
+		GROUP BY COALESCE(parent.name, p.name)
+		ORDER BY total_quantity DESC
+		LIMIT 1
+	`
+
+	var bestProductName sql.NullString
+	var bestProductQty sql.NullFloat64
+	err = repo.db.QueryRow(bestSellingQuery, start, end, outletID).Scan(&bestProductName, &bestProductQty)
+	if err != nil && err != sql.ErrNoRows {
+		return nil, err
+	}
+
+	if bestProductName.Valid && bestProductQty.Valid {
+		summary.BestSellingProduct = &models.BestSellingProduct{
+			Name:     bestProductName.String,
+			Quantity: bestProductQty.Float64,
+		}
+	}
+
+	return &summary, nil
+}
+
+// GetOutletSales merinci penjualan per outlet untuk laporan gabungan owner
+func (repo *TransactionRepository) GetOutletSales(start, end time.Time) ([]models.OutletSales, error) {
+	query := `
+		SELECT o.id, o.name, COALESCE(SUM(t.total_amount), 0), COUNT(t.id)
+		FROM outlets o
+		LEFT JOIN transactions t ON t.outlet_id = o.id AND t.created_at >= $1 AND t.created_at < $2
+		GROUP BY o.id, o.name, o.type
+		HAVING o.type = 'store' OR COUNT(t.id) > 0
+		ORDER BY o.id
+	`
+
+	rows, err := repo.db.Query(query, start, end)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	outlets := make([]models.OutletSales, 0)
+	for rows.Next() {
+		var o models.OutletSales
+		if err := rows.Scan(&o.OutletID, &o.OutletName, &o.TotalRevenue, &o.TotalTransactions); err != nil {
+			return nil, err
+		}
+		outlets = append(outlets, o)
+	}
+
+	return outlets, rows.Err()
+}
+
+// Total penjualan per metode pembayaran untuk start <= created_at < end,
+// outletID 0 berarti semua outlet
+func (repo *TransactionRepository) GetPaymentTotals(start, end time.Time, outletID int) ([]models.PaymentTotal, error) {
+	query := `
+		SELECT payment_method, COALESCE(SUM(total_amount), 0), COUNT(id)
+		FROM transactions
+		WHERE created_at >= $1 AND created_at < $2 AND ($3 = 0 OR outlet_id = $3)
+		GROUP BY payment_method
+		ORDER BY payment_method
+	`
+
+	rows, err := repo.db.Query(query, start, end, outletID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	payments := make([]models.PaymentTotal, 0)
+	for rows.Next() {
+		var p models.PaymentTotal
+		if err := rows.Scan(&p.Method, &p.TotalAmount, &p.TotalTransactions); err != nil {
+			return nil, err
+		}
+		payments = append(payments, p)
+	}
+
+	return payments, rows.Err()
+}
+
+// EachTransactionLine memanggil fn untuk setiap detail transaksi yang cocok
+// dengan filter secara berurutan. Baris dibaca satu per satu dari koneksi,
+// jadi rentang yang panjang tidak dimuat seluruhnya ke memori.
+func (repo *TransactionRepository) EachTransactionLine(filter models.TransactionLineFilter, fn func(models.TransactionLine) error) error {
+	conditions := []string{}
+	args := []interface{}{}
+	addCondition := func(condition string, value interface{}) {
+		args = append(args, value)
+		conditions = append(conditions, fmt.Sprintf(condition, len(args)))
+	}
+	if !filter.Start.IsZero() {
+		addCondition("t.created_at >= $%d", filter.Start)
+	}
+	if !filter.End.IsZero() {
+		addCondition("t.created_at < $%d", filter.End)
+	}
+	if filter.OutletID != 0 {
+		addCondition("t.outlet_id = $%d", filter.OutletID)
+	}
+	if filter.ReceiptNumber != "" {
+		// awalan LIKE memakai index text_pattern_ops, wildcard di input di-escape
+		escaped := strings.NewReplacer(`\`, `\\`, "%", `\%`, "_", `\_`).Replace(filter.ReceiptNumber)
+		addCondition("t.receipt_number LIKE $%d", escaped+"%")
+	}
+
+	query := `
+		SELECT t.id, COALESCE(t.receipt_number, ''), t.created_at, t.outlet_id, o.name, t.payment_method,
+			td.product_id, p.name, td.unit_quantity, td.unit, td.unit_price, td.subtotal
+		FROM transactions t
+		JOIN outlets o ON o.id = t.outlet_id
+		JOIN transaction_details td ON td.transaction_id = t.id
+		JOIN products p ON p.id = td.product_id`
+	if len(conditions) > 0 {
+		query += " WHERE " + strings.Join(conditions, " AND ")
+	}
+	query += " ORDER BY t.created_at, t.id, td.id"
+
+	rows, err := repo.db.Query(query, args...)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var l models.TransactionLine
+		err := rows.Scan(&l.TransactionID, &l.ReceiptNumber, &l.CreatedAt, &l.OutletID, &l.OutletName, &l.PaymentMethod,
+			&l.ProductID, &l.ProductName, &l.Quantity, &l.Unit, &l.UnitPrice, &l.Subtotal)
+		if err != nil {
+			return err
+		}
+		if err := fn(l); err != nil {
+			return err
+		}
+	}
+
+	return rows.Err()
+}
+
+// GetSalesTrend mengelompokkan penjualan start <= created_at < end per
+// jam/hari/minggu/bulan menurut jam dinding di loc. generate_series membuat
+// semua bucket supaya bucket tanpa penjualan tetap muncul dengan nilai 0.
+func (repo *TransactionRepository) GetSalesTrend(start, end time.Time, granularity string, loc *time.Location, outletID int) ([]models.SalesTrendBucket, error) {
+	query := `
+		WITH buckets AS (
+			SELECT generate_series(
+				date_trunc($3::text, $1::timestamptz AT TIME ZONE $5::text),
+				date_trunc($3::text, ($2::timestamptz - interval '1 second') AT TIME ZONE $5::text),
+				('1 ' || $3::text)::interval
+			) AS bucket
+		),
+		sales AS (
+			SELECT date_trunc($3::text, t.created_at AT TIME ZONE $5::text) AS bucket, SUM(t.total_amount) AS revenue, COUNT(t.id) AS transactions
+			FROM transactions t
+			WHERE t.created_at >= $1 AND t.created_at < $2 AND ($4 = 0 OR t.outlet_id = $4)
+			GROUP BY 1
+		),
+		items AS (
+			SELECT date_trunc($3::text, t.created_at AT TIME ZONE $5::text) AS bucket, SUM(td.quantity) AS quantity
+			FROM transaction_details td
+			JOIN transactions t ON t.id = td.transaction_id
+			WHERE t.created_at >= $1 AND t.created_at < $2 AND ($4 = 0 OR t.outlet_id = $4)
+			GROUP BY 1
+		)
+		SELECT b.bucket, COALESCE(s.revenue, 0), COALESCE(s.transactions, 0), COALESCE(i.quantity, 0)
+		FROM buckets b
+		LEFT JOIN sales s ON s.bucket = b.bucket
+		LEFT JOIN items i ON i.bucket = b.bucket
+		ORDER BY b.bucket
+	`
+
+	rows, err := repo.db.Query(query, start, end, granularity, outletID, loc.String())
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	buckets := make([]models.SalesTrendBucket, 0)
+	for rows.Next() {
+		var b models.SalesTrendBucket
+		var bucket time.Time
+		if err := rows.Scan(&bucket, &b.TotalRevenue, &b.TotalTransactions, &b.ItemsSold); err != nil {
+			return nil, err
+		}
+		// bucket adalah jam dinding tanpa zona, dipasang kembali ke loc
+		b.Start = time.Date(bucket.Year(), bucket.Month(), bucket.Day(), bucket.Hour(), 0, 0, 0, loc)
+		if b.TotalTransactions > 0 {
+			b.AverageBasket = (b.TotalRevenue + b.TotalTransactions/2) / b.TotalTransactions
+		}
+		buckets = append(buckets, b)
+	}
+
+	return buckets, rows.Err()
+}
+
+// productSalesFrom menggabungkan produk yang bisa dijual (bukan induk varian)
+// dengan baris transaksi di rentang tanggal, produk tanpa penjualan tetap ikut
+const productSalesFrom = `
+	FROM products p
+	LEFT JOIN categories c ON c.id = p.category_id
+	LEFT JOIN (
+		SELECT td.product_id, td.quantity, td.subtotal, td.transaction_id
+		FROM transaction_details td
+		JOIN transactions t ON t.id = td.transaction_id
+		WHERE t.created_at >= $1 AND t.created_at < $2 AND ($3 = 0 OR t.outlet_id = $3)
+	) s ON s.product_id = p.id
+	WHERE NOT EXISTS (SELECT 1 FROM products v WHERE v.parent_id = p.id)
+`
+
+// GetProductPerformance mengembalikan penjualan per produk dan per kategori
+// beserta totalnya, belum diurutkan. Baris total dihitung dengan GROUPING SETS
+// supaya jumlah transaksi unik tidak terhitung ganda.
+func (repo *TransactionRepository) GetProductPerformance(start, end time.Time, outletID int) (*models.ProductPerformanceReport, error) {
+	rows, err := repo.db.Query(`
+		SELECT p.id, p.name, p.category_id, COALESCE(c.name, ''),
+			COALESCE(SUM(s.quantity), 0), COALESCE(SUM(s.subtotal), 0), COUNT(DISTINCT s.transaction_id)
+	`+productSalesFrom+`
+		GROUP BY p.id, p.name, p.category_id, c.name
+	`, start, end, outletID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	report := &models.ProductPerformanceReport{
+		Products:   make([]models.ProductPerformance, 0),
+		Categories: make([]models.CategoryPerformance, 0),
+	}
+	for rows.Next() {
+		var p models.ProductPerformance
+		var categoryID sql.NullInt64
+		err := rows.Scan(&p.ProductID, &p.ProductName, &categoryID, &p.CategoryName, &p.Quantity, &p.Revenue, &p.Transactions)
+		if err != nil {
+			return nil, err
+		}
+		if categoryID.Valid {
+			v := int(categoryID.Int64)
+			p.CategoryID = &v
+		}
+		report.Products = append(report.Products, p)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	categoryRows, err := repo.db.Query(`
+		SELECT GROUPING(p.category_id), p.category_id, COALESCE(c.name, ''), COUNT(DISTINCT p.id),
+			COALESCE(SUM(s.quantity), 0), COALESCE(SUM(s.subtotal), 0), COUNT(DISTINCT s.transaction_id)
+	`+productSalesFrom+`
+		GROUP BY GROUPING SETS ((p.category_id, c.name), ())
+		ORDER BY COALESCE(SUM(s.subtotal), 0) DESC, c.name
+	`, start, end, outletID)
+	if err != nil {
+		return nil, err
+	}
+	defer categoryRows.Close()
+
+	for categoryRows.Next() {
+		var c models.CategoryPerformance
+		var total int
+		var categoryID sql.NullInt64
+		err := categoryRows.Scan(&total, &categoryID, &c.CategoryName, &c.Products, &c.Quantity, &c.Revenue, &c.Transactions)
+		if err != nil {
+			return nil, err
+		}
+
+		if total == 1 {
+			report.TotalQuantity = c.Quantity
+			report.TotalRevenue = c.Revenue
+			report.TotalTransactions = c.Transactions
+			continue
+		}
+
+		if categoryID.Valid {
+			v := int(categoryID.Int64)
+			c.CategoryID = &v
+		} else {
+			c.CategoryName = "Tanpa Kategori"
+		}
+		report.Categories = append(report.Categories, c)
+	}
+
+	return report, categoryRows.Err()
+}
@@ -0,0 +1,83 @@
+package repositories
+
+import (
+	"testing"
+	"time"
+)
+
+func TestIncludedTax(t *testing.T) {
+	tests := []struct {
+		name  string
+		total int
+		rate  float64
+		want  int
+	}{
+		{"ppn 11 on round total", 111000, 11, 11000},
+		{"ppn 11 rounds to nearest rupiah", 10000, 11, 991},
+		{"ppn 12", 112000, 12, 12000},
+		{"fractional rate", 100000, 1.1, 1088},
+		{"zero rate", 50000, 0, 0},
+		{"negative rate disables tax", 50000, -11, 0},
+		{"zero total", 0, 11, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := includedTax(tt.total, tt.rate); got != tt.want {
+				t.Errorf("includedTax(%d, %v) = %d, want %d", tt.total, tt.rate, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestLineDiscount(t *testing.T) {
+	tests := []struct {
+		name         string
+		listPrice    int
+		unitQuantity float64
+		subtotal     int
+		want         int
+	}{
+		{"tier price per piece", 3500, 12, 12 * 3000, 6000},
+		{"weighed line rounds like the subtotal", 14000, 1.25, lineSubtotal(12000, 1.25), 2500},
+		{"no reduction", 3500, 2, 7000, 0},
+		{"tier above list price is not a negative discount", 3000, 2, 7000, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := lineDiscount(tt.listPrice, tt.unitQuantity, tt.subtotal); got != tt.want {
+				t.Errorf("lineDiscount(%d, %v, %d) = %d, want %d", tt.listPrice, tt.unitQuantity, tt.subtotal, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFormatReceiptNumber(t *testing.T) {
+	// tanggal sudah dalam zona waktu toko, 23:30 WIB tetap tanggal 20
+	wib := time.FixedZone("WIB", 7*3600)
+	date := time.Date(2026, 1, 20, 23, 30, 0, 0, wib)
+
+	tests := []struct {
+		name     string
+		format   string
+		outlet   string
+		sequence int
+		want     string
+	}{
+		{"default format", "INV/{outlet}/{date}/{seq}", "PST", 1, "INV/PST/20260120/0001"},
+		{"sequence wider than padding", "INV/{outlet}/{date}/{seq}", "PST", 12345, "INV/PST/20260120/12345"},
+		{"placeholders in any order", "{seq}-{date}-{outlet}", "CBG2", 42, "0042-20260120-CBG2"},
+		{"repeated placeholder", "{outlet}{outlet}/{seq}", "A", 7, "AA/0007"},
+		{"no placeholders", "STRUK", "PST", 3, "STRUK"},
+		{"unknown placeholder kept", "{year}/{seq}", "PST", 3, "{year}/0003"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := formatReceiptNumber(tt.format, tt.outlet, date, tt.sequence); got != tt.want {
+				t.Errorf("formatReceiptNumber(%q) = %q, want %q", tt.format, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,385 @@
+package repositories
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/anggakrnwn/kasir-api/models"
+)
+
+type TransferRepository struct {
+	db *sql.DB
+}
+
+func NewTransferRepository(db *sql.DB) *TransferRepository {
+	return &TransferRepository{db: db}
+}
+
+// Send mengurangi stok outlet asal dan mencatat mutasi berstatus sent. Produk
+// dikunci urut id supaya dua mutasi yang berjalan bersamaan tidak deadlock.
+func (repo *TransferRepository) Send(req models.StockTransferRequest, sentBy string) (*models.StockTransfer, error) {
+	tx, err := repo.db.Begin()
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	var transferID int
+	err = tx.QueryRow(`
+		INSERT INTO stock_transfers (from_outlet_id, to_outlet_id, status, note, sent_by)
+		VALUES ($1, $2, 'sent', NULLIF($3, ''), $4) RETURNING id
+	`, req.FromOutletID, req.ToOutletID, req.Note, sentBy).Scan(&transferID)
+	if err != nil {
+		return nil, err
+	}
+
+	items := append([]models.StockTransferItemRequest(nil), req.Items...)
+	sort.Slice(items, func(i, j int) bool { return items[i].ProductID < items[j].ProductID })
+
+	for _, item := range items {
+		var productName string
+		var isWeighed, isBundle, hasVariants bool
+		err := tx.QueryRow(`
+			SELECT p.name, p.is_weighed, p.is_bundle, EXISTS (SELECT 1 FROM products v WHERE v.parent_id = p.id)
+			FROM products p WHERE p.id = $1 FOR UPDATE OF p
+		`, item.ProductID).Scan(&productName, &isWeighed, &isBundle, &hasVariants)
+		if err == sql.ErrNoRows {
+			return nil, fmt.Errorf("product id %d not found", item.ProductID)
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		if isBundle || hasVariants {
+			return nil, fmt.Errorf("product '%s' has no stock of its own and cannot be transferred", productName)
+		}
+		if !isWeighed && !isWholeQuantity(item.Quantity) {
+			return nil, fmt.Errorf("product '%s' is not weighed, quantity must be a whole number", productName)
+		}
+
+		// stok yang ditahan reservasi aktif tidak ikut dikirim
+		quantity := roundQuantity(item.Quantity)
+		stock, err := outletStock(tx, req.FromOutletID, item.ProductID)
+		if err != nil {
+			return nil, err
+		}
+		reserved, err := reservedStock(tx, req.FromOutletID, item.ProductID, 0)
+		if err != nil {
+			return nil, err
+		}
+		if available := roundQuantity(stock - reserved); available < quantity {
+			return nil, fmt.Errorf("insufficient stock for product '%s' at outlet %d. Available: %g, Requested: %g",
+				productName, req.FromOutletID, available, quantity)
+		}
+
+		var itemID int
+		err = tx.QueryRow(
+			"INSERT INTO stock_transfer_items (transfer_id, product_id, quantity_sent) VALUES ($1, $2, $3) RETURNING id",
+			transferID, item.ProductID, quantity,
+		).Scan(&itemID)
+		if err != nil {
+			return nil, err
+		}
+
+		err = applyStockMovement(tx, &models.StockMovement{
+			OutletID:      req.FromOutletID,
+			ProductID:     item.ProductID,
+			Quantity:      -quantity,
+			Reason:        models.StockReasonTransferOut,
+			ReferenceType: "stock_transfer",
+			ReferenceID:   &transferID,
+		})
+		if err != nil {
+			return nil, err
+		}
+
+		// lot yang dikirim dicatat supaya bisa dibuat ulang di outlet tujuan
+		lots, err := consumeLots(tx, req.FromOutletID, item.ProductID, quantity)
+		if err != nil {
+			return nil, err
+		}
+		for _, l := range lots {
+			_, err := tx.Exec(
+				"INSERT INTO stock_transfer_item_lots (transfer_item_id, lot_id, quantity) VALUES ($1, $2, $3)",
+				itemID, l.LotID, l.Quantity,
+			)
+			if err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+
+	return repo.GetByID(transferID)
+}
+
+// Receive menambah stok outlet tujuan sebesar jumlah yang benar-benar diterima.
+// received berisi jumlah per product id untuk item yang berbeda dari jumlah
+// kirim; selisihnya dicatat sebagai discrepancy.
+func (repo *TransferRepository) Receive(id int, received map[int]models.ReceiveTransferItem, receivedBy string) (*models.StockTransfer, error) {
+	tx, err := repo.db.Begin()
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	var toOutletID int
+	var status string
+	err = tx.QueryRow("SELECT to_outlet_id, status FROM stock_transfers WHERE id = $1 FOR UPDATE", id).Scan(&toOutletID, &status)
+	if err == sql.ErrNoRows {
+		return nil, errors.New("mutasi stok tidak ditemukan")
+	}
+	if err != nil {
+		return nil, err
+	}
+	if status != models.TransferStatusSent {
+		return nil, fmt.Errorf("stock transfer %d is already %s", id, status)
+	}
+
+	rows, err := tx.Query(
+		"SELECT id, product_id, quantity_sent FROM stock_transfer_items WHERE transfer_id = $1 ORDER BY product_id", id,
+	)
+	if err != nil {
+		return nil, err
+	}
+	var items []models.StockTransferItem
+	for rows.Next() {
+		var item models.StockTransferItem
+		if err := rows.Scan(&item.ID, &item.ProductID, &item.QuantitySent); err != nil {
+			rows.Close()
+			return nil, err
+		}
+		items = append(items, item)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	for _, item := range items {
+		quantity := item.QuantitySent
+		var note string
+		if r, ok := received[item.ProductID]; ok {
+			quantity = roundQuantity(r.Quantity)
+			note = r.Note
+			delete(received, item.ProductID)
+		}
+
+		if _, err := tx.Exec("SELECT 1 FROM products WHERE id = $1 FOR UPDATE", item.ProductID); err != nil {
+			return nil, err
+		}
+
+		_, err := tx.Exec(`
+			UPDATE stock_transfer_items SET quantity_received = $1, discrepancy = $2, note = NULLIF($3, '')
+			WHERE id = $4
+		`, quantity, roundQuantity(quantity-item.QuantitySent), note, item.ID)
+		if err != nil {
+			return nil, err
+		}
+
+		if quantity == 0 {
+			continue
+		}
+
+		err = applyStockMovement(tx, &models.StockMovement{
+			OutletID:      toOutletID,
+			ProductID:     item.ProductID,
+			Quantity:      quantity,
+			Reason:        models.StockReasonTransferIn,
+			ReferenceType: "stock_transfer",
+			ReferenceID:   &id,
+		})
+		if err != nil {
+			return nil, err
+		}
+
+		if err := receiveTransferLots(tx, item.ID, toOutletID, item.ProductID, quantity); err != nil {
+			return nil, err
+		}
+	}
+
+	if len(received) > 0 {
+		return nil, fmt.Errorf("received items are not part of stock transfer %d", id)
+	}
+
+	_, err = tx.Exec(
+		"UPDATE stock_transfers SET status = 'received', received_by = $1, received_at = NOW() WHERE id = $2",
+		receivedBy, id,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+
+	return repo.GetByID(id)
+}
+
+// receiveTransferLots membuat ulang lot yang dikirim di outlet tujuan dengan
+// nomor lot dan tanggal kedaluwarsa yang sama, lot terdekat kedaluwarsanya
+// diisi lebih dulu sampai jumlah yang diterima habis
+func receiveTransferLots(tx execer, itemID, outletID, productID int, quantity float64) error {
+	rows, err := tx.Query(`
+		SELECT l.lot_number, l.expiry_date, il.quantity
+		FROM stock_transfer_item_lots il
+		JOIN stock_lots l ON l.id = il.lot_id
+		WHERE il.transfer_item_id = $1
+		ORDER BY l.expiry_date NULLS LAST, l.id
+	`, itemID)
+	if err != nil {
+		return err
+	}
+
+	type lot struct {
+		number   sql.NullString
+		expiry   sql.NullTime
+		quantity float64
+	}
+	var lots []lot
+	for rows.Next() {
+		var l lot
+		if err := rows.Scan(&l.number, &l.expiry, &l.quantity); err != nil {
+			rows.Close()
+			return err
+		}
+		lots = append(lots, l)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	left := quantity
+	for _, l := range lots {
+		if left <= 0 {
+			break
+		}
+
+		take := min(l.quantity, left)
+		_, err := tx.Exec(`
+			INSERT INTO stock_lots (outlet_id, product_id, lot_number, expiry_date, quantity, remaining)
+			VALUES ($1, $2, $3, $4, $5, $5)
+		`, outletID, productID, l.number, l.expiry, take)
+		if err != nil {
+			return err
+		}
+		left = roundQuantity(left - take)
+	}
+
+	return nil
+}
+
+const transferColumns = `
+	t.id, t.from_outlet_id, fo.name, t.to_outlet_id, too.name, t.status, COALESCE(t.note, ''),
+	t.sent_by, COALESCE(t.received_by, ''), t.sent_at, t.received_at
+`
+
+const transferFrom = `
+	FROM stock_transfers t
+	JOIN outlets fo ON fo.id = t.from_outlet_id
+	JOIN outlets too ON too.id = t.to_outlet_id
+`
+
+func scanTransfer(row interface{ Scan(...interface{}) error }) (*models.StockTransfer, error) {
+	var t models.StockTransfer
+	var receivedAt sql.NullTime
+
+	err := row.Scan(&t.ID, &t.FromOutletID, &t.FromOutletName, &t.ToOutletID, &t.ToOutletName, &t.Status, &t.Note,
+		&t.SentBy, &t.ReceivedBy, &t.SentAt, &receivedAt)
+	if err != nil {
+		return nil, err
+	}
+	if receivedAt.Valid {
+		t.ReceivedAt = &receivedAt.Time
+	}
+
+	return &t, nil
+}
+
+func (repo *TransferRepository) GetByID(id int) (*models.StockTransfer, error) {
+	transfer, err := scanTransfer(repo.db.QueryRow("SELECT"+transferColumns+transferFrom+"WHERE t.id = $1", id))
+	if err == sql.ErrNoRows {
+		return nil, errors.New("mutasi stok tidak ditemukan")
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := repo.db.Query(`
+		SELECT i.id, i.transfer_id, i.product_id, p.name, i.quantity_sent, i.quantity_received, i.discrepancy, COALESCE(i.note, '')
+		FROM stock_transfer_items i
+		JOIN products p ON p.id = i.product_id
+		WHERE i.transfer_id = $1
+		ORDER BY i.id
+	`, id)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	transfer.Items = make([]models.StockTransferItem, 0)
+	for rows.Next() {
+		var i models.StockTransferItem
+		var quantityReceived, discrepancy sql.NullFloat64
+		err := rows.Scan(&i.ID, &i.TransferID, &i.ProductID, &i.ProductName, &i.QuantitySent, &quantityReceived,
+			&discrepancy, &i.Note)
+		if err != nil {
+			return nil, err
+		}
+		if quantityReceived.Valid {
+			i.QuantityReceived = &quantityReceived.Float64
+		}
+		if discrepancy.Valid {
+			i.Discrepancy = &discrepancy.Float64
+		}
+		transfer.Items = append(transfer.Items, i)
+	}
+
+	return transfer, rows.Err()
+}
+
+// GetAll tanpa item, filter OutletID mencocokkan outlet asal maupun tujuan
+func (repo *TransferRepository) GetAll(filter models.StockTransferFilter) ([]models.StockTransfer, error) {
+	conditions := []string{}
+	args := []interface{}{}
+
+	if filter.Status != "" {
+		args = append(args, filter.Status)
+		conditions = append(conditions, fmt.Sprintf("t.status = $%d", len(args)))
+	}
+	if filter.OutletID != 0 {
+		args = append(args, filter.OutletID)
+		conditions = append(conditions, fmt.Sprintf("(t.from_outlet_id = $%d OR t.to_outlet_id = $%d)", len(args), len(args)))
+	}
+
+	query := "SELECT" + transferColumns + transferFrom
+	if len(conditions) > 0 {
+		query += " WHERE " + strings.Join(conditions, " AND ")
+	}
+	query += " ORDER BY t.sent_at DESC, t.id DESC"
+
+	rows, err := repo.db.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	transfers := make([]models.StockTransfer, 0)
+	for rows.Next() {
+		t, err := scanTransfer(rows)
+		if err != nil {
+			return nil, err
+		}
+		transfers = append(transfers, *t)
+	}
+
+	return transfers, rows.Err()
+}
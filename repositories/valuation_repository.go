@@ -0,0 +1,140 @@
+package repositories
+
+import (
+	"database/sql"
+	"math"
+	"sort"
+	"time"
+
+	"github.com/anggakrnwn/kasir-api/models"
+)
+
+type ValuationRepository struct {
+	db *sql.DB
+}
+
+func NewValuationRepository(db *sql.DB) *ValuationRepository {
+	return &ValuationRepository{db: db}
+}
+
+// GetInventoryValuation menilai persediaan pada akhir date (YYYY-MM-DD), yaitu
+// semua mutasi sebelum end (awal hari berikutnya di zona waktu toko), dengan
+// memutar ulang stock ledger per produk. Mutasi antar outlet ikut diputar:
+// barang dalam perjalanan tidak termasuk persediaan (sama seperti stok
+// produk), dan stok yang diterima dinilai dengan harga pokok saat dikirim
+// sehingga selisih kurang terima mengurangi nilai persediaan. Stok masuk lain
+// tanpa harga pokok dinilai dengan harga pokok berjalan, atau harga pokok
+// produk saat ini jika belum ada.
+func (repo *ValuationRepository) GetInventoryValuation(date string, end time.Time, method string) (*models.InventoryValuation, error) {
+	rows, err := repo.db.Query(`
+		SELECT p.id, p.name, p.unit, p.category_id, COALESCE(c.name, ''), p.cost, m.quantity, m.unit_cost,
+			m.reason, m.reference_id
+		FROM stock_movements m
+		JOIN products p ON p.id = m.product_id
+		LEFT JOIN categories c ON c.id = p.category_id
+		WHERE m.created_at < $1
+		ORDER BY m.product_id, m.created_at, m.id
+	`, end)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	valuation := &models.InventoryValuation{
+		Date:       date,
+		Method:     method,
+		Categories: make([]models.CategoryValuation, 0),
+		Products:   make([]models.ProductValuation, 0),
+	}
+
+	var current *models.ProductValuation
+	var replay *costReplay
+	finish := func() {
+		if current == nil {
+			return
+		}
+		current.Quantity = roundQuantity(current.Quantity)
+		if current.Quantity == 0 {
+			return
+		}
+		current.Value = int(math.Round(replay.ledger.value()))
+		if current.Quantity > 0 {
+			current.UnitCost = math.Round(float64(current.Value)/current.Quantity*100) / 100
+		}
+		valuation.Products = append(valuation.Products, *current)
+	}
+
+	for rows.Next() {
+		var p models.ProductValuation
+		var categoryID sql.NullInt64
+		var productCost, quantity float64
+		var unitCost sql.NullFloat64
+		var reason string
+		var referenceID sql.NullInt64
+		err := rows.Scan(&p.ProductID, &p.ProductName, &p.Unit, &categoryID, &p.CategoryName, &productCost, &quantity, &unitCost,
+			&reason, &referenceID)
+		if err != nil {
+			return nil, err
+		}
+
+		if current == nil || current.ProductID != p.ProductID {
+			finish()
+			if categoryID.Valid {
+				v := int(categoryID.Int64)
+				p.CategoryID = &v
+			}
+			current = &p
+			replay = newCostReplay(method, productCost)
+		}
+
+		current.Quantity += quantity
+		replay.apply(quantity, unitCost, reason, referenceID)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	finish()
+
+	valuation.Categories = rollupCategories(valuation.Products)
+	for _, c := range valuation.Categories {
+		valuation.TotalValue += c.Value
+	}
+
+	sort.Slice(valuation.Products, func(i, j int) bool {
+		return valuation.Products[i].Value > valuation.Products[j].Value
+	})
+
+	return valuation, nil
+}
+
+// rollupCategories menjumlahkan nilai per kategori, diurutkan dari nilai
+// terbesar. Produk tanpa kategori dikelompokkan sebagai "Tanpa Kategori".
+func rollupCategories(products []models.ProductValuation) []models.CategoryValuation {
+	index := make(map[int]int)
+	categories := make([]models.CategoryValuation, 0)
+	for _, p := range products {
+		key := 0
+		if p.CategoryID != nil {
+			key = *p.CategoryID
+		}
+
+		i, ok := index[key]
+		if !ok {
+			name := p.CategoryName
+			if p.CategoryID == nil {
+				name = "Tanpa Kategori"
+			}
+			categories = append(categories, models.CategoryValuation{CategoryID: p.CategoryID, CategoryName: name})
+			i = len(categories) - 1
+			index[key] = i
+		}
+
+		categories[i].Products++
+		categories[i].Quantity = roundQuantity(categories[i].Quantity + p.Quantity)
+		categories[i].Value += p.Value
+	}
+
+	sort.Slice(categories, func(i, j int) bool { return categories[i].Value > categories[j].Value })
+
+	return categories
+}
@@ -0,0 +1,100 @@
+package services
+
+import (
+	"encoding/json"
+	"errors"
+	"log"
+	"time"
+
+	"github.com/anggakrnwn/kasir-api/models"
+	"github.com/anggakrnwn/kasir-api/repositories"
+)
+
+const (
+	defaultAuditLimit = 50
+	maxAuditLimit     = 500
+	// panjang kolom audit_log.ip
+	maxAuditIPLength = 64
+)
+
+var (
+	ErrInvalidAuditLimit = errors.New("limit must be between 1 and 500")
+)
+
+type AuditService struct {
+	repo *repositories.AuditRepository
+	loc  *time.Location
+}
+
+// loc adalah zona waktu toko untuk batas start_date/end_date
+func NewAuditService(repo *repositories.AuditRepository, loc *time.Location) *AuditService {
+	return &AuditService{repo: repo, loc: loc}
+}
+
+// Record mencatat satu perubahan. before/after boleh nil (misalnya create tidak
+// punya before). Kegagalan menulis audit hanya di-log supaya tidak membatalkan
+// perubahan yang sudah tersimpan.
+func (s *AuditService) Record(actor models.Actor, action, entityType string, entityID int, before, after interface{}) {
+	entry := models.AuditEntry{
+		Actor:      actor.Name,
+		CashierID:  actor.CashierID,
+		APIKeyHint: actor.APIKeyHint,
+		Action:     action,
+		EntityType: entityType,
+		IP:         actor.IP,
+	}
+	if len(entry.IP) > maxAuditIPLength {
+		entry.IP = entry.IP[:maxAuditIPLength]
+	}
+	if entityID != 0 {
+		entry.EntityID = &entityID
+	}
+
+	var err error
+	if entry.Before, err = marshalAudit(before); err != nil {
+		log.Printf("audit: failed to encode before data for %s: %v", action, err)
+	}
+	if entry.After, err = marshalAudit(after); err != nil {
+		log.Printf("audit: failed to encode after data for %s: %v", action, err)
+	}
+
+	if err := s.repo.Create(&entry); err != nil {
+		log.Printf("audit: failed to record %s on %s %d: %v", action, entityType, entityID, err)
+	}
+}
+
+func (s *AuditService) GetAll(filter models.AuditFilter) ([]models.AuditEntry, error) {
+	if filter.Limit == 0 {
+		filter.Limit = defaultAuditLimit
+	}
+	if filter.Limit < 0 || filter.Limit > maxAuditLimit {
+		return nil, ErrInvalidAuditLimit
+	}
+	if filter.Offset < 0 {
+		filter.Offset = 0
+	}
+
+	if filter.StartDate != "" {
+		start, err := time.ParseInLocation("2006-01-02", filter.StartDate, s.loc)
+		if err != nil {
+			return nil, ErrInvalidDate
+		}
+		filter.Start = start
+	}
+	if filter.EndDate != "" {
+		end, err := time.ParseInLocation("2006-01-02", filter.EndDate, s.loc)
+		if err != nil {
+			return nil, ErrInvalidDate
+		}
+		filter.End = end.AddDate(0, 0, 1)
+	}
+
+	return s.repo.GetAll(filter)
+}
+
+func marshalAudit(v interface{}) (json.RawMessage, error) {
+	if v == nil {
+		return nil, nil
+	}
+	return json.Marshal(v)
+}
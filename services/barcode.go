@@ -0,0 +1,72 @@
+package services
+
+import (
+	"math"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// scaleBarcode adalah isi stiker EAN-13 dari timbangan dengan format
+// PP IIIII VVVVV C: PP prefix 20-29, IIIII kode PLU, VVVVV nilai (harga dalam
+// rupiah atau berat dalam gram) dan C check digit
+type scaleBarcode struct {
+	Prefix string
+	PLU    string
+	Value  int
+}
+
+// parseScaleBarcode mengembalikan false jika code bukan barcode timbangan
+// yang valid
+func parseScaleBarcode(code string) (*scaleBarcode, bool) {
+	if len(code) != 13 || code[0] != '2' || !isDigits(code) || !validEAN13(code) {
+		return nil, false
+	}
+
+	value, err := strconv.Atoi(code[7:12])
+	if err != nil {
+		return nil, false
+	}
+
+	return &scaleBarcode{Prefix: code[:2], PLU: code[2:7], Value: value}, true
+}
+
+// quantity menerjemahkan nilai stiker untuk produk dengan harga price yang
+// sedang berlaku (termasuk jadwal harga) dan satuan dasar unit. Prefix di
+// pricePrefixes memuat harga: jumlah dihitung balik dari price seperti di
+// checkout dan harga stiker dikembalikan sebagai embeddedPrice. Prefix lain
+// memuat berat dalam gram, dikonversi ke kg kecuali satuan dasar gram.
+func (b *scaleBarcode) quantity(price int, unit string, pricePrefixes []string) (float64, int) {
+	if slices.Contains(pricePrefixes, b.Prefix) {
+		return math.Round(float64(b.Value)/float64(price)*1000) / 1000, b.Value
+	}
+
+	switch strings.ToLower(unit) {
+	case "g", "gr", "gram":
+		return float64(b.Value), 0
+	default:
+		return float64(b.Value) / 1000, 0
+	}
+}
+
+func validEAN13(code string) bool {
+	sum := 0
+	for i := 0; i < 12; i++ {
+		digit := int(code[i] - '0')
+		if i%2 == 1 {
+			digit *= 3
+		}
+		sum += digit
+	}
+
+	return (10-sum%10)%10 == int(code[12]-'0')
+}
+
+func isDigits(s string) bool {
+	for _, c := range s {
+		if c < '0' || c > '9' {
+			return false
+		}
+	}
+	return s != ""
+}
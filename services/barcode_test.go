@@ -0,0 +1,123 @@
+package services
+
+import "testing"
+
+func TestValidEAN13(t *testing.T) {
+	tests := []struct {
+		code string
+		want bool
+	}{
+		{"4006381333931", true},
+		{"2212345012503", true},
+		{"2900000000001", true},
+		{"4006381333932", false},
+		{"2212345012500", false},
+		{"2900000000000", false},
+	}
+
+	for _, tt := range tests {
+		if got := validEAN13(tt.code); got != tt.want {
+			t.Errorf("validEAN13(%q) = %v, want %v", tt.code, got, tt.want)
+		}
+	}
+}
+
+func TestParseScaleBarcode(t *testing.T) {
+	tests := []struct {
+		name   string
+		code   string
+		ok     bool
+		prefix string
+		plu    string
+		value  int
+	}{
+		{"price sticker", "2212345012503", true, "22", "12345", 1250},
+		{"weight sticker", "2100123015009", true, "21", "00123", 1500},
+		{"leading zeros in value", "2000123002507", true, "20", "00123", 250},
+		{"bad check digit", "2212345012504", false, "", "", 0},
+		{"not a scale prefix", "4006381333931", false, "", "", 0},
+		{"too short", "221234501250", false, "", "", 0},
+		{"too long", "22123450125033", false, "", "", 0},
+		{"non digit", "22123A5012503", false, "", "", 0},
+		{"empty", "", false, "", "", 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := parseScaleBarcode(tt.code)
+			if ok != tt.ok {
+				t.Fatalf("parseScaleBarcode(%q) ok = %v, want %v", tt.code, ok, tt.ok)
+			}
+			if !ok {
+				return
+			}
+			if got.Prefix != tt.prefix || got.PLU != tt.plu || got.Value != tt.value {
+				t.Errorf("parseScaleBarcode(%q) = %+v, want prefix %s plu %s value %d",
+					tt.code, *got, tt.prefix, tt.plu, tt.value)
+			}
+		})
+	}
+}
+
+func TestScaleBarcodeQuantity(t *testing.T) {
+	pricePrefixes := []string{"22"}
+
+	tests := []struct {
+		name         string
+		barcode      scaleBarcode
+		price        int
+		unit         string
+		wantQuantity float64
+		wantEmbedded int
+	}{
+		{
+			name:         "price prefix converts price back to kg",
+			barcode:      scaleBarcode{Prefix: "22", Value: 21000},
+			price:        14000,
+			unit:         "kg",
+			wantQuantity: 1.5,
+			wantEmbedded: 21000,
+		},
+		{
+			// harga dasar 14000 sudah diganti jadwal harga 16000, stiker
+			// 24000 berarti 1.5 kg seperti yang dihitung checkout
+			name:         "price prefix uses the scheduled price in effect",
+			barcode:      scaleBarcode{Prefix: "22", Value: 24000},
+			price:        16000,
+			unit:         "kg",
+			wantQuantity: 1.5,
+			wantEmbedded: 24000,
+		},
+		{
+			name:         "price prefix rounds quantity to 3 decimals",
+			barcode:      scaleBarcode{Prefix: "22", Value: 10000},
+			price:        30000,
+			unit:         "kg",
+			wantQuantity: 0.333,
+			wantEmbedded: 10000,
+		},
+		{
+			name:         "weight prefix in grams for kg product",
+			barcode:      scaleBarcode{Prefix: "21", Value: 1250},
+			price:        14000,
+			unit:         "kg",
+			wantQuantity: 1.25,
+		},
+		{
+			name:         "weight prefix for gram product",
+			barcode:      scaleBarcode{Prefix: "20", Value: 250},
+			price:        150,
+			unit:         "Gram",
+			wantQuantity: 250,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			quantity, embedded := tt.barcode.quantity(tt.price, tt.unit, pricePrefixes)
+			if quantity != tt.wantQuantity || embedded != tt.wantEmbedded {
+				t.Errorf("quantity() = (%v, %d), want (%v, %d)", quantity, embedded, tt.wantQuantity, tt.wantEmbedded)
+			}
+		})
+	}
+}
@@ -0,0 +1,186 @@
+package services
+
+import (
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/anggakrnwn/kasir-api/models"
+	"github.com/anggakrnwn/kasir-api/repositories"
+)
+
+var (
+	ErrCartNotOpen       = errors.New("cart is not open")
+	ErrCartNotParked     = errors.New("cart is not parked")
+	ErrCartClosed        = errors.New("cart is already checked out or cancelled")
+	ErrCartHeldByOther   = errors.New("cart is being served by another cashier")
+	ErrCartOtherOutlet   = errors.New("cart belongs to another outlet")
+	ErrEmptyCart         = errors.New("cart has no items")
+	ErrInvalidCartStatus = errors.New("status must be open, parked, checked_out or cancelled")
+)
+
+type CartService struct {
+	repo         *repositories.CartRepository
+	transactions *TransactionService
+	reserveFor   time.Duration
+}
+
+// checkout keranjang memakai TransactionService supaya validasi, harga dan
+// pengurangan stok sama persis dengan checkout langsung. reserveFor adalah
+// masa berlaku reservasi stok keranjang, 0 berarti keranjang tidak menahan stok.
+func NewCartService(repo *repositories.CartRepository, transactions *TransactionService, reserveFor time.Duration) *CartService {
+	return &CartService{repo: repo, transactions: transactions, reserveFor: reserveFor}
+}
+
+// Create membuka keranjang baru di outlet kasir, dipegang kasir tersebut
+func (s *CartService) Create(req models.CartRequest, cashier *models.Cashier) (*models.Cart, error) {
+	req.Label = strings.TrimSpace(req.Label)
+
+	return s.repo.Create(req, cashier.OutletID, cashier.ID)
+}
+
+// AddItem menerima item seperti checkout, termasuk barcode dan stiker timbangan
+func (s *CartService) AddItem(id, cashierID int, item models.CheckoutItem) (*models.Cart, error) {
+	if err := s.transactions.resolveBarcodeItem(&item); err != nil {
+		return nil, err
+	}
+	if item.EmbeddedPrice == 0 && item.Quantity <= 0 {
+		return nil, ErrInvalidQuantity
+	}
+	if _, err := s.requireHeld(id, cashierID); err != nil {
+		return nil, err
+	}
+
+	return s.repo.AddItem(id, item, s.reserveFor)
+}
+
+func (s *CartService) RemoveItem(id, cashierID, itemID int) (*models.Cart, error) {
+	if _, err := s.requireHeld(id, cashierID); err != nil {
+		return nil, err
+	}
+
+	return s.repo.RemoveItem(id, itemID, s.reserveFor)
+}
+
+// Park menahan keranjang supaya kasir bisa melayani pelanggan berikutnya
+func (s *CartService) Park(id, cashierID int) (*models.Cart, error) {
+	cart, err := s.requireHeld(id, cashierID)
+	if err != nil {
+		return nil, err
+	}
+	if len(cart.Items) == 0 {
+		return nil, ErrEmptyCart
+	}
+
+	return s.repo.UpdateStatus(id, []string{models.CartStatusOpen}, models.CartStatusParked, cashierID, s.reserveFor)
+}
+
+// Resume melanjutkan keranjang yang ditahan, kasir yang melanjutkan menjadi
+// pemegangnya. Hanya kasir di outlet keranjang yang bisa melanjutkan karena
+// checkout selalu memakai outlet kasir.
+func (s *CartService) Resume(id int, cashier *models.Cashier) (*models.Cart, error) {
+	cart, err := s.GetByID(id, cashier)
+	if err != nil {
+		return nil, err
+	}
+	if cart.Status != models.CartStatusParked {
+		return nil, ErrCartNotParked
+	}
+
+	return s.repo.UpdateStatus(id, []string{models.CartStatusParked}, models.CartStatusOpen, cashier.ID, s.reserveFor)
+}
+
+// Cancel membatalkan keranjang open atau parked. cashier nil (pemilik) boleh
+// membatalkan keranjang siapa pun, kasir hanya keranjang di outletnya sendiri
+// karena pembatalan melepas reservasi stok outlet tersebut.
+func (s *CartService) Cancel(id int, cashier *models.Cashier) (*models.Cart, error) {
+	cart, err := s.GetByID(id, cashier)
+	if err != nil {
+		return nil, err
+	}
+
+	cashierID := 0
+	if cashier != nil {
+		cashierID = cashier.ID
+	}
+	switch {
+	case cart.Status != models.CartStatusOpen && cart.Status != models.CartStatusParked:
+		return nil, ErrCartClosed
+	case cart.Status == models.CartStatusOpen && cashierID != 0 && cart.CashierID != cashierID:
+		return nil, ErrCartHeldByOther
+	}
+
+	return s.repo.UpdateStatus(id, []string{models.CartStatusOpen, models.CartStatusParked}, models.CartStatusCancelled, cashierID, s.reserveFor)
+}
+
+// Checkout menyelesaikan keranjang lewat alur checkout biasa. Keranjang
+// ditandai checked_out dalam transaksi database yang sama.
+func (s *CartService) Checkout(id, cashierID int, req models.CartCheckoutRequest) (*models.Transaction, error) {
+	cart, err := s.requireHeld(id, cashierID)
+	if err != nil {
+		return nil, err
+	}
+	if len(cart.Items) == 0 {
+		return nil, ErrEmptyCart
+	}
+
+	checkout := models.CheckoutRequest{
+		Items:         make([]models.CheckoutItem, len(cart.Items)),
+		PaymentMethod: req.PaymentMethod,
+		AmountPaid:    req.AmountPaid,
+		CustomerID:    cart.CustomerID,
+		CartID:        cart.ID,
+	}
+	if req.CustomerID != nil {
+		checkout.CustomerID = req.CustomerID
+	}
+	for i, item := range cart.Items {
+		checkout.Items[i] = models.CheckoutItem{
+			ProductID:     item.ProductID,
+			Quantity:      item.Quantity,
+			Unit:          item.Unit,
+			EmbeddedPrice: item.EmbeddedPrice,
+		}
+	}
+
+	return s.transactions.Checkout(cashierID, checkout)
+}
+
+// requireHeld memastikan keranjang masih open dan dipegang cashierID
+func (s *CartService) requireHeld(id, cashierID int) (*models.Cart, error) {
+	cart, err := s.repo.GetByID(id)
+	if err != nil {
+		return nil, err
+	}
+	if cart.Status != models.CartStatusOpen {
+		return nil, ErrCartNotOpen
+	}
+	if cart.CashierID != cashierID {
+		return nil, ErrCartHeldByOther
+	}
+	return cart, nil
+}
+
+// GetByID mengembalikan ErrCartOtherOutlet jika kasir meminta keranjang
+// outlet lain, cashier nil berarti pemilik
+func (s *CartService) GetByID(id int, cashier *models.Cashier) (*models.Cart, error) {
+	cart, err := s.repo.GetByID(id)
+	if err != nil {
+		return nil, err
+	}
+	if cashier != nil && cart.OutletID != cashier.OutletID {
+		return nil, ErrCartOtherOutlet
+	}
+
+	return cart, nil
+}
+
+func (s *CartService) GetAll(filter models.CartFilter) ([]models.Cart, error) {
+	switch filter.Status {
+	case "", models.CartStatusOpen, models.CartStatusParked, models.CartStatusCheckedOut, models.CartStatusCancelled:
+	default:
+		return nil, ErrInvalidCartStatus
+	}
+
+	return s.repo.GetAll(filter)
+}
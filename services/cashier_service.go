@@ -0,0 +1,65 @@
+package services
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"strings"
+
+	"github.com/anggakrnwn/kasir-api/models"
+	"github.com/anggakrnwn/kasir-api/repositories"
+)
+
+var (
+	ErrInvalidCashierName = errors.New("cashier name cannot be empty")
+)
+
+type CashierService struct {
+	repo    *repositories.CashierRepository
+	outlets *OutletService
+}
+
+func NewCashierService(repo *repositories.CashierRepository, outlets *OutletService) *CashierService {
+	return &CashierService{repo: repo, outlets: outlets}
+}
+
+func (s *CashierService) GetAll() ([]models.Cashier, error) {
+	return s.repo.GetAll()
+}
+
+// Create membuat kasir baru beserta API key-nya. API key hanya dikembalikan
+// sekali di sini, yang disimpan di database hanya hash-nya.
+func (s *CashierService) Create(data *models.Cashier) error {
+	data.Name = strings.TrimSpace(data.Name)
+	if data.Name == "" {
+		return ErrInvalidCashierName
+	}
+
+	outletID, err := s.outlets.Resolve(data.OutletID)
+	if err != nil {
+		return err
+	}
+	data.OutletID = outletID
+
+	buf := make([]byte, 24)
+	if _, err := rand.Read(buf); err != nil {
+		return err
+	}
+	data.APIKey = "ksr_" + hex.EncodeToString(buf)
+
+	return s.repo.Create(data, hashAPIKey(data.APIKey))
+}
+
+func (s *CashierService) GetByAPIKey(apiKey string) (*models.Cashier, error) {
+	return s.repo.GetByAPIKeyHash(hashAPIKey(apiKey))
+}
+
+func (s *CashierService) Deactivate(id int) error {
+	return s.repo.SetActive(id, false)
+}
+
+func hashAPIKey(apiKey string) string {
+	sum := sha256.Sum256([]byte(apiKey))
+	return hex.EncodeToString(sum[:])
+}
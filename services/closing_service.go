@@ -0,0 +1,89 @@
+package services
+
+import (
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/anggakrnwn/kasir-api/models"
+	"github.com/anggakrnwn/kasir-api/repositories"
+)
+
+var (
+	ErrFutureClosingDate = errors.New("cannot close a business day that has not started yet")
+	ErrShiftsStillOpen   = repositories.ErrShiftsStillOpen
+)
+
+type ClosingService struct {
+	repo    *repositories.ClosingRepository
+	outlets *OutletService
+	loc     *time.Location
+}
+
+// loc adalah zona waktu toko, menentukan batas tanggal yang ditutup
+func NewClosingService(repo *repositories.ClosingRepository, outlets *OutletService, loc *time.Location) *ClosingService {
+	return &ClosingService{repo: repo, outlets: outlets, loc: loc}
+}
+
+// Close membuat laporan Z dan mengunci tanggal tersebut. Semua shift outlet
+// yang dibuka sebelum penutupan harus sudah ditutup (ErrShiftsStillOpen),
+// tanggal yang sudah ditutup mengembalikan ErrDayClosed.
+func (s *ClosingService) Close(req models.DailyClosingRequest, closedBy string) (*models.DailyClosing, error) {
+	outletID, err := s.outlets.Resolve(req.OutletID)
+	if err != nil {
+		return nil, err
+	}
+	req.OutletID = outletID
+	req.Note = strings.TrimSpace(req.Note)
+
+	if req.Date == "" {
+		req.Date = time.Now().In(s.loc).Format("2006-01-02")
+	}
+	start, end, err := parseDateRange(req.Date, req.Date, s.loc)
+	if err != nil {
+		return nil, err
+	}
+	if start.After(time.Now()) {
+		return nil, ErrFutureClosingDate
+	}
+
+	closing, err := s.repo.Close(req, start, end, closedBy)
+	if err != nil {
+		return nil, err
+	}
+	closing.ClosedAt = closing.ClosedAt.In(s.loc)
+
+	return closing, nil
+}
+
+func (s *ClosingService) GetByID(id int) (*models.DailyClosing, error) {
+	closing, err := s.repo.GetByID(id)
+	if err != nil {
+		return nil, err
+	}
+	closing.ClosedAt = closing.ClosedAt.In(s.loc)
+
+	return closing, nil
+}
+
+// GetAll dengan start_date dan end_date opsional (YYYY-MM-DD)
+func (s *ClosingService) GetAll(filter models.DailyClosingFilter) ([]models.DailyClosing, error) {
+	for _, date := range []string{filter.StartDate, filter.EndDate} {
+		if date == "" {
+			continue
+		}
+		if _, err := time.Parse("2006-01-02", date); err != nil {
+			return nil, ErrInvalidDate
+		}
+	}
+
+	closings, err := s.repo.GetAll(filter)
+	if err != nil {
+		return nil, err
+	}
+	for i := range closings {
+		closings[i].ClosedAt = closings[i].ClosedAt.In(s.loc)
+	}
+
+	return closings, nil
+}
@@ -0,0 +1,103 @@
+package services
+
+import (
+	"errors"
+	"strings"
+
+	"github.com/anggakrnwn/kasir-api/models"
+	"github.com/anggakrnwn/kasir-api/repositories"
+)
+
+var (
+	ErrInvalidCustomerName  = errors.New("customer name cannot be empty")
+	ErrInvalidGroupCode     = errors.New("customer group code and name cannot be empty")
+	ErrCustomerGroupMissing = errors.New("customer group not found")
+	ErrInvalidTaxID         = errors.New("tax_id (NPWP) must contain 15 or 16 digits")
+)
+
+type CustomerService struct {
+	repo *repositories.CustomerRepository
+}
+
+func NewCustomerService(repo *repositories.CustomerRepository) *CustomerService {
+	return &CustomerService{repo: repo}
+}
+
+func (s *CustomerService) GetAllGroups() ([]models.CustomerGroup, error) {
+	return s.repo.GetAllGroups()
+}
+
+func (s *CustomerService) CreateGroup(data *models.CustomerGroup) error {
+	data.Code = strings.ToLower(strings.TrimSpace(data.Code))
+	data.Name = strings.TrimSpace(data.Name)
+	if data.Code == "" || data.Name == "" {
+		return ErrInvalidGroupCode
+	}
+
+	return s.repo.CreateGroup(data)
+}
+
+func (s *CustomerService) GetAll(name string) ([]models.Customer, error) {
+	return s.repo.GetAll(name)
+}
+
+func (s *CustomerService) GetByID(id int) (*models.Customer, error) {
+	return s.repo.GetByID(id)
+}
+
+func (s *CustomerService) Create(data *models.Customer) error {
+	if err := s.validate(data); err != nil {
+		return err
+	}
+
+	return s.repo.Create(data)
+}
+
+func (s *CustomerService) Update(data *models.Customer) error {
+	if err := s.validate(data); err != nil {
+		return err
+	}
+
+	return s.repo.Update(data)
+}
+
+func (s *CustomerService) validate(data *models.Customer) error {
+	data.Name = strings.TrimSpace(data.Name)
+	if data.Name == "" {
+		return ErrInvalidCustomerName
+	}
+
+	data.Address = strings.TrimSpace(data.Address)
+	data.TaxID = strings.TrimSpace(data.TaxID)
+	if data.TaxID != "" && !validTaxID(data.TaxID) {
+		return ErrInvalidTaxID
+	}
+
+	if data.CustomerGroupID != nil {
+		exists, err := s.repo.GroupExists(*data.CustomerGroupID)
+		if err != nil {
+			return err
+		}
+		if !exists {
+			return ErrCustomerGroupMissing
+		}
+	}
+
+	return nil
+}
+
+// validTaxID menerima NPWP 15 digit (format lama, boleh dengan titik dan
+// strip) atau 16 digit (NIK/NPWP baru)
+func validTaxID(taxID string) bool {
+	digits := 0
+	for _, c := range taxID {
+		switch {
+		case c >= '0' && c <= '9':
+			digits++
+		case c == '.' || c == '-':
+		default:
+			return false
+		}
+	}
+	return digits == 15 || digits == 16
+}
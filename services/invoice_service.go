@@ -0,0 +1,206 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/anggakrnwn/kasir-api/models"
+	"github.com/anggakrnwn/kasir-api/repositories"
+)
+
+var (
+	ErrInvoiceRequiresCustomer = errors.New("invoice requires a transaction with customer_id")
+	ErrInvoiceNotIssued        = errors.New("invoice has not been issued for this transaction")
+)
+
+type InvoiceService struct {
+	repo  *repositories.InvoiceRepository
+	store models.StoreProfile
+	loc   *time.Location
+}
+
+// store adalah identitas toko di kop faktur, loc zona waktu toko untuk tahun
+// penomoran dan tanggal faktur
+func NewInvoiceService(repo *repositories.InvoiceRepository, store models.StoreProfile, loc *time.Location) *InvoiceService {
+	return &InvoiceService{repo: repo, store: store, loc: loc}
+}
+
+// Issue menerbitkan faktur untuk transaksi, atau mengembalikan faktur yang
+// sudah terbit. created true jika nomor faktur baru saja dibuat.
+func (s *InvoiceService) Issue(transaction *models.Transaction) (*models.Invoice, bool, error) {
+	if transaction.CustomerID == nil {
+		return nil, false, ErrInvoiceRequiresCustomer
+	}
+
+	invoice, created, err := s.repo.Issue(transaction.ID, time.Now().In(s.loc).Year())
+	if err != nil {
+		return nil, false, err
+	}
+	invoice.IssuedAt = invoice.IssuedAt.In(s.loc)
+
+	return invoice, created, nil
+}
+
+// Get mengembalikan faktur yang sudah terbit tanpa membuat nomor baru
+func (s *InvoiceService) Get(transaction *models.Transaction) (*models.Invoice, error) {
+	invoice, err := s.repo.GetByTransactionID(transaction.ID)
+	if err != nil {
+		return nil, err
+	}
+	if invoice == nil {
+		return nil, ErrInvoiceNotIssued
+	}
+	invoice.IssuedAt = invoice.IssuedAt.In(s.loc)
+
+	return invoice, nil
+}
+
+// tata letak faktur dalam point, A4 dengan margin 50
+const (
+	invoiceMargin = 50.0
+	invoiceBottom = pdfPageHeight - 70
+	invoiceRight  = pdfPageWidth - invoiceMargin
+)
+
+// RenderPDF membuat faktur A4: kop toko, nomor dan tanggal faktur, data
+// pelanggan, rincian item dan rincian PPN. Tabel item berlanjut ke halaman
+// berikutnya jika tidak muat.
+func (s *InvoiceService) RenderPDF(transaction *models.Transaction, invoice *models.Invoice) []byte {
+	doc := &pdfDocument{}
+	page := doc.addPage()
+
+	legalName := s.store.LegalName
+	if legalName == "" {
+		legalName = s.store.Name
+	}
+
+	// kop toko di kiri, judul dan nomor faktur di kanan
+	y := invoiceMargin + 12
+	page.text(invoiceMargin, y, 16, true, legalName)
+	page.textRight(invoiceRight, y, 18, true, "FAKTUR")
+	header := []string{}
+	if legalName != s.store.Name {
+		header = append(header, s.store.Name)
+	}
+	if s.store.Address != "" {
+		header = append(header, pdfWrap(s.store.Address, 280, 9, false)...)
+	}
+	if s.store.Phone != "" {
+		header = append(header, "Telp. "+s.store.Phone)
+	}
+	if s.store.TaxID != "" {
+		header = append(header, "NPWP "+s.store.TaxID)
+	}
+	transactionNumber := transaction.ReceiptNumber
+	if transactionNumber == "" {
+		transactionNumber = fmt.Sprintf("#%d", transaction.ID)
+	}
+	meta := [][2]string{
+		{"Nomor", invoice.Number},
+		{"Tanggal", invoice.IssuedAt.Format("02/01/2006")},
+		{"Transaksi", fmt.Sprintf("%s, %s", transactionNumber, transaction.CreatedAt.Format("02/01/2006 15:04"))},
+		{"Outlet", transaction.OutletName},
+	}
+	for i := 0; i < len(header) || i < len(meta); i++ {
+		y += 13
+		if i < len(header) {
+			page.text(invoiceMargin, y, 9, false, header[i])
+		}
+		if i < len(meta) {
+			page.textRight(invoiceRight-110, y, 9, false, meta[i][0])
+			page.textRight(invoiceRight, y, 9, true, meta[i][1])
+		}
+	}
+
+	// data pelanggan dari salinan saat faktur terbit
+	y += 28
+	page.text(invoiceMargin, y, 9, true, "Kepada")
+	y += 14
+	page.text(invoiceMargin, y, 11, true, invoice.CustomerName)
+	for _, line := range pdfWrap(invoice.CustomerAddress, 300, 9, false) {
+		y += 12
+		page.text(invoiceMargin, y, 9, false, line)
+	}
+	if invoice.CustomerTaxID != "" {
+		y += 12
+		page.text(invoiceMargin, y, 9, false, "NPWP "+invoice.CustomerTaxID)
+	}
+
+	// kolom tabel: No, Produk, Qty, Harga, Jumlah (tepi kanan untuk angka)
+	const (
+		colNo      = invoiceMargin
+		colProduct = invoiceMargin + 28
+		colQty     = invoiceRight - 230
+		colPrice   = invoiceRight - 110
+		colAmount  = invoiceRight
+	)
+	tableHeader := func() {
+		y += 26
+		page.text(colNo, y, 9, true, "No")
+		page.text(colProduct, y, 9, true, "Produk")
+		page.textRight(colQty, y, 9, true, "Qty")
+		page.textRight(colPrice, y, 9, true, "Harga")
+		page.textRight(colAmount, y, 9, true, "Jumlah")
+		y += 6
+		page.line(invoiceMargin, y, invoiceRight, y)
+	}
+	tableHeader()
+
+	for i, d := range transaction.Details {
+		names := pdfWrap(d.ProductName, colQty-colProduct-60, 9, false)
+		if y+float64(len(names))*12+4 > invoiceBottom {
+			page = doc.addPage()
+			y = invoiceMargin
+			tableHeader()
+		}
+
+		y += 14
+		page.text(colNo, y, 9, false, strconv.Itoa(i+1))
+		page.textRight(colQty, y, 9, false, strconv.FormatFloat(d.UnitQuantity, 'f', -1, 64)+" "+d.Unit)
+		page.textRight(colPrice, y, 9, false, formatRupiah(d.UnitPrice))
+		page.textRight(colAmount, y, 9, false, formatRupiah(d.Subtotal))
+		for j, name := range names {
+			if j > 0 {
+				y += 12
+			}
+			page.text(colProduct, y, 9, false, name)
+		}
+	}
+	y += 8
+	page.line(invoiceMargin, y, invoiceRight, y)
+
+	// rincian PPN: harga jual sudah termasuk PPN, DPP = total - PPN
+	totals := [][2]string{
+		{"Dasar Pengenaan Pajak (DPP)", formatRupiah(transaction.TotalAmount - transaction.TaxAmount)},
+		{fmt.Sprintf("PPN %s%%", strconv.FormatFloat(transaction.TaxRate, 'f', -1, 64)), formatRupiah(transaction.TaxAmount)},
+		{"Total", formatRupiah(transaction.TotalAmount)},
+		{"Dibayar (" + strings.ToUpper(transaction.PaymentMethod) + ")", formatRupiah(transaction.AmountPaid)},
+	}
+	if y+float64(len(totals))*14+40 > invoiceBottom {
+		page = doc.addPage()
+		y = invoiceMargin
+	}
+	for i, t := range totals {
+		y += 14
+		bold := i == 2
+		page.textRight(colPrice, y, 9, bold, t[0])
+		page.textRight(colAmount, y, 9, bold, t[1])
+	}
+
+	if transaction.CashierName != "" {
+		y += 30
+		page.text(invoiceMargin, y, 8, false, "Kasir: "+transaction.CashierName)
+	}
+
+	// nomor halaman di kaki setiap halaman
+	for i, content := range doc.pages {
+		footer := &pdfPage{buf: content}
+		footer.text(invoiceMargin, pdfPageHeight-40, 8, false, invoice.Number)
+		footer.textRight(invoiceRight, pdfPageHeight-40, 8, false, fmt.Sprintf("Halaman %d dari %d", i+1, len(doc.pages)))
+	}
+
+	return doc.bytes()
+}
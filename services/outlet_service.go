@@ -0,0 +1,92 @@
+package services
+
+import (
+	"errors"
+	"strings"
+
+	"github.com/anggakrnwn/kasir-api/models"
+	"github.com/anggakrnwn/kasir-api/repositories"
+)
+
+var (
+	ErrInvalidOutletName = errors.New("outlet name cannot be empty")
+	ErrInvalidOutlet     = errors.New("outlet not found or inactive")
+	ErrInvalidOutletType = errors.New("outlet type must be store or warehouse")
+	ErrInvalidOutletCode = errors.New("outlet code must be at most 20 letters, digits or dashes")
+)
+
+type OutletService struct {
+	repo *repositories.OutletRepository
+}
+
+func NewOutletService(repo *repositories.OutletRepository) *OutletService {
+	return &OutletService{repo: repo}
+}
+
+func (s *OutletService) GetAll() ([]models.Outlet, error) {
+	return s.repo.GetAll()
+}
+
+func (s *OutletService) GetByID(id int) (*models.Outlet, error) {
+	return s.repo.GetByID(id)
+}
+
+func (s *OutletService) Create(data *models.Outlet) error {
+	if err := prepareOutlet(data); err != nil {
+		return err
+	}
+
+	return s.repo.Create(data)
+}
+
+func (s *OutletService) Update(outlet *models.Outlet) error {
+	if err := prepareOutlet(outlet); err != nil {
+		return err
+	}
+
+	return s.repo.Update(outlet)
+}
+
+func prepareOutlet(outlet *models.Outlet) error {
+	outlet.Name = strings.TrimSpace(outlet.Name)
+	outlet.Address = strings.TrimSpace(outlet.Address)
+	if outlet.Name == "" {
+		return ErrInvalidOutletName
+	}
+
+	// kode dipakai di nomor struk, jadi dibatasi huruf besar, angka dan strip
+	outlet.Code = strings.ToUpper(strings.TrimSpace(outlet.Code))
+	if len(outlet.Code) > 20 {
+		return ErrInvalidOutletCode
+	}
+	for _, c := range outlet.Code {
+		if (c < 'A' || c > 'Z') && (c < '0' || c > '9') && c != '-' {
+			return ErrInvalidOutletCode
+		}
+	}
+
+	switch outlet.Type {
+	case "":
+		outlet.Type = models.OutletTypeStore
+	case models.OutletTypeStore, models.OutletTypeWarehouse:
+	default:
+		return ErrInvalidOutletType
+	}
+
+	return nil
+}
+
+// Resolve memvalidasi outlet tujuan perubahan stok atau penugasan kasir.
+// ID 0 berarti outlet bawaan.
+func (s *OutletService) Resolve(id int) (int, error) {
+	if id == 0 {
+		id = models.DefaultOutletID
+	}
+
+	outlet, err := s.repo.GetByID(id)
+	if err != nil || !outlet.Active {
+		return 0, ErrInvalidOutlet
+	}
+
+	return outlet.ID, nil
+}
@@ -1,55 +1,372 @@
-package services
-
-import (
-	"errors"
-	"strings"
-
-	"github.com/anggakrnwn/kasir-api/models"
-	"github.com/anggakrnwn/kasir-api/repositories"
-)
-
-var (
-	ErrInvalidProductName  = errors.New("product name cannot be empty")
-	ErrInvalidProductPrice = errors.New("product price must be greater than zero")
-	ErrInvalidProductStock = errors.New("product stock cannot be negative")
-)
-
-type ProductService struct {
-	repo *repositories.ProductRepository
-}
-
-func NewProductService(repo *repositories.ProductRepository) *ProductService {
-	return &ProductService{repo: repo}
-}
-
-func (s *ProductService) GetAll(name string) ([]models.Product, error) {
-	return s.repo.GetAll(name)
-
-}
-
-func (s *ProductService) Create(data *models.Product) error {
-
-	if strings.TrimSpace(data.Name) == "" {
-		return ErrInvalidProductName
-	}
-	if data.Price <= 0 {
-		return ErrInvalidProductPrice
-	}
-	if data.Stock < 0 {
-		return ErrInvalidProductStock
-	}
-
-	return s.repo.Create(data)
-}
-
-func (s *ProductService) GetByID(id int) (*models.Product, error) {
-	return s.repo.GetByID(id)
-}
-
-func (s *ProductService) Update(product *models.Product) error {
-	return s.repo.Update(product)
-}
-
-func (s *ProductService) Delete(id int) error {
-	return s.repo.Delete(id)
-}
+package services
+
+import (
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/anggakrnwn/kasir-api/models"
+	"github.com/anggakrnwn/kasir-api/repositories"
+)
+
+var (
+	ErrInvalidProductName  = errors.New("product name cannot be empty")
+	ErrInvalidProductPrice = errors.New("product price must be greater than zero")
+	ErrInvalidProductStock = errors.New("product stock cannot be negative")
+	ErrInvalidEffectiveAt  = errors.New("effective_from must be in the future")
+	ErrInvalidMinQuantity  = errors.New("tier min_quantity must be at least 1")
+	ErrInvalidParent       = errors.New("parent product must be an existing top-level product")
+	ErrParentHasVariants   = errors.New("a product with variants cannot become a variant")
+	ErrInvalidUnit         = errors.New("unit name cannot be empty or equal to the base unit, factor must be greater than 1")
+	ErrInvalidPLU          = errors.New("plu must be 5 digits and is only allowed for weighed products")
+	ErrBarcodeNotFound     = errors.New("barcode not found")
+	ErrInvalidBundle       = errors.New("a bundle cannot be weighed or have variants")
+	ErrNotBundle           = errors.New("product is not a bundle")
+	ErrInvalidComponents   = errors.New("bundle needs at least one component, each with quantity greater than zero and listed once")
+	ErrInvalidReorderLevel = errors.New("min_stock and reorder_qty cannot be negative")
+	ErrInvalidProductCost  = errors.New("product cost cannot be negative")
+	ErrInvalidCategoryName = errors.New("category name cannot be empty")
+	ErrCategoryMissing     = errors.New("category not found")
+	ErrProductHasHistory   = repositories.ErrProductHasHistory
+)
+
+const defaultProductUnit = "pcs"
+
+type ProductService struct {
+	repo          *repositories.ProductRepository
+	outlets       *OutletService
+	pricePrefixes []string
+}
+
+// pricePrefixes adalah prefix barcode timbangan yang memuat harga, bukan berat
+func NewProductService(repo *repositories.ProductRepository, outlets *OutletService, pricePrefixes []string) *ProductService {
+	return &ProductService{repo: repo, outlets: outlets, pricePrefixes: pricePrefixes}
+}
+
+func (s *ProductService) GetAll(name string) ([]models.Product, error) {
+	return s.repo.GetAll(name)
+
+}
+
+func (s *ProductService) GetAllCategories() ([]models.Category, error) {
+	return s.repo.GetAllCategories()
+}
+
+func (s *ProductService) CreateCategory(data *models.Category) error {
+	data.Name = strings.TrimSpace(data.Name)
+	if data.Name == "" {
+		return ErrInvalidCategoryName
+	}
+
+	return s.repo.CreateCategory(data)
+}
+
+func (s *ProductService) Create(data *models.Product) error {
+	if err := s.prepareProduct(data); err != nil {
+		return err
+	}
+
+	data.Unit = strings.TrimSpace(data.Unit)
+	if data.Unit == "" {
+		data.Unit = defaultProductUnit
+	}
+
+	if strings.TrimSpace(data.Name) == "" {
+		return ErrInvalidProductName
+	}
+	if data.Price <= 0 {
+		return ErrInvalidProductPrice
+	}
+	if data.Stock < 0 {
+		return ErrInvalidProductStock
+	}
+
+	return s.repo.Create(data)
+}
+
+func (s *ProductService) GetByID(id int) (*models.Product, error) {
+	return s.repo.GetByID(id)
+}
+
+func (s *ProductService) Update(product *models.Product) error {
+	if err := s.prepareProduct(product); err != nil {
+		return err
+	}
+
+	if product.Stock < 0 {
+		return ErrInvalidProductStock
+	}
+
+	product.Unit = strings.TrimSpace(product.Unit)
+	if product.Unit == "" {
+		current, err := s.repo.GetByID(product.ID)
+		if err != nil {
+			return err
+		}
+		product.Unit = current.Unit
+	}
+
+	if product.ParentID != nil {
+		hasVariants, err := s.repo.HasVariants(product.ID)
+		if err != nil {
+			return err
+		}
+		if hasVariants {
+			return ErrParentHasVariants
+		}
+	}
+
+	return s.repo.Update(product)
+}
+
+// prepareProduct merapikan input create/update: memvalidasi PLU, paket dan
+// outlet stok, juga produk induk beserta nama varian, misalnya induk "Aqua"
+// dengan variant_name "600ml" menjadi "Aqua 600ml"
+func (s *ProductService) prepareProduct(product *models.Product) error {
+	product.Variants = nil
+	product.Units = nil
+	product.SKU = strings.TrimSpace(product.SKU)
+	product.Barcode = strings.TrimSpace(product.Barcode)
+	product.VariantName = strings.TrimSpace(product.VariantName)
+	product.PLU = strings.TrimSpace(product.PLU)
+	product.Components = nil
+	product.OutletStocks = nil
+
+	outletID, err := s.outlets.Resolve(product.OutletID)
+	if err != nil {
+		return err
+	}
+	product.OutletID = outletID
+
+	if product.MinStock < 0 || product.ReorderQty < 0 {
+		return ErrInvalidReorderLevel
+	}
+	if product.Cost < 0 {
+		return ErrInvalidProductCost
+	}
+
+	product.CategoryName = ""
+	if product.CategoryID != nil {
+		exists, err := s.repo.CategoryExists(*product.CategoryID)
+		if err != nil {
+			return err
+		}
+		if !exists {
+			return ErrCategoryMissing
+		}
+	}
+
+	// stok paket selalu 0, yang berkurang saat terjual adalah stok komponennya
+	if product.IsBundle {
+		if product.IsWeighed {
+			return ErrInvalidBundle
+		}
+		if product.ID != 0 {
+			hasVariants, err := s.repo.HasVariants(product.ID)
+			if err != nil {
+				return err
+			}
+			if hasVariants {
+				return ErrInvalidBundle
+			}
+		}
+		product.Stock = 0
+		product.MinStock = 0
+		product.ReorderQty = 0
+	}
+
+	if product.PLU != "" && (len(product.PLU) != 5 || !isDigits(product.PLU) || !product.IsWeighed) {
+		return ErrInvalidPLU
+	}
+
+	if product.ParentID == nil {
+		return nil
+	}
+
+	if *product.ParentID == product.ID {
+		return ErrInvalidParent
+	}
+
+	parent, err := s.repo.GetByID(*product.ParentID)
+	if err != nil || parent.ParentID != nil {
+		return ErrInvalidParent
+	}
+
+	if strings.TrimSpace(product.Name) == "" && product.VariantName != "" {
+		product.Name = parent.Name + " " + product.VariantName
+	}
+
+	return nil
+}
+
+// ResolveBarcode mencari produk dari barcode produk biasa, atau dari stiker
+// timbangan (prefix 20-29) yang memuat kode PLU dan berat atau harga
+func (s *ProductService) ResolveBarcode(code string) (*models.BarcodeItem, error) {
+	code = strings.TrimSpace(code)
+
+	product, err := s.repo.GetByBarcode(code)
+	if err != nil {
+		return nil, err
+	}
+	if product != nil {
+		return &models.BarcodeItem{Barcode: code, Product: product, Quantity: 1}, nil
+	}
+
+	scale, ok := parseScaleBarcode(code)
+	if !ok {
+		return nil, ErrBarcodeNotFound
+	}
+
+	product, err = s.repo.GetByPLU(scale.PLU)
+	if err != nil {
+		return nil, err
+	}
+	if product == nil || !product.IsWeighed {
+		return nil, ErrBarcodeNotFound
+	}
+
+	// product.Price dari GetByPLU sudah harga yang berlaku sekarang, dihitung
+	// dengan ekspresi yang sama dengan checkout
+	item := &models.BarcodeItem{Barcode: code, Product: product, Scale: true}
+	item.Quantity, item.EmbeddedPrice = scale.quantity(product.Price, product.Unit, s.pricePrefixes)
+
+	return item, nil
+}
+
+func (s *ProductService) Delete(id int) error {
+	return s.repo.Delete(id)
+}
+
+func (s *ProductService) GetPriceHistory(id int) ([]models.ProductPrice, error) {
+	if _, err := s.repo.GetByID(id); err != nil {
+		return nil, err
+	}
+
+	return s.repo.GetPriceHistory(id)
+}
+
+// SchedulePrice menjadwalkan perubahan harga, checkout akan memakai harga ini
+// otomatis begitu effective_from terlewati
+func (s *ProductService) SchedulePrice(productID int, req models.SchedulePriceRequest) (*models.ProductPrice, error) {
+	if req.Price <= 0 {
+		return nil, ErrInvalidProductPrice
+	}
+	if !req.EffectiveFrom.After(time.Now()) {
+		return nil, ErrInvalidEffectiveAt
+	}
+
+	if _, err := s.repo.GetByID(productID); err != nil {
+		return nil, err
+	}
+
+	price := &models.ProductPrice{
+		ProductID:     productID,
+		Price:         req.Price,
+		EffectiveFrom: req.EffectiveFrom,
+	}
+	if err := s.repo.SchedulePrice(price); err != nil {
+		return nil, err
+	}
+
+	return price, nil
+}
+
+func (s *ProductService) CancelScheduledPrice(productID, priceID int) error {
+	return s.repo.CancelScheduledPrice(productID, priceID)
+}
+
+func (s *ProductService) GetPriceTiers(productID int) ([]models.PriceTier, error) {
+	if _, err := s.repo.GetByID(productID); err != nil {
+		return nil, err
+	}
+
+	return s.repo.GetPriceTiers(productID)
+}
+
+// SetPriceTier membuat tier baru atau mengganti harga tier dengan kombinasi
+// kelompok pelanggan dan min_quantity yang sama
+func (s *ProductService) SetPriceTier(tier *models.PriceTier) error {
+	if tier.MinQuantity < 1 {
+		return ErrInvalidMinQuantity
+	}
+	if tier.Price <= 0 {
+		return ErrInvalidProductPrice
+	}
+
+	if _, err := s.repo.GetByID(tier.ProductID); err != nil {
+		return err
+	}
+
+	return s.repo.CreatePriceTier(tier)
+}
+
+func (s *ProductService) DeletePriceTier(productID, tierID int) error {
+	return s.repo.DeletePriceTier(productID, tierID)
+}
+
+func (s *ProductService) GetUnits(productID int) ([]models.ProductUnit, error) {
+	if _, err := s.repo.GetByID(productID); err != nil {
+		return nil, err
+	}
+
+	return s.repo.GetUnits(productID)
+}
+
+// SetUnit membuat atau mengganti satuan tambahan dengan nama yang sama
+func (s *ProductService) SetUnit(unit *models.ProductUnit) error {
+	product, err := s.repo.GetByID(unit.ProductID)
+	if err != nil {
+		return err
+	}
+
+	unit.Name = strings.TrimSpace(unit.Name)
+	if unit.Name == "" || strings.EqualFold(unit.Name, product.Unit) || unit.Factor <= 1 {
+		return ErrInvalidUnit
+	}
+	if unit.Price != nil && *unit.Price <= 0 {
+		return ErrInvalidProductPrice
+	}
+
+	return s.repo.SetUnit(unit)
+}
+
+func (s *ProductService) DeleteUnit(productID, unitID int) error {
+	return s.repo.DeleteUnit(productID, unitID)
+}
+
+func (s *ProductService) GetComponents(bundleID int) ([]models.BundleComponent, error) {
+	product, err := s.repo.GetByID(bundleID)
+	if err != nil {
+		return nil, err
+	}
+	if !product.IsBundle {
+		return nil, ErrNotBundle
+	}
+
+	return s.repo.GetComponents(bundleID)
+}
+
+// SetComponents mengganti seluruh isi paket
+func (s *ProductService) SetComponents(bundleID int, components []models.BundleComponent) error {
+	if len(components) == 0 {
+		return ErrInvalidComponents
+	}
+
+	seen := make(map[int]bool)
+	for _, c := range components {
+		if c.Quantity <= 0 || c.ProductID == bundleID || seen[c.ProductID] {
+			return ErrInvalidComponents
+		}
+		seen[c.ProductID] = true
+	}
+
+	product, err := s.repo.GetByID(bundleID)
+	if err != nil {
+		return err
+	}
+	if !product.IsBundle {
+		return ErrNotBundle
+	}
+
+	return s.repo.SetComponents(bundleID, components)
+}
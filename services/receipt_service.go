@@ -0,0 +1,263 @@
+package services
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"html"
+	"strconv"
+	"strings"
+	"text/template"
+	"time"
+	"unicode/utf8"
+
+	"github.com/anggakrnwn/kasir-api/models"
+)
+
+var (
+	ErrInvalidReceiptFormat = errors.New("format must be escpos, text or html")
+	ErrInvalidPaperWidth    = errors.New("width must be 58 or 80")
+)
+
+// receiptColumns adalah jumlah karakter per baris (font A) untuk lebar
+// kertas printer thermal dalam mm
+var receiptColumns = map[int]int{58: 32, 80: 48}
+
+// penanda tebal memakai perintah ESC/POS ESC E n. Output text membuangnya,
+// output html menggantinya dengan <b>.
+const (
+	receiptBoldOn  = "\x1bE\x01"
+	receiptBoldOff = "\x1bE\x00"
+)
+
+// DefaultReceiptTemplate dipakai jika RECEIPT_TEMPLATE tidak diisi. Template
+// memakai text/template dengan data receiptView; fungsi tata letak (Center,
+// Columns, Wrap, Rule) menyesuaikan lebar kertas.
+const DefaultReceiptTemplate = `{{bold ($.Center .Store.Name)}}
+{{range .HeaderLines}}{{$.Center .}}
+{{end}}{{.Rule}}
+{{.Columns "No" .Number}}
+{{.Columns "Tanggal" (datetime .Transaction.CreatedAt)}}
+{{.Columns "Kasir" .Transaction.CashierName}}
+{{.Columns "Outlet" .Transaction.OutletName}}
+{{.Rule}}
+{{range .Transaction.Details}}{{$.Wrap .ProductName}}
+{{$.Columns (printf "  %s %s x %s" (qty .UnitQuantity) .Unit (money .UnitPrice)) (money .Subtotal)}}
+{{end}}{{.Rule}}
+{{bold (.Columns "TOTAL" (money .Transaction.TotalAmount))}}
+{{if .Transaction.TaxAmount}}{{.Columns (printf "Termasuk PPN %s%%" (qty .Transaction.TaxRate)) (money .Transaction.TaxAmount)}}
+{{end}}{{.Columns (upper .Transaction.PaymentMethod) (money .Transaction.AmountPaid)}}
+{{if .Transaction.ChangeAmount}}{{.Columns "Kembali" (money .Transaction.ChangeAmount)}}
+{{end}}{{.Rule}}
+{{range .Footer}}{{$.Center .}}
+{{end}}`
+
+type ReceiptService struct {
+	store      models.StoreProfile
+	footer     []string
+	paperWidth int
+	tmpl       *template.Template
+}
+
+// tmpl adalah isi template struk (lihat DefaultReceiptTemplate), paperWidth
+// adalah lebar kertas bawaan dalam mm
+func NewReceiptService(store models.StoreProfile, footer []string, tmpl string, paperWidth int) (*ReceiptService, error) {
+	if _, ok := receiptColumns[paperWidth]; !ok {
+		return nil, ErrInvalidPaperWidth
+	}
+
+	parsed, err := template.New("receipt").Funcs(template.FuncMap{
+		"bold":     func(s string) string { return receiptBoldOn + s + receiptBoldOff },
+		"money":    formatRupiah,
+		"qty":      func(q float64) string { return strconv.FormatFloat(q, 'f', -1, 64) },
+		"datetime": func(t time.Time) string { return t.Format("02/01/2006 15:04") },
+		"upper":    strings.ToUpper,
+	}).Parse(tmpl)
+	if err != nil {
+		return nil, fmt.Errorf("invalid receipt template: %w", err)
+	}
+
+	return &ReceiptService{store: store, footer: footer, paperWidth: paperWidth, tmpl: parsed}, nil
+}
+
+// Render mencetak struk transaksi dalam format escpos, text atau html.
+// paperWidth 0 memakai lebar bawaan. Semua terminal memakai template yang sama
+// sehingga struk yang dicetak identik.
+func (s *ReceiptService) Render(transaction *models.Transaction, format string, paperWidth int) ([]byte, string, error) {
+	if paperWidth == 0 {
+		paperWidth = s.paperWidth
+	}
+	columns, ok := receiptColumns[paperWidth]
+	if !ok {
+		return nil, "", ErrInvalidPaperWidth
+	}
+	switch format {
+	case "":
+		format = models.ReceiptFormatText
+	case models.ReceiptFormatText, models.ReceiptFormatHTML, models.ReceiptFormatESCPOS:
+	default:
+		return nil, "", ErrInvalidReceiptFormat
+	}
+
+	var buf bytes.Buffer
+	view := receiptView{Store: s.store, Footer: s.footer, Transaction: transaction, Width: columns}
+	if err := s.tmpl.Execute(&buf, view); err != nil {
+		return nil, "", err
+	}
+	text := buf.String()
+
+	switch format {
+	case models.ReceiptFormatText:
+		plain := strings.NewReplacer(receiptBoldOn, "", receiptBoldOff, "").Replace(text)
+		return []byte(plain), "text/plain; charset=utf-8", nil
+	case models.ReceiptFormatHTML:
+		body := strings.NewReplacer(receiptBoldOn, "<b>", receiptBoldOff, "</b>").Replace(html.EscapeString(text))
+		page := fmt.Sprintf(`<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>Struk #%d</title>
+<style>pre { font-family: monospace; width: %dch; margin: 0 auto; }</style>
+</head>
+<body><pre>%s</pre></body>
+</html>
+`, transaction.ID, columns, body)
+		return []byte(page), "text/html; charset=utf-8", nil
+	default:
+		return escposReceipt(text), "application/octet-stream", nil
+	}
+}
+
+// escposReceipt membungkus struk dengan inisialisasi printer (ESC @), feed dan
+// potong kertas (GS V 66). Karakter non-ASCII diganti '?' karena code page
+// bawaan printer thermal berbeda-beda.
+func escposReceipt(text string) []byte {
+	var buf bytes.Buffer
+	buf.WriteString("\x1b@")
+	for _, r := range text {
+		if r < utf8.RuneSelf {
+			buf.WriteByte(byte(r))
+		} else {
+			buf.WriteByte('?')
+		}
+	}
+	buf.WriteString("\x1dV\x42\x03")
+
+	return buf.Bytes()
+}
+
+// receiptView adalah data template struk. Fungsi tata letaknya memakai Width
+// karakter per baris.
+type receiptView struct {
+	Store       models.StoreProfile
+	Footer      []string
+	Transaction *models.Transaction
+	Width       int
+}
+
+// HeaderLines adalah alamat, telepon dan NPWP toko yang diisi
+func (v receiptView) HeaderLines() []string {
+	lines := make([]string, 0, 3)
+	if v.Store.Address != "" {
+		lines = append(lines, v.Store.Address)
+	}
+	if v.Store.Phone != "" {
+		lines = append(lines, "Telp. "+v.Store.Phone)
+	}
+	if v.Store.TaxID != "" {
+		lines = append(lines, "NPWP "+v.Store.TaxID)
+	}
+	return lines
+}
+
+// Number adalah nomor struk, atau id transaksi untuk transaksi lama yang
+// belum bernomor
+func (v receiptView) Number() string {
+	if v.Transaction.ReceiptNumber != "" {
+		return v.Transaction.ReceiptNumber
+	}
+	return fmt.Sprintf("#%d", v.Transaction.ID)
+}
+
+func (v receiptView) Rule() string {
+	return strings.Repeat("-", v.Width)
+}
+
+// Center meratakan tengah s, teks yang lebih panjang dari satu baris dipecah
+func (v receiptView) Center(s string) string {
+	lines := strings.Split(v.Wrap(s), "\n")
+	for i, line := range lines {
+		if pad := (v.Width - utf8.RuneCountInString(line)) / 2; pad > 0 {
+			lines[i] = strings.Repeat(" ", pad) + line
+		}
+	}
+	return strings.Join(lines, "\n")
+}
+
+// Columns menaruh left di kiri dan right rata kanan. Jika tidak muat dalam
+// satu baris, right ditulis rata kanan di baris berikutnya.
+func (v receiptView) Columns(left, right string) string {
+	gap := v.Width - utf8.RuneCountInString(left) - utf8.RuneCountInString(right)
+	if gap >= 1 {
+		return left + strings.Repeat(" ", gap) + right
+	}
+
+	pad := v.Width - utf8.RuneCountInString(right)
+	if pad < 0 {
+		pad = 0
+	}
+	if utf8.RuneCountInString(left) > v.Width {
+		left = v.Wrap(left)
+	}
+	return left + "\n" + strings.Repeat(" ", pad) + right
+}
+
+// Wrap memecah s per kata supaya setiap baris tidak lebih dari Width karakter
+func (v receiptView) Wrap(s string) string {
+	var lines []string
+	line := ""
+	for _, word := range strings.Fields(s) {
+		for utf8.RuneCountInString(word) > v.Width {
+			if line != "" {
+				lines = append(lines, line)
+				line = ""
+			}
+			runes := []rune(word)
+			lines = append(lines, string(runes[:v.Width]))
+			word = string(runes[v.Width:])
+		}
+		switch {
+		case line == "":
+			line = word
+		case utf8.RuneCountInString(line)+1+utf8.RuneCountInString(word) <= v.Width:
+			line += " " + word
+		default:
+			lines = append(lines, line)
+			line = word
+		}
+	}
+	if line != "" {
+		lines = append(lines, line)
+	}
+	return strings.Join(lines, "\n")
+}
+
+// formatRupiah memformat amount dengan pemisah ribuan titik, mis. Rp12.500
+func formatRupiah(amount int) string {
+	sign := ""
+	if amount < 0 {
+		sign = "-"
+		amount = -amount
+	}
+
+	digits := strconv.Itoa(amount)
+	var b strings.Builder
+	for i, d := range digits {
+		if i > 0 && (len(digits)-i)%3 == 0 {
+			b.WriteByte('.')
+		}
+		b.WriteRune(d)
+	}
+
+	return sign + "Rp" + b.String()
+}
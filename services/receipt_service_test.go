@@ -0,0 +1,211 @@
+package services
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+	"unicode/utf8"
+
+	"github.com/anggakrnwn/kasir-api/models"
+)
+
+func TestFormatRupiah(t *testing.T) {
+	tests := []struct {
+		amount int
+		want   string
+	}{
+		{0, "Rp0"},
+		{500, "Rp500"},
+		{12500, "Rp12.500"},
+		{100000, "Rp100.000"},
+		{1000000, "Rp1.000.000"},
+		{-2500, "-Rp2.500"},
+	}
+
+	for _, tt := range tests {
+		if got := formatRupiah(tt.amount); got != tt.want {
+			t.Errorf("formatRupiah(%d) = %q, want %q", tt.amount, got, tt.want)
+		}
+	}
+}
+
+func TestReceiptViewColumns(t *testing.T) {
+	v := receiptView{Width: 20}
+
+	tests := []struct {
+		name        string
+		left, right string
+		want        string
+	}{
+		{"fits on one line", "TOTAL", "Rp12.500", "TOTAL       Rp12.500"},
+		{"exactly one space", "Kembalian uang", "Rp500", "Kembalian uang Rp500"},
+		{"right moves to next line", "Kembalian uang tunai", "Rp500", "Kembalian uang tunai\n               Rp500"},
+		{"long left is wrapped", "Indomie Goreng Rendang Jumbo", "Rp3.500", "Indomie Goreng\nRendang Jumbo\n             Rp3.500"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := v.Columns(tt.left, tt.right); got != tt.want {
+				t.Errorf("Columns(%q, %q) = %q, want %q", tt.left, tt.right, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestReceiptViewWrap(t *testing.T) {
+	v := receiptView{Width: 10}
+
+	tests := []struct {
+		name string
+		s    string
+		want string
+	}{
+		{"short", "Kopi", "Kopi"},
+		{"breaks between words", "Kopi Susu Gula Aren", "Kopi Susu\nGula Aren"},
+		{"collapses spaces", "  Kopi   Susu  ", "Kopi Susu"},
+		{"splits long word", "Supercalifragilistic", "Supercalif\nragilistic"},
+		{"long word after short word", "Es Supercalifragilistic", "Es\nSupercalif\nragilistic"},
+		{"multibyte counted as one", "Kopi Café Latte", "Kopi Café\nLatte"},
+		{"empty", "", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := v.Wrap(tt.s); got != tt.want {
+				t.Errorf("Wrap(%q) = %q, want %q", tt.s, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestReceiptViewCenter(t *testing.T) {
+	v := receiptView{Width: 10}
+
+	tests := []struct {
+		s    string
+		want string
+	}{
+		{"Toko", "   Toko"},
+		{"Toko Maju Jaya", "Toko Maju\n   Jaya"},
+		{"0123456789", "0123456789"},
+	}
+
+	for _, tt := range tests {
+		if got := v.Center(tt.s); got != tt.want {
+			t.Errorf("Center(%q) = %q, want %q", tt.s, got, tt.want)
+		}
+	}
+}
+
+func receiptFixture() *models.Transaction {
+	return &models.Transaction{
+		ID:            42,
+		ReceiptNumber: "INV/PST/20240120/0007",
+		TotalAmount:   24500,
+		TaxRate:       11,
+		TaxAmount:     2428,
+		PaymentMethod: models.PaymentMethodCash,
+		AmountPaid:    50000,
+		ChangeAmount:  25500,
+		CashierName:   "Siti",
+		OutletName:    "Toko Pusat",
+		CreatedAt:     time.Date(2024, 1, 20, 9, 30, 0, 0, time.UTC),
+		Details: []models.TransactionDetail{
+			{ProductName: "Indomie Goreng Rendang <Jumbo>", UnitQuantity: 3, Unit: "pcs", UnitPrice: 3500, Subtotal: 10500},
+			{ProductName: "Beras Pandan Wangi", UnitQuantity: 1.25, Unit: "kg", UnitPrice: 11200, Subtotal: 14000},
+		},
+	}
+}
+
+func TestReceiptRender(t *testing.T) {
+	store := models.StoreProfile{Name: "Toko Maju", Address: "Jl. Merdeka 1", Phone: "0211234", TaxID: "01.234.567.8-901.000"}
+	service, err := NewReceiptService(store, []string{"Terima kasih"}, DefaultReceiptTemplate, 58)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	t.Run("text fits the paper and drops bold markers", func(t *testing.T) {
+		for _, width := range []int{58, 80} {
+			out, contentType, err := service.Render(receiptFixture(), models.ReceiptFormatText, width)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if contentType != "text/plain; charset=utf-8" {
+				t.Errorf("content type = %q", contentType)
+			}
+			if bytes.ContainsRune(out, 0x1b) {
+				t.Errorf("text receipt contains ESC/POS commands")
+			}
+			for _, line := range strings.Split(string(out), "\n") {
+				if utf8.RuneCountInString(line) > receiptColumns[width] {
+					t.Errorf("line %q is wider than %d columns", line, receiptColumns[width])
+				}
+			}
+
+			for _, want := range []string{"INV/PST/20240120/0007", "20/01/2024 09:30", "1.25 kg x Rp11.200", "Rp24.500", "Termasuk PPN 11%", "NPWP 01.234.567.8-901.000", "Terima kasih"} {
+				if !bytes.Contains(out, []byte(want)) {
+					t.Errorf("%dmm receipt missing %q:\n%s", width, want, out)
+				}
+			}
+		}
+	})
+
+	t.Run("html escapes content and keeps bold", func(t *testing.T) {
+		out, _, err := service.Render(receiptFixture(), models.ReceiptFormatHTML, 0)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !bytes.Contains(out, []byte("&lt;Jumbo&gt;")) {
+			t.Errorf("product name is not escaped")
+		}
+		if !bytes.Contains(out, []byte("<b>")) {
+			t.Errorf("bold markers were not converted")
+		}
+	})
+
+	t.Run("escpos wraps with init and cut", func(t *testing.T) {
+		tx := receiptFixture()
+		tx.Details[0].ProductName = "Kopi Café"
+		out, _, err := service.Render(tx, models.ReceiptFormatESCPOS, 0)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !bytes.HasPrefix(out, []byte("\x1b@")) || !bytes.HasSuffix(out, []byte("\x1dV\x42\x03")) {
+			t.Errorf("missing printer init or cut command")
+		}
+		if !bytes.Contains(out, []byte("Kopi Caf?")) {
+			t.Errorf("non-ASCII character was not replaced")
+		}
+	})
+
+	t.Run("no tax line without tax", func(t *testing.T) {
+		tx := receiptFixture()
+		tx.TaxAmount = 0
+		out, _, err := service.Render(tx, models.ReceiptFormatText, 0)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if bytes.Contains(out, []byte("PPN")) {
+			t.Errorf("receipt without tax shows a PPN line")
+		}
+	})
+
+	t.Run("invalid format and width", func(t *testing.T) {
+		if _, _, err := service.Render(receiptFixture(), "pdf", 0); err != ErrInvalidReceiptFormat {
+			t.Errorf("format pdf: err = %v, want %v", err, ErrInvalidReceiptFormat)
+		}
+		if _, _, err := service.Render(receiptFixture(), models.ReceiptFormatText, 76); err != ErrInvalidPaperWidth {
+			t.Errorf("width 76: err = %v, want %v", err, ErrInvalidPaperWidth)
+		}
+	})
+}
+
+func TestNewReceiptServiceInvalidTemplate(t *testing.T) {
+	if _, err := NewReceiptService(models.StoreProfile{}, nil, "{{.Nope", 58); err == nil {
+		t.Error("expected an error for a broken template")
+	}
+	if _, err := NewReceiptService(models.StoreProfile{}, nil, DefaultReceiptTemplate, 76); err != ErrInvalidPaperWidth {
+		t.Errorf("err = %v, want %v", err, ErrInvalidPaperWidth)
+	}
+}
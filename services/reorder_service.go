@@ -0,0 +1,110 @@
+package services
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/anggakrnwn/kasir-api/models"
+	"github.com/anggakrnwn/kasir-api/repositories"
+)
+
+var (
+	ErrNothingToReorder        = errors.New("no low-stock products to order at this outlet")
+	ErrInvalidStockAlertStatus = errors.New("status must be open, ordered or resolved")
+)
+
+type ReorderService struct {
+	repo       *repositories.ReorderRepository
+	outlets    *OutletService
+	webhookURL string
+	client     *http.Client
+}
+
+// webhookURL kosong berarti peringatan tidak dikirim ke luar
+func NewReorderService(repo *repositories.ReorderRepository, outlets *OutletService, webhookURL string, timeout time.Duration) *ReorderService {
+	return &ReorderService{
+		repo:       repo,
+		outlets:    outlets,
+		webhookURL: webhookURL,
+		client:     &http.Client{Timeout: timeout},
+	}
+}
+
+func (s *ReorderService) GetLowStock(outletID int) ([]models.LowStockItem, error) {
+	return s.repo.GetLowStock(outletID)
+}
+
+func (s *ReorderService) GetAlerts(filter models.StockAlertFilter) ([]models.StockAlert, error) {
+	switch filter.Status {
+	case "", models.StockAlertStatusOpen, models.StockAlertStatusOrdered, models.StockAlertStatusResolved:
+	default:
+		return nil, ErrInvalidStockAlertStatus
+	}
+
+	return s.repo.GetAlerts(filter)
+}
+
+// NotifyLowStock mengirim peringatan ke webhook di background supaya checkout
+// tidak menunggu. Kegagalan hanya dicatat di log, peringatan tetap tersimpan
+// di stock_alerts.
+func (s *ReorderService) NotifyLowStock(alerts []models.StockAlert) {
+	if s.webhookURL == "" || len(alerts) == 0 {
+		return
+	}
+
+	body, err := json.Marshal(map[string]interface{}{
+		"event":  "stock.low",
+		"alerts": alerts,
+	})
+	if err != nil {
+		log.Printf("low stock webhook: failed to encode alerts: %v", err)
+		return
+	}
+
+	go func() {
+		resp, err := s.client.Post(s.webhookURL, "application/json", bytes.NewReader(body))
+		if err != nil {
+			log.Printf("low stock webhook: %v", err)
+			return
+		}
+		resp.Body.Close()
+		if resp.StatusCode >= 300 {
+			log.Printf("low stock webhook: unexpected status %s", resp.Status)
+		}
+	}()
+}
+
+// CreateDraftPurchaseOrder membuat draft pesanan dari produk yang stoknya
+// rendah di outlet
+func (s *ReorderService) CreateDraftPurchaseOrder(req models.DraftPurchaseOrderRequest, createdBy string) (*models.PurchaseOrder, error) {
+	outletID, err := s.outlets.Resolve(req.OutletID)
+	if err != nil {
+		return nil, err
+	}
+	req.OutletID = outletID
+	req.Supplier = strings.TrimSpace(req.Supplier)
+	req.Note = strings.TrimSpace(req.Note)
+
+	po, err := s.repo.CreateDraftPurchaseOrder(req, createdBy)
+	if err != nil {
+		return nil, err
+	}
+	if po == nil {
+		return nil, ErrNothingToReorder
+	}
+
+	return po, nil
+}
+
+func (s *ReorderService) GetPurchaseOrder(id int) (*models.PurchaseOrder, error) {
+	return s.repo.GetPurchaseOrder(id)
+}
+
+func (s *ReorderService) GetPurchaseOrders(outletID int) ([]models.PurchaseOrder, error) {
+	return s.repo.GetPurchaseOrders(outletID)
+}
@@ -0,0 +1,121 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/anggakrnwn/kasir-api/models"
+	"github.com/anggakrnwn/kasir-api/repositories"
+)
+
+var (
+	ErrReservationsDisabled     = errors.New("stock reservations are disabled, set STOCK_RESERVATION_TTL to enable them")
+	ErrEmptyReservation         = errors.New("reservation must contain at least one item")
+	ErrInvalidReservationTTL    = errors.New("ttl_minutes cannot be negative")
+	ErrReservationNotActive     = repositories.ErrReservationNotActive
+	ErrInvalidReservationStatus = errors.New("status must be active, released, expired or consumed")
+)
+
+type ReservationService struct {
+	repo    *repositories.ReservationRepository
+	outlets *OutletService
+	ttl     time.Duration
+}
+
+// ttl adalah masa berlaku reservasi bawaan, 0 berarti mode reservasi mati
+func NewReservationService(repo *repositories.ReservationRepository, outlets *OutletService, ttl time.Duration) *ReservationService {
+	return &ReservationService{repo: repo, outlets: outlets, ttl: ttl}
+}
+
+// Create menahan stok untuk pesanan online sampai dibayar lewat checkout
+// dengan reservation_id, dilepas, atau kedaluwarsa
+func (s *ReservationService) Create(req models.StockReservationRequest, createdBy string) (*models.StockReservation, error) {
+	if s.ttl == 0 {
+		return nil, ErrReservationsDisabled
+	}
+	if len(req.Items) == 0 {
+		return nil, ErrEmptyReservation
+	}
+	for _, item := range req.Items {
+		if item.Quantity <= 0 {
+			return nil, ErrInvalidQuantity
+		}
+	}
+	if req.TTLMinutes < 0 {
+		return nil, ErrInvalidReservationTTL
+	}
+
+	outletID, err := s.outlets.Resolve(req.OutletID)
+	if err != nil {
+		return nil, err
+	}
+	req.OutletID = outletID
+	req.Reference = strings.TrimSpace(req.Reference)
+
+	ttl := s.ttl
+	if req.TTLMinutes > 0 {
+		ttl = time.Duration(req.TTLMinutes) * time.Minute
+	}
+
+	return s.repo.Create(req, createdBy, ttl)
+}
+
+func (s *ReservationService) Release(id int) (*models.StockReservation, error) {
+	reservation, err := s.repo.GetByID(id)
+	if err != nil {
+		return nil, err
+	}
+	if reservation.Status != models.ReservationStatusActive {
+		return nil, ErrReservationNotActive
+	}
+
+	return s.repo.Release(id)
+}
+
+// StartSweeper menandai reservasi yang lewat masa berlakunya sebagai expired
+// setiap interval sampai ctx selesai. Stok tersedia sudah tidak dikurangi
+// reservasi kedaluwarsa sejak expires_at, sweeper merapikan statusnya.
+func (s *ReservationService) StartSweeper(ctx context.Context, interval time.Duration) {
+	if interval <= 0 {
+		return
+	}
+
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				expired, err := s.repo.ExpireDue()
+				if err != nil {
+					log.Printf("reservation sweeper: %v", err)
+					continue
+				}
+				if expired > 0 {
+					log.Printf("reservation sweeper: released %d expired reservations", expired)
+				}
+			}
+		}
+	}()
+}
+
+func (s *ReservationService) GetByID(id int) (*models.StockReservation, error) {
+	return s.repo.GetByID(id)
+}
+
+func (s *ReservationService) GetAll(filter models.StockReservationFilter) ([]models.StockReservation, error) {
+	switch filter.Status {
+	case "", models.ReservationStatusActive, models.ReservationStatusReleased,
+		models.ReservationStatusExpired, models.ReservationStatusConsumed:
+	default:
+		return nil, ErrInvalidReservationStatus
+	}
+
+	return s.repo.GetAll(filter)
+}
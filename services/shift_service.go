@@ -0,0 +1,163 @@
+package services
+
+import (
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/anggakrnwn/kasir-api/models"
+	"github.com/anggakrnwn/kasir-api/repositories"
+)
+
+var (
+	ErrInvalidOpeningFloat = errors.New("opening float cannot be negative")
+	ErrInvalidCountedCash  = errors.New("counted cash cannot be negative")
+	ErrShiftAlreadyOpen    = errors.New("cashier already has an open shift")
+	ErrNoOpenShift         = errors.New("no open shift for this cashier")
+	ErrInvalidCashAmount   = errors.New("cash amount must be greater than zero")
+	ErrEmptyCashReason     = errors.New("reason cannot be empty")
+	ErrShiftNotOpen        = repositories.ErrShiftClosed
+)
+
+type ShiftService struct {
+	repo          *repositories.ShiftRepository
+	cashMovements *repositories.CashMovementRepository
+	loc           *time.Location
+}
+
+// loc adalah zona waktu toko, dipakai untuk menolak pay-in/pay-out pada
+// tanggal yang sudah tutup hari
+func NewShiftService(repo *repositories.ShiftRepository, cashMovements *repositories.CashMovementRepository, loc *time.Location) *ShiftService {
+	return &ShiftService{repo: repo, cashMovements: cashMovements, loc: loc}
+}
+
+func (s *ShiftService) Open(cashierID int, req models.OpenShiftRequest) (*models.Shift, error) {
+	if req.OpeningFloat < 0 {
+		return nil, ErrInvalidOpeningFloat
+	}
+
+	current, err := s.repo.GetOpenByCashier(cashierID)
+	if err != nil {
+		return nil, err
+	}
+	if current != nil {
+		return nil, ErrShiftAlreadyOpen
+	}
+
+	shift := &models.Shift{
+		CashierID:    cashierID,
+		OpeningFloat: req.OpeningFloat,
+	}
+	opened, err := s.repo.Open(shift)
+	if err != nil {
+		return nil, err
+	}
+	if !opened {
+		return nil, ErrShiftAlreadyOpen
+	}
+
+	return s.repo.GetByID(shift.ID)
+}
+
+func (s *ShiftService) Close(cashierID int, req models.CloseShiftRequest) (*models.ShiftReport, error) {
+	if req.CountedCash < 0 {
+		return nil, ErrInvalidCountedCash
+	}
+
+	current, err := s.GetOpen(cashierID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.repo.Close(current.ID, req.CountedCash, strings.TrimSpace(req.Note)); err != nil {
+		return nil, err
+	}
+
+	return s.GetReport(current.ID)
+}
+
+// ForceClose dipakai owner untuk menutup shift yang ditinggal kasir, mis.
+// kasir lupa menutup shift atau sudah dinonaktifkan
+func (s *ShiftService) ForceClose(id int, req models.CloseShiftRequest) (*models.ShiftReport, error) {
+	if req.CountedCash < 0 {
+		return nil, ErrInvalidCountedCash
+	}
+
+	if err := s.repo.Close(id, req.CountedCash, strings.TrimSpace(req.Note)); err != nil {
+		return nil, err
+	}
+
+	return s.GetReport(id)
+}
+
+func (s *ShiftService) GetOpen(cashierID int) (*models.Shift, error) {
+	current, err := s.repo.GetOpenByCashier(cashierID)
+	if err != nil {
+		return nil, err
+	}
+	if current == nil {
+		return nil, ErrNoOpenShift
+	}
+
+	return current, nil
+}
+
+func (s *ShiftService) GetCurrentReport(cashierID int) (*models.ShiftReport, error) {
+	current, err := s.GetOpen(cashierID)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.GetReport(current.ID)
+}
+
+func (s *ShiftService) GetReport(id int) (*models.ShiftReport, error) {
+	report, err := s.repo.GetReport(id)
+	if err != nil {
+		return nil, err
+	}
+
+	report.CashMovements, err = s.cashMovements.GetByShift(id)
+	if err != nil {
+		return nil, err
+	}
+
+	return report, nil
+}
+
+func (s *ShiftService) PayIn(cashierID int, req models.CashMovementRequest) (*models.CashMovement, error) {
+	return s.recordCashMovement(cashierID, models.CashMovementPayIn, req)
+}
+
+func (s *ShiftService) PayOut(cashierID int, req models.CashMovementRequest) (*models.CashMovement, error) {
+	return s.recordCashMovement(cashierID, models.CashMovementPayOut, req)
+}
+
+func (s *ShiftService) recordCashMovement(cashierID int, movementType string, req models.CashMovementRequest) (*models.CashMovement, error) {
+	if req.Amount <= 0 {
+		return nil, ErrInvalidCashAmount
+	}
+
+	reason := strings.TrimSpace(req.Reason)
+	if reason == "" {
+		return nil, ErrEmptyCashReason
+	}
+
+	current, err := s.GetOpen(cashierID)
+	if err != nil {
+		return nil, err
+	}
+
+	movement := &models.CashMovement{
+		ShiftID:   current.ID,
+		CashierID: cashierID,
+		Type:      movementType,
+		Amount:    req.Amount,
+		Reason:    reason,
+	}
+	if err := s.cashMovements.Create(movement, s.loc); err != nil {
+		return nil, err
+	}
+
+	return movement, nil
+}
@@ -0,0 +1,127 @@
+package services
+
+import (
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/anggakrnwn/kasir-api/models"
+	"github.com/anggakrnwn/kasir-api/repositories"
+)
+
+const (
+	defaultMovementLimit = 100
+	maxMovementLimit     = 1000
+	defaultExpiringDays  = 30
+)
+
+var (
+	ErrEmptyReceipt         = errors.New("receipt must contain at least one item")
+	ErrInvalidMovementLimit = errors.New("limit must be between 1 and 1000")
+	ErrInvalidExpiryDate    = errors.New("expiry_date must use YYYY-MM-DD format")
+	ErrInvalidDays          = errors.New("days cannot be negative")
+	ErrInvalidUnitCost      = errors.New("unit_cost cannot be negative")
+	ErrInvalidCostingMethod = errors.New("method must be average or fifo")
+)
+
+type StockService struct {
+	repo          *repositories.StockRepository
+	valuations    *repositories.ValuationRepository
+	outlets       *OutletService
+	costingMethod string
+	loc           *time.Location
+}
+
+// costingMethod adalah metode penilaian persediaan bawaan, average atau fifo.
+// loc adalah zona waktu toko untuk menentukan "hari ini" dan akhir hari.
+func NewStockService(repo *repositories.StockRepository, valuations *repositories.ValuationRepository, outlets *OutletService, costingMethod string, loc *time.Location) *StockService {
+	return &StockService{repo: repo, valuations: valuations, outlets: outlets, costingMethod: costingMethod, loc: loc}
+}
+
+func (s *StockService) CreateReceipt(req models.StockReceiptRequest) (*models.StockReceipt, error) {
+	if len(req.Items) == 0 {
+		return nil, ErrEmptyReceipt
+	}
+	for i := range req.Items {
+		item := &req.Items[i]
+		if item.Quantity <= 0 {
+			return nil, ErrInvalidQuantity
+		}
+		if item.UnitCost < 0 {
+			return nil, ErrInvalidUnitCost
+		}
+
+		item.LotNumber = strings.TrimSpace(item.LotNumber)
+		item.ExpiryDate = strings.TrimSpace(item.ExpiryDate)
+		if item.ExpiryDate != "" {
+			if _, err := time.Parse("2006-01-02", item.ExpiryDate); err != nil {
+				return nil, ErrInvalidExpiryDate
+			}
+		}
+	}
+
+	outletID, err := s.outlets.Resolve(req.OutletID)
+	if err != nil {
+		return nil, err
+	}
+	req.OutletID = outletID
+
+	req.Supplier = strings.TrimSpace(req.Supplier)
+	req.Note = strings.TrimSpace(req.Note)
+
+	return s.repo.CreateReceipt(req)
+}
+
+func (s *StockService) GetReceipt(id int) (*models.StockReceipt, error) {
+	return s.repo.GetReceipt(id)
+}
+
+func (s *StockService) GetMovements(filter models.StockMovementFilter) ([]models.StockMovement, error) {
+	if filter.Limit == 0 {
+		filter.Limit = defaultMovementLimit
+	}
+	if filter.Limit < 0 || filter.Limit > maxMovementLimit {
+		return nil, ErrInvalidMovementLimit
+	}
+	if filter.Offset < 0 {
+		filter.Offset = 0
+	}
+
+	return s.repo.GetMovements(filter)
+}
+
+// GetExpiringLots mengembalikan lot yang kedaluwarsa dalam days hari ke depan
+// (default 30), termasuk yang sudah lewat, untuk ditarik dari rak. outletID 0
+// berarti semua outlet.
+func (s *StockService) GetExpiringLots(days *int, outletID int) ([]models.StockLot, error) {
+	today := time.Now().In(s.loc).Format("2006-01-02")
+	if days == nil {
+		return s.repo.GetExpiringLots(today, defaultExpiringDays, outletID)
+	}
+	if *days < 0 {
+		return nil, ErrInvalidDays
+	}
+
+	return s.repo.GetExpiringLots(today, *days, outletID)
+}
+
+// GetInventoryValuation menilai persediaan seluruh outlet pada akhir date
+// (default hari ini). method kosong memakai metode bawaan dari konfigurasi.
+func (s *StockService) GetInventoryValuation(date, method string) (*models.InventoryValuation, error) {
+	if date == "" {
+		date = time.Now().In(s.loc).Format("2006-01-02")
+	}
+	day, err := time.ParseInLocation("2006-01-02", date, s.loc)
+	if err != nil {
+		return nil, ErrInvalidDate
+	}
+
+	if method == "" {
+		method = s.costingMethod
+	}
+	if method != models.CostingAverage && method != models.CostingFIFO {
+		return nil, ErrInvalidCostingMethod
+	}
+
+	return s.valuations.GetInventoryValuation(date, day.AddDate(0, 0, 1), method)
+}
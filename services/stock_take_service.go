@@ -0,0 +1,162 @@
+package services
+
+import (
+	"errors"
+	"strings"
+
+	"github.com/anggakrnwn/kasir-api/models"
+	"github.com/anggakrnwn/kasir-api/repositories"
+)
+
+var (
+	ErrStockTakeNotOpen       = errors.New("stock take is no longer open")
+	ErrEmptyStockTakeCount    = errors.New("count must contain at least one item")
+	ErrInvalidDevice          = errors.New("device is required")
+	ErrInvalidCountedQuantity = errors.New("counted quantity cannot be negative")
+	ErrDuplicateStockTakeItem = errors.New("each product can only appear once in a count")
+	ErrNothingCounted         = errors.New("stock take has no counted items to post")
+	ErrInvalidStockTakeStatus = errors.New("status must be open, posted or cancelled")
+)
+
+type StockTakeService struct {
+	repo    *repositories.StockTakeRepository
+	outlets *OutletService
+}
+
+func NewStockTakeService(repo *repositories.StockTakeRepository, outlets *OutletService) *StockTakeService {
+	return &StockTakeService{repo: repo, outlets: outlets}
+}
+
+// Start membuka sesi opname dan men-snapshot stok sistem outlet
+func (s *StockTakeService) Start(req models.StockTakeRequest, startedBy string) (*models.StockTake, error) {
+	outletID, err := s.outlets.Resolve(req.OutletID)
+	if err != nil {
+		return nil, err
+	}
+	req.OutletID = outletID
+	req.Note = strings.TrimSpace(req.Note)
+
+	return s.repo.Start(req, startedBy)
+}
+
+// SubmitCounts menyimpan hitungan dari satu perangkat
+func (s *StockTakeService) SubmitCounts(id int, req models.StockTakeCountRequest, countedBy string) (*models.StockTake, error) {
+	req.Device = strings.TrimSpace(req.Device)
+	if req.Device == "" {
+		return nil, ErrInvalidDevice
+	}
+	if len(req.Items) == 0 {
+		return nil, ErrEmptyStockTakeCount
+	}
+
+	seen := make(map[int]bool)
+	for _, item := range req.Items {
+		if item.Quantity < 0 {
+			return nil, ErrInvalidCountedQuantity
+		}
+		if seen[item.ProductID] {
+			return nil, ErrDuplicateStockTakeItem
+		}
+		seen[item.ProductID] = true
+	}
+
+	if err := s.requireOpen(id); err != nil {
+		return nil, err
+	}
+
+	return s.repo.SubmitCounts(id, req, countedBy)
+}
+
+// Post menerapkan selisih hitung sebagai mutasi stok dengan alasan opname
+func (s *StockTakeService) Post(id int, postedBy string) (*models.StockTake, error) {
+	take, err := s.repo.GetByID(id)
+	if err != nil {
+		return nil, err
+	}
+	if take.Status != models.StockTakeStatusOpen {
+		return nil, ErrStockTakeNotOpen
+	}
+
+	counted := false
+	for _, item := range take.Items {
+		if item.CountedQuantity != nil {
+			counted = true
+			break
+		}
+	}
+	if !counted {
+		return nil, ErrNothingCounted
+	}
+
+	return s.repo.Post(id, postedBy)
+}
+
+func (s *StockTakeService) Cancel(id int) (*models.StockTake, error) {
+	if err := s.requireOpen(id); err != nil {
+		return nil, err
+	}
+
+	return s.repo.Cancel(id)
+}
+
+func (s *StockTakeService) requireOpen(id int) error {
+	take, err := s.repo.GetByID(id)
+	if err != nil {
+		return err
+	}
+	if take.Status != models.StockTakeStatusOpen {
+		return ErrStockTakeNotOpen
+	}
+	return nil
+}
+
+func (s *StockTakeService) GetByID(id int) (*models.StockTake, error) {
+	return s.repo.GetByID(id)
+}
+
+func (s *StockTakeService) GetAll(filter models.StockTakeFilter) ([]models.StockTake, error) {
+	switch filter.Status {
+	case "", models.StockTakeStatusOpen, models.StockTakeStatusPosted, models.StockTakeStatusCancelled:
+	default:
+		return nil, ErrInvalidStockTakeStatus
+	}
+
+	return s.repo.GetAll(filter)
+}
+
+// GetShrinkageReport menilai selisih opname. Sebelum diposting laporan
+// bersifat sementara memakai hitungan dan harga saat ini; item tanpa selisih
+// tidak ditampilkan.
+func (s *StockTakeService) GetShrinkageReport(id int) (*models.ShrinkageReport, error) {
+	take, err := s.repo.GetByID(id)
+	if err != nil {
+		return nil, err
+	}
+
+	report := &models.ShrinkageReport{
+		StockTakeID: take.ID,
+		OutletID:    take.OutletID,
+		Status:      take.Status,
+		Items:       make([]models.StockTakeItem, 0),
+	}
+	for _, item := range take.Items {
+		if item.CountedQuantity == nil {
+			report.UncountedItems++
+			continue
+		}
+		report.CountedItems++
+
+		if *item.Variance == 0 {
+			continue
+		}
+		if *item.VarianceValue < 0 {
+			report.TotalShrinkage -= *item.VarianceValue
+		} else {
+			report.TotalSurplus += *item.VarianceValue
+		}
+		report.Items = append(report.Items, item)
+	}
+	report.NetValue = report.TotalSurplus - report.TotalShrinkage
+
+	return report, nil
+}
@@ -1,26 +1,424 @@
-package services
-
-import (
-	"github.com/anggakrnwn/kasir-api/models"
-	"github.com/anggakrnwn/kasir-api/repositories"
-)
-
-type TransactionService struct {
-	repo *repositories.TransactionRepository
-}
-
-func NewTransactionService(repo *repositories.TransactionRepository) *TransactionService {
-	return &TransactionService{repo: repo}
-}
-
-func (s *TransactionService) Checkout(items []models.CheckoutItem) (*models.Transaction, error) {
-	return s.repo.CreateTransaction(items)
-}
-
-func (s *TransactionService) GetTodaySalesSummary() (*models.SalesSummary, error) {
-	return s.repo.GetTodaySalesSummary()
-}
-
-func (s *TransactionService) GetSalesReport(startDate, endDate string) (*models.SalesSummary, error) {
-	return s.repo.GetSalesReport(startDate, endDate)
-}
+package services
+
+import (
+	"errors"
+	"math"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/anggakrnwn/kasir-api/models"
+	"github.com/anggakrnwn/kasir-api/repositories"
+)
+
+var (
+	ErrEmptyCheckout        = errors.New("checkout must contain at least one item")
+	ErrInvalidQuantity      = errors.New("item quantity must be greater than zero")
+	ErrInvalidPaymentMethod = errors.New("payment method must be one of cash, qris, debit, credit, transfer")
+	ErrInvalidDate          = errors.New("date must use YYYY-MM-DD format")
+	ErrInvalidDateRange     = errors.New("start_date and end_date are required and end_date cannot be before start_date")
+	ErrInvalidGranularity   = errors.New("granularity must be hour, day, week or month")
+	ErrTooManyBuckets       = errors.New("date range is too long for this granularity")
+	ErrInvalidSort          = errors.New("sort must be revenue, quantity, transactions or name, order must be asc or desc")
+	ErrInvalidLimit         = errors.New("limit cannot be negative")
+	ErrInvalidAmountPaid    = errors.New("amount_paid cannot be negative")
+	ErrDayClosed            = repositories.ErrDayClosed
+
+	// dari checkout di repository, dibungkus dengan pesan rinci
+	ErrProductNotFound     = repositories.ErrProductNotFound
+	ErrProductHasVariants  = repositories.ErrProductHasVariants
+	ErrNotWeighed          = repositories.ErrNotWeighed
+	ErrUnknownUnit         = repositories.ErrUnknownUnit
+	ErrInsufficientStock   = repositories.ErrInsufficientStock
+	ErrEmptyBundle         = repositories.ErrEmptyBundle
+	ErrAmountPaidTooLow    = repositories.ErrAmountPaidTooLow
+	ErrCustomerNotFound    = repositories.ErrCustomerNotFound
+	ErrReservationNotFound = repositories.ErrReservationNotFound
+	ErrCartNotCheckoutable = repositories.ErrCartNotCheckoutable
+)
+
+// maxTrendBuckets membatasi panjang deret waktu, mis. granularity hour
+// maksimal sekitar 3 bulan
+const maxTrendBuckets = 2500
+
+type TransactionService struct {
+	repo          *repositories.TransactionRepository
+	shifts        *ShiftService
+	cashMovements *repositories.CashMovementRepository
+	products      *ProductService
+	reorder       *ReorderService
+	loc           *time.Location
+	taxRate       float64
+	receiptFormat string
+}
+
+// loc adalah zona waktu toko, dipakai untuk batas tanggal semua laporan.
+// taxRate adalah tarif PPN (persen) yang termasuk di harga jual.
+// receiptFormat adalah format nomor struk, mis. INV/{outlet}/{date}/{seq}.
+func NewTransactionService(repo *repositories.TransactionRepository, shifts *ShiftService, cashMovements *repositories.CashMovementRepository, products *ProductService, reorder *ReorderService, loc *time.Location, taxRate float64, receiptFormat string) *TransactionService {
+	return &TransactionService{repo: repo, shifts: shifts, cashMovements: cashMovements, products: products, reorder: reorder, loc: loc, taxRate: taxRate, receiptFormat: receiptFormat}
+}
+
+// Checkout hanya bisa dilakukan kasir yang sedang membuka shift
+func (s *TransactionService) Checkout(cashierID int, req models.CheckoutRequest) (*models.Transaction, error) {
+	if len(req.Items) == 0 {
+		return nil, ErrEmptyCheckout
+	}
+	for i := range req.Items {
+		if err := s.resolveBarcodeItem(&req.Items[i]); err != nil {
+			return nil, err
+		}
+		if req.Items[i].EmbeddedPrice == 0 && req.Items[i].Quantity <= 0 {
+			return nil, ErrInvalidQuantity
+		}
+	}
+
+	if req.PaymentMethod == "" {
+		req.PaymentMethod = models.PaymentMethodCash
+	}
+	switch req.PaymentMethod {
+	case models.PaymentMethodCash,
+		models.PaymentMethodQRIS,
+		models.PaymentMethodDebit,
+		models.PaymentMethodCredit,
+		models.PaymentMethodTransfer:
+	default:
+		return nil, ErrInvalidPaymentMethod
+	}
+	if req.AmountPaid < 0 {
+		return nil, ErrInvalidAmountPaid
+	}
+
+	shift, err := s.shifts.GetOpen(cashierID)
+	if err != nil {
+		return nil, err
+	}
+
+	req.CashierID = cashierID
+	req.ShiftID = shift.ID
+	req.OutletID = shift.OutletID
+	req.TaxRate = s.taxRate
+	req.ReceiptNumberFormat = s.receiptFormat
+	req.Location = s.loc
+
+	transaction, err := s.repo.CreateTransaction(req)
+	if err != nil {
+		return nil, err
+	}
+
+	s.reorder.NotifyLowStock(transaction.LowStockAlerts)
+
+	return transaction, nil
+}
+
+// resolveBarcodeItem mengisi produk dari barcode. Stiker timbangan menentukan
+// berat atau harga sendiri, barcode produk biasa memakai quantity dari request
+// (default 1).
+func (s *TransactionService) resolveBarcodeItem(item *models.CheckoutItem) error {
+	if item.Barcode == "" {
+		return nil
+	}
+
+	resolved, err := s.products.ResolveBarcode(item.Barcode)
+	if err != nil {
+		return err
+	}
+
+	item.ProductID = resolved.Product.ID
+	switch {
+	case resolved.EmbeddedPrice > 0:
+		item.Unit = ""
+		item.EmbeddedPrice = resolved.EmbeddedPrice
+	case resolved.Scale:
+		item.Unit = ""
+		item.Quantity = resolved.Quantity
+	case item.Quantity == 0:
+		item.Quantity = resolved.Quantity
+	}
+
+	return nil
+}
+
+// GetByID mengembalikan transaksi dengan created_at di zona waktu toko
+func (s *TransactionService) GetByID(id int) (*models.Transaction, error) {
+	transaction, err := s.repo.GetByID(id)
+	if err != nil {
+		return nil, err
+	}
+	transaction.CreatedAt = transaction.CreatedAt.In(s.loc)
+
+	return transaction, nil
+}
+
+// outletID 0 menghasilkan laporan gabungan semua outlet beserta rinciannya
+func (s *TransactionService) GetTodaySalesSummary(outletID int) (*models.SalesSummary, error) {
+	today := time.Now().In(s.loc).Format("2006-01-02")
+	return s.GetSalesReport(today, today, outletID)
+}
+
+func (s *TransactionService) GetSalesReport(startDate, endDate string, outletID int) (*models.SalesSummary, error) {
+	start, end, err := parseDateRange(startDate, endDate, s.loc)
+	if err != nil {
+		return nil, err
+	}
+
+	summary, err := s.repo.GetSalesReport(start, end, outletID)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.withOutlets(summary, start, end, outletID)
+}
+
+func (s *TransactionService) withOutlets(summary *models.SalesSummary, start, end time.Time, outletID int) (*models.SalesSummary, error) {
+	if outletID != 0 {
+		summary.OutletID = &outletID
+		return summary, nil
+	}
+
+	outlets, err := s.repo.GetOutletSales(start, end)
+	if err != nil {
+		return nil, err
+	}
+	summary.Outlets = outlets
+
+	return summary, nil
+}
+
+// GetCashFlowReport merangkum arus kas laci untuk satu tanggal (default hari
+// ini), outletID 0 berarti semua outlet
+func (s *TransactionService) GetCashFlowReport(date string, outletID int) (*models.CashFlowReport, error) {
+	if date == "" {
+		date = time.Now().In(s.loc).Format("2006-01-02")
+	}
+	start, end, err := parseDateRange(date, date, s.loc)
+	if err != nil {
+		return nil, err
+	}
+
+	sales, err := s.GetSalesReport(date, date, outletID)
+	if err != nil {
+		return nil, err
+	}
+
+	payments, err := s.repo.GetPaymentTotals(start, end, outletID)
+	if err != nil {
+		return nil, err
+	}
+
+	movements, err := s.cashMovements.GetByPeriod(start, end, outletID)
+	if err != nil {
+		return nil, err
+	}
+
+	report := models.CashFlowReport{
+		Date:      date,
+		OutletID:  sales.OutletID,
+		Sales:     sales,
+		Payments:  payments,
+		Movements: movements,
+	}
+
+	for _, p := range payments {
+		if p.Method == models.PaymentMethodCash {
+			report.CashSales = p.TotalAmount
+		}
+	}
+	for _, m := range movements {
+		switch m.Type {
+		case models.CashMovementPayIn:
+			report.PayIns += m.Amount
+		case models.CashMovementPayOut:
+			report.PayOuts += m.Amount
+		}
+	}
+	report.NetCash = report.CashSales + report.PayIns - report.PayOuts
+
+	return &report, nil
+}
+
+// GetSalesTrend mengembalikan deret waktu penjualan per bucket, outletID 0
+// berarti semua outlet. granularity kosong berarti day.
+func (s *TransactionService) GetSalesTrend(startDate, endDate, granularity string, outletID int) (*models.SalesTrend, error) {
+	start, end, err := parseDateRange(startDate, endDate, s.loc)
+	if err != nil {
+		return nil, err
+	}
+
+	if granularity == "" {
+		granularity = models.GranularityDay
+	}
+	days := int(math.Round(end.Sub(start).Hours() / 24))
+	var buckets int
+	switch granularity {
+	case models.GranularityHour:
+		buckets = days * 24
+	case models.GranularityDay:
+		buckets = days
+	case models.GranularityWeek:
+		buckets = days/7 + 1
+	case models.GranularityMonth:
+		buckets = days/28 + 1
+	default:
+		return nil, ErrInvalidGranularity
+	}
+	if buckets > maxTrendBuckets {
+		return nil, ErrTooManyBuckets
+	}
+
+	trend := &models.SalesTrend{StartDate: startDate, EndDate: endDate, Granularity: granularity}
+	if outletID != 0 {
+		trend.OutletID = &outletID
+	}
+
+	trend.Buckets, err = s.repo.GetSalesTrend(start, end, granularity, s.loc, outletID)
+	if err != nil {
+		return nil, err
+	}
+
+	return trend, nil
+}
+
+// parseDateRange memvalidasi start_date dan end_date (YYYY-MM-DD, keduanya
+// wajib) dan mengembalikan rentang setengah terbuka [start, end) di loc:
+// tengah malam start_date sampai tengah malam sesudah end_date
+func parseDateRange(startDate, endDate string, loc *time.Location) (time.Time, time.Time, error) {
+	if startDate == "" || endDate == "" {
+		return time.Time{}, time.Time{}, ErrInvalidDateRange
+	}
+	start, err := time.ParseInLocation("2006-01-02", startDate, loc)
+	if err != nil {
+		return time.Time{}, time.Time{}, ErrInvalidDate
+	}
+	end, err := time.ParseInLocation("2006-01-02", endDate, loc)
+	if err != nil {
+		return time.Time{}, time.Time{}, ErrInvalidDate
+	}
+	if end.Before(start) {
+		return time.Time{}, time.Time{}, ErrInvalidDateRange
+	}
+
+	return start, end.AddDate(0, 0, 1), nil
+}
+
+// GetProductPerformance mengurutkan produk menurut sortBy (revenue, quantity,
+// transactions atau name) dan order. limit > 0 mengambil N teratas; dengan
+// order asc hasilnya N produk paling lambat terjual.
+func (s *TransactionService) GetProductPerformance(startDate, endDate, sortBy, order string, limit, outletID int) (*models.ProductPerformanceReport, error) {
+	start, end, err := parseDateRange(startDate, endDate, s.loc)
+	if err != nil {
+		return nil, err
+	}
+
+	if sortBy == "" {
+		sortBy = "revenue"
+	}
+	if order == "" {
+		order = "desc"
+		if sortBy == "name" {
+			order = "asc"
+		}
+	}
+	if order != "asc" && order != "desc" {
+		return nil, ErrInvalidSort
+	}
+	if limit < 0 {
+		return nil, ErrInvalidLimit
+	}
+
+	less := performanceLess(sortBy)
+	if less == nil {
+		return nil, ErrInvalidSort
+	}
+
+	report, err := s.repo.GetProductPerformance(start, end, outletID)
+	if err != nil {
+		return nil, err
+	}
+	report.StartDate = startDate
+	report.EndDate = endDate
+	report.Sort = sortBy
+	report.Order = order
+	if outletID != 0 {
+		report.OutletID = &outletID
+	}
+	rankProducts(report, less, order, limit)
+
+	return report, nil
+}
+
+// performanceLess mengembalikan nil jika sortBy tidak dikenal
+func performanceLess(sortBy string) func(a, b models.ProductPerformance) bool {
+	switch sortBy {
+	case "revenue":
+		return func(a, b models.ProductPerformance) bool { return a.Revenue < b.Revenue }
+	case "quantity":
+		return func(a, b models.ProductPerformance) bool { return a.Quantity < b.Quantity }
+	case "transactions":
+		return func(a, b models.ProductPerformance) bool { return a.Transactions < b.Transactions }
+	case "name":
+		return func(a, b models.ProductPerformance) bool { return a.ProductName < b.ProductName }
+	default:
+		return nil
+	}
+}
+
+// rankProducts mengurutkan produk report menurut less dan order, mengisi
+// peringkat dan porsi omzet, lalu memotong ke limit (0 berarti semua)
+func rankProducts(report *models.ProductPerformanceReport, less func(a, b models.ProductPerformance) bool, order string, limit int) {
+	// nilai yang sama diurutkan menurut nama supaya peringkat stabil
+	products := report.Products
+	sort.SliceStable(products, func(i, j int) bool {
+		a, b := products[i], products[j]
+		if order == "desc" {
+			a, b = b, a
+		}
+		if less(a, b) {
+			return true
+		}
+		if less(b, a) {
+			return false
+		}
+		return products[i].ProductName < products[j].ProductName
+	})
+
+	for i := range products {
+		products[i].Rank = i + 1
+		products[i].RevenueShare = revenueShare(products[i].Revenue, report.TotalRevenue)
+	}
+	for i := range report.Categories {
+		report.Categories[i].RevenueShare = revenueShare(report.Categories[i].Revenue, report.TotalRevenue)
+	}
+
+	if limit > 0 && limit < len(products) {
+		report.Products = products[:limit]
+	}
+}
+
+// EachTransactionLine memanggil fn untuk setiap detail transaksi antara
+// startDate dan endDate, outletID 0 berarti semua outlet. receiptNumber
+// mencari awalan nomor struk, dan jika diisi rentang tanggal boleh kosong.
+// CreatedAt diubah ke zona waktu toko.
+func (s *TransactionService) EachTransactionLine(startDate, endDate, receiptNumber string, outletID int, fn func(models.TransactionLine) error) error {
+	filter := models.TransactionLineFilter{OutletID: outletID, ReceiptNumber: strings.TrimSpace(receiptNumber)}
+	if filter.ReceiptNumber == "" || startDate != "" || endDate != "" {
+		start, end, err := parseDateRange(startDate, endDate, s.loc)
+		if err != nil {
+			return err
+		}
+		filter.Start, filter.End = start, end
+	}
+
+	return s.repo.EachTransactionLine(filter, func(line models.TransactionLine) error {
+		line.CreatedAt = line.CreatedAt.In(s.loc)
+		return fn(line)
+	})
+}
+
+// revenueShare dalam persen dengan 2 desimal
+func revenueShare(revenue, total int) float64 {
+	if total == 0 {
+		return 0
+	}
+	return math.Round(float64(revenue)*10000/float64(total)) / 100
+}
@@ -0,0 +1,196 @@
+package services
+
+import (
+	"testing"
+	"time"
+	_ "time/tzdata"
+
+	"github.com/anggakrnwn/kasir-api/models"
+)
+
+func performanceFixture() *models.ProductPerformanceReport {
+	return &models.ProductPerformanceReport{
+		TotalRevenue: 100000,
+		Products: []models.ProductPerformance{
+			{ProductName: "Kopi", Quantity: 10, Revenue: 50000, Transactions: 8},
+			{ProductName: "Teh", Quantity: 25, Revenue: 25000, Transactions: 20},
+			{ProductName: "Air", Quantity: 25, Revenue: 15000, Transactions: 12},
+			{ProductName: "Gula", Quantity: 2, Revenue: 10000, Transactions: 2},
+		},
+	}
+}
+
+func TestRankProducts(t *testing.T) {
+	tests := []struct {
+		name   string
+		sortBy string
+		order  string
+		limit  int
+		want   []string
+	}{
+		{"revenue desc", "revenue", "desc", 0, []string{"Kopi", "Teh", "Air", "Gula"}},
+		{"revenue asc gives slowest sellers", "revenue", "asc", 0, []string{"Gula", "Air", "Teh", "Kopi"}},
+		{"quantity ties ordered by name", "quantity", "desc", 0, []string{"Air", "Teh", "Kopi", "Gula"}},
+		{"quantity asc ties still by name", "quantity", "asc", 0, []string{"Gula", "Kopi", "Air", "Teh"}},
+		{"transactions desc", "transactions", "desc", 0, []string{"Teh", "Air", "Kopi", "Gula"}},
+		{"name asc", "name", "asc", 0, []string{"Air", "Gula", "Kopi", "Teh"}},
+		{"top 2", "revenue", "desc", 2, []string{"Kopi", "Teh"}},
+		{"bottom 1", "revenue", "asc", 1, []string{"Gula"}},
+		{"limit above count keeps all", "revenue", "desc", 10, []string{"Kopi", "Teh", "Air", "Gula"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			less := performanceLess(tt.sortBy)
+			if less == nil {
+				t.Fatalf("performanceLess(%q) = nil", tt.sortBy)
+			}
+
+			report := performanceFixture()
+			rankProducts(report, less, tt.order, tt.limit)
+
+			if len(report.Products) != len(tt.want) {
+				t.Fatalf("got %d products, want %d", len(report.Products), len(tt.want))
+			}
+			for i, p := range report.Products {
+				if p.ProductName != tt.want[i] {
+					t.Errorf("position %d = %s, want %s", i, p.ProductName, tt.want[i])
+				}
+				if p.Rank != i+1 {
+					t.Errorf("%s rank = %d, want %d", p.ProductName, p.Rank, i+1)
+				}
+			}
+		})
+	}
+}
+
+func TestRankProductsRevenueShare(t *testing.T) {
+	report := performanceFixture()
+	rankProducts(report, performanceLess("revenue"), "desc", 0)
+
+	want := []float64{50, 25, 15, 10}
+	for i, p := range report.Products {
+		if p.RevenueShare != want[i] {
+			t.Errorf("%s revenue_share = %v, want %v", p.ProductName, p.RevenueShare, want[i])
+		}
+	}
+}
+
+func TestPerformanceLessUnknownSort(t *testing.T) {
+	if performanceLess("margin") != nil {
+		t.Error("performanceLess(\"margin\") should be nil")
+	}
+}
+
+func TestRevenueShare(t *testing.T) {
+	tests := []struct {
+		revenue, total int
+		want           float64
+	}{
+		{1, 3, 33.33},
+		{2, 3, 66.67},
+		{5, 0, 0},
+		{100, 100, 100},
+	}
+
+	for _, tt := range tests {
+		if got := revenueShare(tt.revenue, tt.total); got != tt.want {
+			t.Errorf("revenueShare(%d, %d) = %v, want %v", tt.revenue, tt.total, got, tt.want)
+		}
+	}
+}
+
+func TestParseDateRange(t *testing.T) {
+	jakarta, err := time.LoadLocation("Asia/Jakarta")
+	if err != nil {
+		t.Fatal(err)
+	}
+	newYork, err := time.LoadLocation("America/New_York")
+	if err != nil {
+		t.Fatal(err)
+	}
+	kathmandu := time.FixedZone("NPT", 5*3600+45*60)
+
+	tests := []struct {
+		name      string
+		startDate string
+		endDate   string
+		loc       *time.Location
+		wantStart string
+		wantEnd   string
+		wantErr   error
+	}{
+		{
+			name:      "single day in store time",
+			startDate: "2024-01-20",
+			endDate:   "2024-01-20",
+			loc:       jakarta,
+			wantStart: "2024-01-19T17:00:00Z",
+			wantEnd:   "2024-01-20T17:00:00Z",
+		},
+		{
+			name:      "end is exclusive midnight after end_date",
+			startDate: "2024-01-01",
+			endDate:   "2024-01-31",
+			loc:       jakarta,
+			wantStart: "2023-12-31T17:00:00Z",
+			wantEnd:   "2024-01-31T17:00:00Z",
+		},
+		{
+			name:      "utc",
+			startDate: "2024-02-28",
+			endDate:   "2024-02-29",
+			loc:       time.UTC,
+			wantStart: "2024-02-28T00:00:00Z",
+			wantEnd:   "2024-03-01T00:00:00Z",
+		},
+		{
+			name:      "non-hour offset",
+			startDate: "2024-01-20",
+			endDate:   "2024-01-20",
+			loc:       kathmandu,
+			wantStart: "2024-01-19T18:15:00Z",
+			wantEnd:   "2024-01-20T18:15:00Z",
+		},
+		{
+			name:      "dst start day is 23 hours",
+			startDate: "2024-03-10",
+			endDate:   "2024-03-10",
+			loc:       newYork,
+			wantStart: "2024-03-10T05:00:00Z",
+			wantEnd:   "2024-03-11T04:00:00Z",
+		},
+		{
+			name:      "dst end day is 25 hours",
+			startDate: "2024-11-03",
+			endDate:   "2024-11-03",
+			loc:       newYork,
+			wantStart: "2024-11-03T04:00:00Z",
+			wantEnd:   "2024-11-04T05:00:00Z",
+		},
+		{name: "missing start", endDate: "2024-01-20", loc: jakarta, wantErr: ErrInvalidDateRange},
+		{name: "missing end", startDate: "2024-01-20", loc: jakarta, wantErr: ErrInvalidDateRange},
+		{name: "end before start", startDate: "2024-01-21", endDate: "2024-01-20", loc: jakarta, wantErr: ErrInvalidDateRange},
+		{name: "wrong format", startDate: "20/01/2024", endDate: "2024-01-20", loc: jakarta, wantErr: ErrInvalidDate},
+		{name: "impossible date", startDate: "2024-01-20", endDate: "2024-02-30", loc: jakarta, wantErr: ErrInvalidDate},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			start, end, err := parseDateRange(tt.startDate, tt.endDate, tt.loc)
+			if err != tt.wantErr {
+				t.Fatalf("err = %v, want %v", err, tt.wantErr)
+			}
+			if tt.wantErr != nil {
+				return
+			}
+
+			if got := start.UTC().Format(time.RFC3339); got != tt.wantStart {
+				t.Errorf("start = %s, want %s", got, tt.wantStart)
+			}
+			if got := end.UTC().Format(time.RFC3339); got != tt.wantEnd {
+				t.Errorf("end = %s, want %s", got, tt.wantEnd)
+			}
+		})
+	}
+}
@@ -0,0 +1,109 @@
+package services
+
+import (
+	"errors"
+	"strings"
+
+	"github.com/anggakrnwn/kasir-api/models"
+	"github.com/anggakrnwn/kasir-api/repositories"
+)
+
+var (
+	ErrEmptyTransfer           = errors.New("stock transfer must contain at least one item")
+	ErrInvalidTransferOutlets  = errors.New("from_outlet_id and to_outlet_id must be different active outlets")
+	ErrDuplicateTransferItem   = errors.New("each product can only appear once in a stock transfer")
+	ErrTransferAlreadyReceived = errors.New("stock transfer has already been received")
+	ErrInvalidReceivedQuantity = errors.New("received quantity cannot be negative")
+	ErrReceivedExceedsSent     = errors.New("received quantity cannot exceed the quantity sent")
+	ErrInvalidTransferStatus   = errors.New("status must be sent or received")
+)
+
+type TransferService struct {
+	repo    *repositories.TransferRepository
+	outlets *OutletService
+}
+
+func NewTransferService(repo *repositories.TransferRepository, outlets *OutletService) *TransferService {
+	return &TransferService{repo: repo, outlets: outlets}
+}
+
+// Send mengeluarkan stok dari outlet asal. sentBy adalah nama pengirim untuk
+// jejak mutasi.
+func (s *TransferService) Send(req models.StockTransferRequest, sentBy string) (*models.StockTransfer, error) {
+	if len(req.Items) == 0 {
+		return nil, ErrEmptyTransfer
+	}
+
+	seen := make(map[int]bool)
+	for _, item := range req.Items {
+		if item.Quantity <= 0 {
+			return nil, ErrInvalidQuantity
+		}
+		if seen[item.ProductID] {
+			return nil, ErrDuplicateTransferItem
+		}
+		seen[item.ProductID] = true
+	}
+
+	if req.FromOutletID == 0 || req.ToOutletID == 0 || req.FromOutletID == req.ToOutletID {
+		return nil, ErrInvalidTransferOutlets
+	}
+	for _, id := range []int{req.FromOutletID, req.ToOutletID} {
+		if _, err := s.outlets.Resolve(id); err != nil {
+			return nil, ErrInvalidTransferOutlets
+		}
+	}
+
+	req.Note = strings.TrimSpace(req.Note)
+
+	return s.repo.Send(req, sentBy)
+}
+
+// Receive menyelesaikan mutasi di outlet tujuan
+func (s *TransferService) Receive(id int, req models.ReceiveTransferRequest, receivedBy string) (*models.StockTransfer, error) {
+	transfer, err := s.repo.GetByID(id)
+	if err != nil {
+		return nil, err
+	}
+	if transfer.Status != models.TransferStatusSent {
+		return nil, ErrTransferAlreadyReceived
+	}
+
+	sent := make(map[int]float64)
+	for _, item := range transfer.Items {
+		sent[item.ProductID] = item.QuantitySent
+	}
+
+	// kelebihan barang tidak punya lot maupun harga pokok asal, dicatat lewat
+	// penerimaan barang atau penyesuaian stok biasa
+	received := make(map[int]models.ReceiveTransferItem)
+	for _, item := range req.Items {
+		if item.Quantity < 0 {
+			return nil, ErrInvalidReceivedQuantity
+		}
+		if quantitySent, ok := sent[item.ProductID]; ok && item.Quantity > quantitySent {
+			return nil, ErrReceivedExceedsSent
+		}
+		if _, ok := received[item.ProductID]; ok {
+			return nil, ErrDuplicateTransferItem
+		}
+		item.Note = strings.TrimSpace(item.Note)
+		received[item.ProductID] = item
+	}
+
+	return s.repo.Receive(id, received, receivedBy)
+}
+
+func (s *TransferService) GetByID(id int) (*models.StockTransfer, error) {
+	return s.repo.GetByID(id)
+}
+
+func (s *TransferService) GetAll(filter models.StockTransferFilter) ([]models.StockTransfer, error) {
+	switch filter.Status {
+	case "", models.TransferStatusSent, models.TransferStatusReceived:
+	default:
+		return nil, ErrInvalidTransferStatus
+	}
+
+	return s.repo.GetAll(filter)
+}